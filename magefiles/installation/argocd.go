@@ -0,0 +1,269 @@
+package installation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+const (
+	argoCDNamespace         = "openshift-gitops"
+	argoCDRefreshAnnotation = "argocd.argoproj.io/refresh"
+
+	// DefaultStuckRefreshIterations is the number of consecutive iterations after which an application
+	// that is not ready and is not being reconciled by Argo CD is considered to be stuck
+	DefaultStuckRefreshIterations = 6
+
+	readinessReportFileName = "argocd-readiness-report"
+)
+
+// ResourceReadiness describes a child resource of an Argo CD Application that is out of sync or not healthy
+type ResourceReadiness struct {
+	Group        string `json:"group,omitempty"`
+	Kind         string `json:"kind"`
+	Namespace    string `json:"namespace,omitempty"`
+	Name         string `json:"name"`
+	SyncStatus   string `json:"syncStatus,omitempty"`
+	HealthStatus string `json:"healthStatus,omitempty"`
+	Message      string `json:"message,omitempty"`
+}
+
+func (r ResourceReadiness) String() string {
+	kind := r.Kind
+	if r.Group != "" {
+		kind = r.Kind + "." + r.Group
+	}
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s %s", kind, r.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", kind, r.Namespace, r.Name)
+}
+
+// ApplicationReadiness describes the sync and health status of a single Argo CD Application
+type ApplicationReadiness struct {
+	Name             string              `json:"name"`
+	Ready            bool                `json:"ready"`
+	SyncStatus       string              `json:"syncStatus"`
+	HealthStatus     string              `json:"healthStatus"`
+	HealthMessage    string              `json:"healthMessage,omitempty"`
+	Revision         string              `json:"revision,omitempty"`
+	OperationPhase   string              `json:"operationPhase,omitempty"`
+	OperationMessage string              `json:"operationMessage,omitempty"`
+	Conditions       []string            `json:"conditions,omitempty"`
+	Resources        []ResourceReadiness `json:"resources,omitempty"`
+	// StuckRefresh is true when Argo CD did not reconcile the application for several iterations
+	StuckRefresh bool `json:"stuckRefresh,omitempty"`
+}
+
+// ReadinessReport is the result of a single ReadinessAnalyser iteration
+type ReadinessReport struct {
+	Time         time.Time              `json:"time"`
+	Iteration    int                    `json:"iteration"`
+	Applications []ApplicationReadiness `json:"applications"`
+}
+
+// ReadinessAnalyser evaluates readiness of Argo CD Applications. It keeps track of the applications
+// across iterations, so it is able to detect applications stuck in a refresh loop.
+type ReadinessAnalyser struct {
+	StuckRefreshIterations int
+
+	iteration int
+	observed  map[string]*appObservation
+}
+
+type appObservation struct {
+	fingerprint    string
+	unchanged      int
+	refreshPending bool
+	refreshing     int
+}
+
+func NewReadinessAnalyser() *ReadinessAnalyser {
+	return &ReadinessAnalyser{StuckRefreshIterations: DefaultStuckRefreshIterations, observed: map[string]*appObservation{}}
+}
+
+// Analyse evaluates the current state of the applications
+func (a *ReadinessAnalyser) Analyse(apps []argocdv1alpha1.Application) *ReadinessReport {
+	a.iteration++
+	report := &ReadinessReport{Time: time.Now().UTC(), Iteration: a.iteration}
+	for _, app := range apps {
+		r := analyseApplication(app)
+		if !r.Ready {
+			r.StuckRefresh = a.observe(app)
+		} else {
+			delete(a.observed, app.Name)
+		}
+		report.Applications = append(report.Applications, r)
+	}
+	sort.Slice(report.Applications, func(i, j int) bool {
+		return report.Applications[i].Name < report.Applications[j].Name
+	})
+	return report
+}
+
+// observe records the state of a not ready application and returns true if the application was not reconciled
+// by Argo CD (or a requested refresh was not processed) for StuckRefreshIterations consecutive iterations
+func (a *ReadinessAnalyser) observe(app argocdv1alpha1.Application) bool {
+	o, ok := a.observed[app.Name]
+	if !ok {
+		o = &appObservation{}
+		a.observed[app.Name] = o
+	}
+	fingerprint := applicationFingerprint(app)
+	if ok && fingerprint == o.fingerprint {
+		o.unchanged++
+	} else {
+		o.unchanged = 0
+	}
+	o.fingerprint = fingerprint
+	_, refreshPending := app.Annotations[argoCDRefreshAnnotation]
+	if refreshPending && o.refreshPending {
+		o.refreshing++
+	} else {
+		o.refreshing = 0
+	}
+	o.refreshPending = refreshPending
+	return o.unchanged >= a.StuckRefreshIterations || o.refreshing >= a.StuckRefreshIterations
+}
+
+// RefreshRequested resets the observation of the application after a refresh was requested for it,
+// so the refresh is not requested again before the application is considered to be stuck once more
+func (a *ReadinessAnalyser) RefreshRequested(name string) {
+	if o, ok := a.observed[name]; ok {
+		o.unchanged = 0
+		o.refreshing = 0
+	}
+}
+
+func applicationFingerprint(app argocdv1alpha1.Application) string {
+	reconciledAt := ""
+	if app.Status.ReconciledAt != nil {
+		reconciledAt = app.Status.ReconciledAt.UTC().Format(time.RFC3339)
+	}
+	return strings.Join([]string{reconciledAt, app.Status.Sync.Revision, string(app.Status.Sync.Status), string(app.Status.Health.Status)}, "|")
+}
+
+func analyseApplication(app argocdv1alpha1.Application) ApplicationReadiness {
+	r := ApplicationReadiness{
+		Name:          app.Name,
+		SyncStatus:    string(app.Status.Sync.Status),
+		HealthStatus:  string(app.Status.Health.Status),
+		HealthMessage: app.Status.Health.Message,
+		Revision:      app.Status.Sync.Revision,
+	}
+	r.Ready = app.Status.Sync.Status == argocdv1alpha1.SyncStatusCodeSynced && r.HealthStatus == "Healthy"
+	if op := app.Status.OperationState; op != nil {
+		r.OperationPhase = string(op.Phase)
+		r.OperationMessage = op.Message
+	}
+	for _, c := range app.Status.Conditions {
+		r.Conditions = append(r.Conditions, fmt.Sprintf("%s: %s", c.Type, c.Message))
+	}
+	if r.Ready {
+		return r
+	}
+	for _, res := range app.Status.Resources {
+		rr := ResourceReadiness{Group: res.Group, Kind: res.Kind, Namespace: res.Namespace, Name: res.Name, SyncStatus: string(res.Status)}
+		if res.Health != nil {
+			rr.HealthStatus = string(res.Health.Status)
+			rr.Message = res.Health.Message
+		}
+		outOfSync := res.Status != "" && res.Status != argocdv1alpha1.SyncStatusCodeSynced
+		notHealthy := rr.HealthStatus != "" && rr.HealthStatus != "Healthy"
+		if outOfSync || notHealthy {
+			r.Resources = append(r.Resources, rr)
+		}
+	}
+	return r
+}
+
+// NotReady returns the applications which are not synced or healthy
+func (r *ReadinessReport) NotReady() []ApplicationReadiness {
+	var notReady []ApplicationReadiness
+	for _, app := range r.Applications {
+		if !app.Ready {
+			notReady = append(notReady, app)
+		}
+	}
+	return notReady
+}
+
+// StuckRefresh returns names of the applications stuck in a refresh loop
+func (r *ReadinessReport) StuckRefresh() []string {
+	var names []string
+	for _, app := range r.Applications {
+		if app.StuckRefresh {
+			names = append(names, app.Name)
+		}
+	}
+	return names
+}
+
+// Summary returns a single line description of the report
+func (r *ReadinessReport) Summary() string {
+	notReady := r.NotReady()
+	if len(notReady) == 0 {
+		return fmt.Sprintf("all %d applications are ready", len(r.Applications))
+	}
+	names := make([]string, 0, len(notReady))
+	for _, app := range notReady {
+		names = append(names, app.Name)
+	}
+	return fmt.Sprintf("%d/%d applications are not ready: %s", len(notReady), len(r.Applications), strings.Join(names, ", "))
+}
+
+// PrintTable prints the status of all applications, followed by the out of sync or not healthy resources
+// of applications that are not ready
+func (r *ReadinessReport) PrintTable(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APPLICATION\tSYNC\tHEALTH\tOPERATION\tMESSAGE")
+	for _, app := range r.Applications {
+		message := app.OperationMessage
+		if app.HealthMessage != "" {
+			message = app.HealthMessage
+		}
+		if app.StuckRefresh {
+			message = strings.TrimSpace("(stuck refresh) " + message)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", app.Name, app.SyncStatus, app.HealthStatus, app.OperationPhase, oneLine(message))
+		for _, c := range app.Conditions {
+			fmt.Fprintf(w, "  condition\t\t\t\t%s\n", oneLine(c))
+		}
+		for _, res := range app.Resources {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t\t%s\n", res, res.SyncStatus, res.HealthStatus, oneLine(res.Message))
+		}
+	}
+	return w.Flush()
+}
+
+// Save writes the report to dir in JSON and table formats
+func (r *ReadinessReport) Save(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %+v", dir, err)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal readiness report: %+v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, readinessReportFileName+".json"), data, 0600); err != nil {
+		return fmt.Errorf("failed to write readiness report: %+v", err)
+	}
+	f, err := os.Create(filepath.Join(dir, readinessReportFileName+".txt"))
+	if err != nil {
+		return fmt.Errorf("failed to write readiness report: %+v", err)
+	}
+	defer f.Close()
+	return r.PrintTable(f)
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
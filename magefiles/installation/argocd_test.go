@@ -0,0 +1,123 @@
+package installation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/argoproj/gitops-engine/pkg/health"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func application(name string, sync argocdv1alpha1.SyncStatusCode, healthStatus health.HealthStatusCode, resources ...argocdv1alpha1.ResourceStatus) argocdv1alpha1.Application {
+	app := argocdv1alpha1.Application{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: argoCDNamespace}}
+	app.Status.Sync.Status = sync
+	app.Status.Health.Status = healthStatus
+	app.Status.Resources = resources
+	return app
+}
+
+func TestAnalyse(t *testing.T) {
+	degraded := application("build-service", argocdv1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusDegraded,
+		argocdv1alpha1.ResourceStatus{Kind: "Deployment", Group: "apps", Namespace: "build-service", Name: "controller", Status: argocdv1alpha1.SyncStatusCodeSynced,
+			Health: &argocdv1alpha1.HealthStatus{Status: health.HealthStatusDegraded, Message: "Deployment \"controller\" exceeded its progress deadline"}},
+		argocdv1alpha1.ResourceStatus{Kind: "ConfigMap", Namespace: "build-service", Name: "config", Status: argocdv1alpha1.SyncStatusCodeOutOfSync},
+		argocdv1alpha1.ResourceStatus{Kind: "Service", Namespace: "build-service", Name: "metrics", Status: argocdv1alpha1.SyncStatusCodeSynced,
+			Health: &argocdv1alpha1.HealthStatus{Status: health.HealthStatusHealthy}},
+	)
+	degraded.Status.OperationState = &argocdv1alpha1.OperationState{Phase: "Running", Message: "waiting for healthy state of apps/Deployment/controller"}
+	degraded.Status.Conditions = []argocdv1alpha1.ApplicationCondition{{Type: argocdv1alpha1.ApplicationConditionComparisonError, Message: "context deadline exceeded"}}
+
+	report := NewReadinessAnalyser().Analyse([]argocdv1alpha1.Application{
+		application("integration", argocdv1alpha1.SyncStatusCodeSynced, health.HealthStatusHealthy),
+		degraded,
+	})
+
+	assert.Equal(t, 1, report.Iteration)
+	assert.Equal(t, "1/2 applications are not ready: build-service", report.Summary())
+	assert.Equal(t, []ApplicationReadiness{{
+		Name:             "build-service",
+		SyncStatus:       "OutOfSync",
+		HealthStatus:     "Degraded",
+		OperationPhase:   "Running",
+		OperationMessage: "waiting for healthy state of apps/Deployment/controller",
+		Conditions:       []string{"ComparisonError: context deadline exceeded"},
+		Resources: []ResourceReadiness{
+			{Group: "apps", Kind: "Deployment", Namespace: "build-service", Name: "controller", SyncStatus: "Synced", HealthStatus: "Degraded", Message: "Deployment \"controller\" exceeded its progress deadline"},
+			{Kind: "ConfigMap", Namespace: "build-service", Name: "config", SyncStatus: "OutOfSync"},
+		},
+	}}, report.NotReady())
+}
+
+func TestAnalyseStuckRefresh(t *testing.T) {
+	analyser := NewReadinessAnalyser()
+	analyser.StuckRefreshIterations = 2
+	reconciledAt := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	stuck := application("release", argocdv1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusProgressing)
+	stuck.Status.ReconciledAt = &reconciledAt
+	progressing := application("enterprise-contract", argocdv1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusProgressing)
+	refreshing := application("pipeline-service", argocdv1alpha1.SyncStatusCodeSynced, health.HealthStatusMissing)
+	refreshing.Annotations = map[string]string{argoCDRefreshAnnotation: "hard"}
+
+	for i := 0; i < 3; i++ {
+		progressingReconciledAt := metav1.NewTime(reconciledAt.Add(time.Duration(i) * time.Minute))
+		progressing.Status.ReconciledAt = &progressingReconciledAt
+		refreshing.Status.ReconciledAt = &progressingReconciledAt
+
+		report := analyser.Analyse([]argocdv1alpha1.Application{stuck, progressing, refreshing})
+		if i < 2 {
+			assert.Empty(t, report.StuckRefresh(), "iteration %d", report.Iteration)
+		} else {
+			assert.Equal(t, []string{"pipeline-service", "release"}, report.StuckRefresh())
+		}
+	}
+
+	// the application is reconciled again
+	newReconciledAt := metav1.NewTime(reconciledAt.Add(time.Hour))
+	stuck.Status.ReconciledAt = &newReconciledAt
+	refreshing.Annotations = nil
+	assert.Empty(t, analyser.Analyse([]argocdv1alpha1.Application{stuck, progressing, refreshing}).StuckRefresh())
+}
+
+func TestRefreshRequested(t *testing.T) {
+	analyser := NewReadinessAnalyser()
+	analyser.StuckRefreshIterations = 2
+	stuck := application("release", argocdv1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusProgressing)
+
+	for i := 0; i < 3; i++ {
+		analyser.Analyse([]argocdv1alpha1.Application{stuck})
+	}
+	analyser.RefreshRequested("release")
+
+	// the refresh is not requested again before the application is considered to be stuck once more
+	assert.Empty(t, analyser.Analyse([]argocdv1alpha1.Application{stuck}).StuckRefresh())
+	assert.Equal(t, []string{"release"}, analyser.Analyse([]argocdv1alpha1.Application{stuck}).StuckRefresh())
+}
+
+func TestReadinessReportSave(t *testing.T) {
+	dir := t.TempDir()
+	app := application("build-service", argocdv1alpha1.SyncStatusCodeOutOfSync, health.HealthStatusHealthy,
+		argocdv1alpha1.ResourceStatus{Kind: "ConfigMap", Namespace: "build-service", Name: "config", Status: argocdv1alpha1.SyncStatusCodeOutOfSync})
+	report := NewReadinessAnalyser().Analyse([]argocdv1alpha1.Application{app})
+
+	assert.NoError(t, report.Save(dir))
+
+	data, err := os.ReadFile(filepath.Join(dir, "argocd-readiness-report.json"))
+	assert.NoError(t, err)
+	saved := &ReadinessReport{}
+	assert.NoError(t, json.Unmarshal(data, saved))
+	assert.Equal(t, report.Applications, saved.Applications)
+
+	table, err := os.ReadFile(filepath.Join(dir, "argocd-readiness-report.txt"))
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(table)), "\n")
+	assert.Len(t, lines, 3)
+	assert.Equal(t, []string{"build-service", "OutOfSync", "Healthy"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"ConfigMap", "build-service/config", "OutOfSync"}, strings.Fields(lines[2]))
+}
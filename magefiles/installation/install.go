@@ -6,10 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
-	"strings"
-
 	appsv1 "k8s.io/api/apps/v1"
 
 	"github.com/devfile/library/v2/pkg/util"
@@ -70,6 +69,15 @@ type InstallAppStudio struct {
 	// Github organization from where will be cloned
 	InfraDeploymentsOrganizationName string
 
+	// Local infra-deployments checkout copied to InfraDeploymentsCloneDir instead of cloning the repo from GitHub
+	InfraDeploymentsLocalDir string
+
+	// YAML file with kustomize overlays (image overrides, replicas, patches) applied to infra-deployments before the installation
+	InfraDeploymentsOverlaysFile string
+
+	// Directory where the manifests rendered from the overlays are recorded
+	ArtifactDir string
+
 	// Desired fork name for testing
 	LocalForkName string
 
@@ -109,6 +117,9 @@ func NewAppStudioInstallController() (*InstallAppStudio, error) {
 		InfraDeploymentsCloneDir:         fmt.Sprintf("%s/%s/infra-deployments", cwd, DEFAULT_TMP_DIR),
 		InfraDeploymentsBranch:           utils.GetEnv("INFRA_DEPLOYMENTS_BRANCH", DEFAULT_INFRA_DEPLOYMENTS_BRANCH),
 		InfraDeploymentsOrganizationName: utils.GetEnv("INFRA_DEPLOYMENTS_ORG", DEFAULT_INFRA_DEPLOYMENTS_GH_ORG),
+		InfraDeploymentsLocalDir:         utils.GetEnv("INFRA_DEPLOYMENTS_LOCAL_DIR", ""),
+		InfraDeploymentsOverlaysFile:     utils.GetEnv("INFRA_DEPLOYMENTS_OVERLAYS_FILE", ""),
+		ArtifactDir:                      utils.GetEnv("ARTIFACT_DIR", "."),
 		LocalForkName:                    DEFAULT_LOCAL_FORK_NAME,
 		LocalGithubForkOrganization:      utils.GetEnv("MY_GITHUB_ORG", DEFAULT_LOCAL_FORK_ORGANIZATION),
 		QuayToken:                        utils.GetEnv("QUAY_TOKEN", ""),
@@ -121,9 +132,25 @@ func NewAppStudioInstallController() (*InstallAppStudio, error) {
 
 // Start the appstudio installation in preview mode.
 func (i *InstallAppStudio) InstallAppStudioPreviewMode() error {
-	if err := i.cloneInfraDeployments(); err != nil {
+	if i.InfraDeploymentsLocalDir != "" {
+		if err := i.copyLocalInfraDeployments(); err != nil {
+			return fmt.Errorf("failed to copy local infra-deployments repository: %+v", err)
+		}
+		if err := i.addForkRemote(); err != nil {
+			return fmt.Errorf("failed to add fork remote to the local infra-deployments repository: %+v", err)
+		}
+	} else if err := i.cloneInfraDeployments(); err != nil {
 		return fmt.Errorf("failed to clone infra-deployments repository: %+v", err)
 	}
+	if i.InfraDeploymentsOverlaysFile != "" {
+		overlays, err := LoadOverlaysConfig(i.InfraDeploymentsOverlaysFile)
+		if err != nil {
+			return err
+		}
+		if err := overlays.ApplyOverlays(i.InfraDeploymentsCloneDir, filepath.Join(i.ArtifactDir, "infra-deployments-overlays")); err != nil {
+			return fmt.Errorf("failed to apply infra-deployments overlays: %+v", err)
+		}
+	}
 	i.setInstallationEnvironments()
 
 	if i.EnableSchedulingOnMasterNodes == "true" {
@@ -132,6 +159,8 @@ func (i *InstallAppStudio) InstallAppStudioPreviewMode() error {
 		}
 	}
 
+	// the preview mode commits the tree (including the local changes and overlays) to a preview branch
+	// of the fork and Argo CD deploys the kustomize manifests from it
 	if err := utils.ExecuteCommandInASpecificDirectory("hack/bootstrap-cluster.sh", previewInstallArgs, i.InfraDeploymentsCloneDir); err != nil {
 		return err
 	}
@@ -175,7 +204,7 @@ func (i *InstallAppStudio) setInstallationEnvironments() {
 	os.Setenv(constants.ENABLE_SCHEDULING_ON_MASTER_NODES_ENV, i.EnableSchedulingOnMasterNodes)
 }
 
-func (i *InstallAppStudio) cloneInfraDeployments() error {
+func (i *InstallAppStudio) removeInfraDeploymentsCloneDir() error {
 	dirInfo, err := os.Stat(i.InfraDeploymentsCloneDir)
 
 	if !os.IsNotExist(err) && dirInfo.IsDir() {
@@ -186,6 +215,42 @@ func (i *InstallAppStudio) cloneInfraDeployments() error {
 			return fmt.Errorf("error removing %s folder", i.InfraDeploymentsCloneDir)
 		}
 	}
+	return nil
+}
+
+// copyLocalInfraDeployments copies the local infra-deployments checkout (including uncommitted changes),
+// so the developer's tree is not modified by the overlays
+func (i *InstallAppStudio) copyLocalInfraDeployments() error {
+	if err := i.removeInfraDeploymentsCloneDir(); err != nil {
+		return err
+	}
+	if _, err := git.PlainOpen(i.InfraDeploymentsLocalDir); err != nil {
+		return fmt.Errorf("%s is not a git repository: %+v", i.InfraDeploymentsLocalDir, err)
+	}
+	if err := os.MkdirAll(i.InfraDeploymentsCloneDir, 0755); err != nil {
+		return err
+	}
+	klog.Infof("copying local infra-deployments repository '%s' to '%s'", i.InfraDeploymentsLocalDir, i.InfraDeploymentsCloneDir)
+	return utils.ExecuteCommandInASpecificDirectory("cp", []string{"-a", filepath.Clean(i.InfraDeploymentsLocalDir) + "/.", i.InfraDeploymentsCloneDir}, ".")
+}
+
+// addForkRemote adds the remote of the fork the preview mode pushes the tree to
+func (i *InstallAppStudio) addForkRemote() error {
+	repo, err := git.PlainOpen(i.InfraDeploymentsCloneDir)
+	if err != nil {
+		return err
+	}
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: i.LocalForkName, URLs: []string{fmt.Sprintf("https://github.com/%s/infra-deployments.git", i.LocalGithubForkOrganization)}})
+	if err != nil && err != git.ErrRemoteExists {
+		return err
+	}
+	return nil
+}
+
+func (i *InstallAppStudio) cloneInfraDeployments() error {
+	if err := i.removeInfraDeploymentsCloneDir(); err != nil {
+		return err
+	}
 
 	url := fmt.Sprintf("https://github.com/%s/infra-deployments", i.InfraDeploymentsOrganizationName)
 	refName := fmt.Sprintf("refs/heads/%s", i.InfraDeploymentsBranch)
@@ -208,7 +273,7 @@ func (i *InstallAppStudio) cloneInfraDeployments() error {
 		}
 	}
 
-	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: i.LocalForkName, URLs: []string{fmt.Sprintf("https://github.com/%s/infra-deployments.git", i.LocalGithubForkOrganization)}}); err != nil {
+	if err := i.addForkRemote(); err != nil {
 		return err
 	}
 	if err := utils.ExecuteCommandInASpecificDirectory("git", []string{"pull", "--rebase", "upstream", "main"}, i.InfraDeploymentsCloneDir); err != nil {
@@ -238,55 +303,59 @@ func (i *InstallAppStudio) CheckOperatorsReady() (err error) {
 	if err != nil {
 		klog.Fatal(err)
 	}
-	_, err = appClientset.ArgoprojV1alpha1().Applications("openshift-gitops").Patch(context.Background(), "all-application-sets", types.JSONPatchType, patchPayloadBytes, metav1.PatchOptions{})
+	_, err = appClientset.ArgoprojV1alpha1().Applications(argoCDNamespace).Patch(context.Background(), "all-application-sets", types.JSONPatchType, patchPayloadBytes, metav1.PatchOptions{})
 	if err != nil {
 		klog.Fatal(err)
 	}
 
+	// a longer wait has to be requested explicitly, so a hang doesn't go unnoticed
+	timeout := kubeCl.DefaultTimeout
+	if value := utils.GetEnv("ARGOCD_READINESS_TIMEOUT", ""); value != "" {
+		if timeout, err = time.ParseDuration(value); err != nil {
+			return fmt.Errorf("invalid ARGOCD_READINESS_TIMEOUT: %+v", err)
+		}
+	}
+	deadline := time.Now().Add(timeout)
+	analyser := NewReadinessAnalyser()
 	for {
-		var count = 0
-		appsListFor, err := appClientset.ArgoprojV1alpha1().Applications("openshift-gitops").List(context.Background(), metav1.ListOptions{})
-		for _, app := range appsListFor.Items {
-			fmt.Printf("Check application: %s\n", app.Name)
-			application, err := appClientset.ArgoprojV1alpha1().Applications("openshift-gitops").Get(context.Background(), app.Name, metav1.GetOptions{})
-			if err != nil {
-				klog.Fatal(err)
+		apps, err := appClientset.ArgoprojV1alpha1().Applications(argoCDNamespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list Argo CD applications: %+v", err)
+		}
+		report := analyser.Analyse(apps.Items)
+		notReady := report.NotReady()
+		if len(notReady) == 0 {
+			klog.Info("All Application are ready")
+			return nil
+		}
+		klog.Infof("iteration %d: %s", report.Iteration, report.Summary())
+		for _, app := range notReady {
+			klog.Infof("Application %s not ready: sync %s, health %s, operation %s %s", app.Name, app.SyncStatus, app.HealthStatus, app.OperationPhase, oneLine(app.OperationMessage))
+			for _, res := range app.Resources {
+				klog.Infof("  %s: sync %s, health %s %s", res, res.SyncStatus, res.HealthStatus, oneLine(res.Message))
 			}
+		}
 
-			if !(application.Status.Sync.Status == "Synced" && application.Status.Health.Status == "Healthy") {
-				klog.Infof("Application %s not ready", app.Name)
-				count++
-			} else if strings.Contains(application.String(), ("context deadline exceeded")) {
-				fmt.Printf("Refreshing Application %s\n", app.Name)
-				patchPayload := []patchStringValue{{
-					Op:    "replace",
-					Path:  "/metadata/annotations/argocd.argoproj.io~1refresh",
-					Value: "soft",
-				}}
-
-				patchPayloadBytes, err := json.Marshal(patchPayload)
-				if err != nil {
-					klog.Fatal(err)
-				}
-				for _, app := range appsListFor.Items {
-					_, err = i.KubernetesClient.KubeInterface().AppsV1().Deployments("openshift-gitops").Patch(context.Background(), app.Name, types.JSONPatchType, patchPayloadBytes, metav1.PatchOptions{})
-					if err != nil {
-						klog.Fatal(err)
-					}
-				}
+		if time.Now().After(deadline) {
+			dir := filepath.Join(i.ArtifactDir, "argocd")
+			if err := report.Save(dir); err != nil {
+				klog.Errorf("failed to save Argo CD readiness report: %+v", err)
 			}
-		}
-		if err != nil {
-			klog.Fatal(err)
+			return fmt.Errorf("Argo CD applications not ready after %s (%s), see the readiness report in %s", timeout, report.Summary(), dir)
 		}
 
-		if count == 0 {
-			klog.Info("All Application are ready\n")
-			break
+		for _, name := range report.StuckRefresh() {
+			klog.Infof("Application %s looks stuck, requesting refresh", name)
+			// merge patch works also for applications without annotations
+			patchPayloadBytes := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:"hard"}}}`, argoCDRefreshAnnotation))
+			if _, err := appClientset.ArgoprojV1alpha1().Applications(argoCDNamespace).Patch(context.Background(), name, types.MergePatchType, patchPayloadBytes, metav1.PatchOptions{}); err != nil {
+				klog.Errorf("failed to refresh Application %s: %+v", name, err)
+				continue
+			}
+			analyser.RefreshRequested(name)
 		}
 		time.Sleep(10 * time.Second)
 	}
-	return err
 }
 
 // Create secret in e2e-secrets which can be copied to testing namespaces
@@ -0,0 +1,149 @@
+package installation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kustomizeTypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	kustomizationFileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+	overlayNameRegexp      = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+)
+
+// OverlaysConfig is the content of the file referenced by INFRA_DEPLOYMENTS_OVERLAYS_FILE env var
+type OverlaysConfig struct {
+	Overlays []Overlay `json:"overlays"`
+}
+
+// Overlay describes changes of a kustomize directory within infra-deployments repository,
+// e.g. image overrides of a controller, replica counts or feature flags (via patches)
+type Overlay struct {
+	// Name identifies the overlay in logs and in artifacts
+	Name string `json:"name"`
+	// Path to the kustomize directory relative to the root of infra-deployments repository
+	Path     string                   `json:"path"`
+	Images   []kustomizeTypes.Image   `json:"images,omitempty"`
+	Replicas []kustomizeTypes.Replica `json:"replicas,omitempty"`
+	Patches  []kustomizeTypes.Patch   `json:"patches,omitempty"`
+}
+
+// LoadOverlaysConfig reads and validates the overlays file
+func LoadOverlaysConfig(file string) (*OverlaysConfig, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read overlays file %s: %+v", file, err)
+	}
+	c := &OverlaysConfig{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse overlays file %s: %+v", file, err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid overlays file %s: %+v", file, err)
+	}
+	return c, nil
+}
+
+func (c *OverlaysConfig) Validate() error {
+	names := map[string]bool{}
+	for i, o := range c.Overlays {
+		if !overlayNameRegexp.MatchString(o.Name) {
+			return fmt.Errorf("overlay #%d: name %q is not valid", i, o.Name)
+		}
+		if names[o.Name] {
+			return fmt.Errorf("overlay %s: duplicate name", o.Name)
+		}
+		names[o.Name] = true
+		if o.Path == "" || filepath.IsAbs(o.Path) || strings.HasPrefix(filepath.Clean(o.Path), "..") {
+			return fmt.Errorf("overlay %s: path has to be relative to the infra-deployments root", o.Name)
+		}
+		if len(o.Images)+len(o.Replicas)+len(o.Patches) == 0 {
+			return fmt.Errorf("overlay %s: at least one image, replica or patch has to be defined", o.Name)
+		}
+		for _, p := range o.Patches {
+			if p.Patch == "" {
+				return fmt.Errorf("overlay %s: only inline patches are supported", o.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Apply adds the images, replicas and patches of the overlay to the kustomization file
+// of the overlay directory within infraDeploymentsDir
+func (o *Overlay) Apply(infraDeploymentsDir string) error {
+	file, err := findKustomizationFile(filepath.Join(infraDeploymentsDir, o.Path))
+	if err != nil {
+		return fmt.Errorf("overlay %s: %+v", o.Name, err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("overlay %s: failed to read %s: %+v", o.Name, file, err)
+	}
+	k := &kustomizeTypes.Kustomization{}
+	if err := yaml.Unmarshal(data, k); err != nil {
+		return fmt.Errorf("overlay %s: failed to parse %s: %+v", o.Name, file, err)
+	}
+	k.Images = append(k.Images, o.Images...)
+	k.Replicas = append(k.Replicas, o.Replicas...)
+	k.Patches = append(k.Patches, o.Patches...)
+
+	if data, err = yaml.Marshal(k); err != nil {
+		return fmt.Errorf("overlay %s: failed to marshal %s: %+v", o.Name, file, err)
+	}
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		return fmt.Errorf("overlay %s: failed to write %s: %+v", o.Name, file, err)
+	}
+	return nil
+}
+
+// Render builds the overlay directory within infraDeploymentsDir with kustomize and returns the manifests
+func (o *Overlay) Render(infraDeploymentsDir string) ([]byte, error) {
+	resMap, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(filesys.MakeFsOnDisk(), filepath.Join(infraDeploymentsDir, o.Path))
+	if err != nil {
+		return nil, err
+	}
+	return resMap.AsYaml()
+}
+
+// ApplyOverlays applies all overlays to the infra-deployments tree and records the rendered manifests
+// to the outputDir (one file per overlay)
+func (c *OverlaysConfig) ApplyOverlays(infraDeploymentsDir, outputDir string) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %+v", outputDir, err)
+	}
+	for _, o := range c.Overlays {
+		klog.Infof("applying overlay %s to %s", o.Name, o.Path)
+		if err := o.Apply(infraDeploymentsDir); err != nil {
+			return err
+		}
+		manifests, err := o.Render(infraDeploymentsDir)
+		if err != nil {
+			return fmt.Errorf("overlay %s: failed to render %s: %+v", o.Name, o.Path, err)
+		}
+		output := filepath.Join(outputDir, o.Name+".yaml")
+		if err := os.WriteFile(output, manifests, 0600); err != nil {
+			return fmt.Errorf("overlay %s: failed to record rendered manifests: %+v", o.Name, err)
+		}
+		klog.Infof("rendered manifests of overlay %s recorded to %s", o.Name, output)
+	}
+	return nil
+}
+
+func findKustomizationFile(dir string) (string, error) {
+	for _, name := range kustomizationFileNames {
+		file := filepath.Join(dir, name)
+		if _, err := os.Stat(file); err == nil {
+			return file, nil
+		}
+	}
+	return "", fmt.Errorf("kustomization file not found in %s", dir)
+}
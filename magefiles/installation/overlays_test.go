@@ -0,0 +1,86 @@
+package installation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	kustomizeTypes "sigs.k8s.io/kustomize/api/types"
+)
+
+const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: build-service-controller-manager
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: manager
+        image: quay.io/konflux-ci/build-service:abc
+`
+
+const overlays = `overlays:
+- name: build-service
+  path: components/build-service/development
+  images:
+  - name: quay.io/konflux-ci/build-service
+    newName: quay.io/developer/build-service
+    newTag: pr-1
+  replicas:
+  - name: build-service-controller-manager
+    count: 2
+  patches:
+  - target:
+      kind: Deployment
+      name: build-service-controller-manager
+    patch: |-
+      - op: add
+        path: /spec/template/spec/containers/0/env
+        value: [{name: FEATURE_FLAG, value: "true"}]
+`
+
+func writeTestFile(t *testing.T, path, content string) {
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func TestApplyOverlays(t *testing.T) {
+	infraDir, outputDir := t.TempDir(), t.TempDir()
+	writeTestFile(t, filepath.Join(infraDir, "components/build-service/base/deployment.yaml"), deployment)
+	writeTestFile(t, filepath.Join(infraDir, "components/build-service/base/kustomization.yaml"), "resources:\n- deployment.yaml\n")
+	writeTestFile(t, filepath.Join(infraDir, "components/build-service/development/kustomization.yaml"), "resources:\n- ../base\nnamespace: build-service\n")
+	overlaysFile := filepath.Join(t.TempDir(), "overlays.yaml")
+	writeTestFile(t, overlaysFile, overlays)
+
+	c, err := LoadOverlaysConfig(overlaysFile)
+	assert.NoError(t, err)
+	assert.NoError(t, c.ApplyOverlays(infraDir, outputDir))
+
+	rendered, err := os.ReadFile(filepath.Join(outputDir, "build-service.yaml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(rendered), "image: quay.io/developer/build-service:pr-1")
+	assert.Contains(t, string(rendered), "replicas: 2")
+	assert.Contains(t, string(rendered), "name: FEATURE_FLAG")
+	assert.Contains(t, string(rendered), "namespace: build-service")
+
+	kustomization, err := os.ReadFile(filepath.Join(infraDir, "components/build-service/development/kustomization.yaml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(kustomization), "newTag: pr-1")
+}
+
+func TestOverlaysConfigValidation(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		overlay Overlay
+	}{
+		{"invalid name", Overlay{Name: "a/b", Path: "components/a", Images: []kustomizeTypes.Image{{Name: "a"}}}},
+		{"path outside of the repo", Overlay{Name: "a", Path: "../a", Images: []kustomizeTypes.Image{{Name: "a"}}}},
+		{"no changes", Overlay{Name: "a", Path: "components/a"}},
+	} {
+		c := &OverlaysConfig{Overlays: []Overlay{tc.overlay}}
+		assert.Error(t, c.Validate(), tc.name)
+	}
+}
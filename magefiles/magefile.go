@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -24,11 +25,15 @@ import (
 	remoteimg "github.com/google/go-containerregistry/pkg/v1/remote"
 	gh "github.com/google/go-github/v44/github"
 	"github.com/konflux-ci/e2e-tests/magefiles/installation"
+	"github.com/konflux-ci/e2e-tests/magefiles/preflight"
+	"github.com/konflux-ci/e2e-tests/magefiles/sharding"
+	"github.com/konflux-ci/e2e-tests/magefiles/testselection"
 	"github.com/konflux-ci/e2e-tests/magefiles/upgrade"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/github"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/gitlab"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/slack"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/sprayproxy"
+	"github.com/konflux-ci/e2e-tests/pkg/config"
 	"github.com/konflux-ci/e2e-tests/pkg/constants"
 	"github.com/konflux-ci/e2e-tests/pkg/testspecs"
 	"github.com/konflux-ci/e2e-tests/pkg/utils"
@@ -40,8 +45,9 @@ import (
 )
 
 const (
-	quayApiUrl       = "https://quay.io/api/v1"
-	gitopsRepository = "GitOps Repository"
+	quayApiUrl         = "https://quay.io/api/v1"
+	gitopsRepository   = "GitOps Repository"
+	defaultLabelFilter = "!upgrade-create && !upgrade-verify && !upgrade-fingerprint && !upgrade-fingerprint-diff && !upgrade-cleanup && !upgrade-matrix-create && !upgrade-matrix-verify && !upgrade-matrix-cleanup && !release-pipelines"
 )
 
 var (
@@ -297,25 +303,198 @@ func (ci CI) UnregisterSprayproxy() {
 }
 
 func RunE2ETests() error {
-	labelFilter := utils.GetEnv("E2E_TEST_SUITE_LABEL", "!upgrade-create && !upgrade-verify && !upgrade-cleanup && !release-pipelines")
-	return runTests(labelFilter, "e2e-report.xml")
+	labelFilter := utils.GetEnv("E2E_TEST_SUITE_LABEL", defaultLabelFilter)
+
+	// Run only suites affected by the changes in e2e-tests repository PRs, unless the label was set explicitly
+	if os.Getenv("E2E_TEST_SUITE_LABEL") == "" && jobType == "presubmit" && openshiftJobSpec.Refs.Repo == "e2e-tests" {
+		selection, err := selectTests(utils.GetEnv("E2E_TEST_SELECTION_BASE_REF", "upstream/main"))
+		if err != nil {
+			klog.Warningf("failed to select tests based on changed files, running the full suite: %+v", err)
+		} else {
+			labelFilter = selection.LabelFilter(defaultLabelFilter)
+			if labelFilter == "" {
+				// only non-Go files unrelated to the suites (e.g. documentation) were changed
+				klog.Info("the PR does not affect any test suite - skipping e2e tests")
+				return nil
+			}
+		}
+	}
+
+	// The cluster is bootstrapped at this point, so also the cluster related requirements can be evaluated
+	report, err := runPreflightReport(true)
+	if err != nil {
+		return err
+	}
+	if newLabelFilter := report.ApplyToLabelFilter(labelFilter); newLabelFilter != labelFilter {
+		klog.Infof("suites with unmet requirements excluded, label filter changed from %q to %q", labelFilter, newLabelFilter)
+		labelFilter = newLabelFilter
+	}
+
+	shardArgs, err := shardFocusArgs(labelFilter)
+	if err != nil {
+		return err
+	}
+	if shardArgs != nil && len(shardArgs) == 0 {
+		klog.Info("no specs were assigned to this shard - skipping e2e tests")
+		return nil
+	}
+
+	return runTests(labelFilter, "e2e-report.xml", shardArgs...)
 }
 
-func PreflightChecks() error {
-	requiredEnv := []string{
-		"GITHUB_TOKEN",
-		"QUAY_TOKEN",
-		"DEFAULT_QUAY_ORG",
-		"DEFAULT_QUAY_ORG_TOKEN",
-	}
-	missingEnv := []string{}
-	for _, env := range requiredEnv {
-		if os.Getenv(env) == "" {
-			missingEnv = append(missingEnv, env)
+// shardFocusArgs returns Ginkgo --focus-file arguments selecting the specs of the shard specified by SHARD_INDEX and SHARD_COUNT env vars.
+// Top-level containers are distributed across the shards based on the durations from JUnit reports found in SHARD_TIMINGS_DIR.
+// nil is returned when sharding is not enabled (SHARD_COUNT is not greater than 1).
+func shardFocusArgs(labelFilter string) ([]string, error) {
+	cfg, err := config.Load("")
+	if err != nil {
+		return nil, err
+	}
+	shardCount, shardIndex := cfg.ShardCount, cfg.ShardIndex
+	if shardCount <= 1 {
+		return nil, nil
+	}
+	if shardIndex < 0 || shardIndex >= shardCount {
+		return nil, fmt.Errorf("SHARD_INDEX has to be in range [0, %d), got %d", shardCount, shardIndex)
+	}
+
+	shards, err := planShards(labelFilter, shardCount)
+	if err != nil {
+		return nil, err
+	}
+	sharding.PrintPlan(os.Stdout, shards)
+
+	args := []string{}
+	for _, f := range shards[shardIndex].FocusFiles() {
+		args = append(args, "--focus-file="+f)
+	}
+	klog.Infof("running shard %d of %d", shardIndex, shardCount)
+	return args, nil
+}
+
+// Prints the distribution of the e2e suites across the shards
+func (Local) PlanShards(shardCount int) error {
+	shards, err := planShards(utils.GetEnv("E2E_TEST_SUITE_LABEL", defaultLabelFilter), shardCount)
+	if err != nil {
+		return err
+	}
+	sharding.PrintPlan(os.Stdout, shards)
+	return nil
+}
+
+func planShards(labelFilter string, shardCount int) ([]sharding.Shard, error) {
+	cfg, err := config.Load("")
+	if err != nil {
+		return nil, err
+	}
+	history := &sharding.History{}
+	if cfg.ShardTimingsDir != "" {
+		if history, err = sharding.LoadHistory(cfg.ShardTimingsDir); err != nil {
+			return nil, err
 		}
 	}
-	if len(missingEnv) != 0 {
-		return fmt.Errorf("required env vars containing secrets (%s) not defined or empty", strings.Join(missingEnv, ","))
+
+	// Ginkgo does not support parallel (-p) dry runs
+	dryRunDir, err := os.MkdirTemp("", "shard-dry-run")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a temporary directory: %+v", err)
+	}
+	defer os.RemoveAll(dryRunDir)
+	if err := sh.Run("ginkgo", "--dry-run", "--output-dir="+dryRunDir, "--json-report=report.json", "--label-filter="+labelFilter, "./cmd", "--"); err != nil {
+		return nil, fmt.Errorf("failed to list specs with ginkgo dry run: %+v", err)
+	}
+	f, err := os.Open(filepath.Join(dryRunDir, "report.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ginkgo dry run report: %+v", err)
+	}
+	defer f.Close()
+	report, err := sharding.LoadDryRunReport(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ginkgo dry run report: %+v", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return sharding.Plan(sharding.UnitsFromReport(report, history, wd), shardCount)
+}
+
+// Prints test suites affected by changes in the current branch compared to baseRef (e.g. "upstream/main")
+// together with the reasoning and the resulting Ginkgo label filter
+func (Local) SelectTests(baseRef string) error {
+	selection, err := selectTests(baseRef)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("label filter: %q\n", selection.LabelFilter(defaultLabelFilter))
+	return nil
+}
+
+func selectTests(baseRef string) (*testselection.Selection, error) {
+	changedFiles, err := testselection.ChangedFiles(baseRef)
+	if err != nil {
+		return nil, err
+	}
+	suites, err := testselection.DiscoverSuites(".")
+	if err != nil {
+		return nil, err
+	}
+	selection := testselection.Analyze(changedFiles, suites)
+	selection.Print(os.Stdout)
+	return selection, nil
+}
+
+// runPreflightReport evaluates requirements of all registered suites and prints the report.
+// It returns an error if any of the requirements shared by all suites is not met.
+func runPreflightReport(withCluster bool) (*preflight.Report, error) {
+	report := preflightRegistry.Run(preflight.NewEnvironment(withCluster))
+	report.Print(os.Stdout)
+
+	if failures := report.CommonFailures(); len(failures) > 0 {
+		failed := make([]string, 0, len(failures))
+		for _, f := range failures {
+			failed = append(failed, fmt.Sprintf("%s (%s)", f.Check, f.Result.Message))
+		}
+		return report, fmt.Errorf("requirements shared by all suites are not met: %s", strings.Join(failed, ", "))
+	}
+	return report, nil
+}
+
+// Verifies the credentials (GitHub, Quay, GitLab and Slack tokens) together with their permissions
+// and reports which suites would fail because of them
+func Doctor() error {
+	if err := config.Init(); err != nil {
+		return err
+	}
+	report := doctorRegistry.Run(preflight.NewEnvironment(false))
+	report.PrintTable(os.Stdout)
+
+	failing := report.FailingSuites()
+	if len(failing) == 0 {
+		fmt.Println("\nall credentials are valid")
+		return nil
+	}
+	fmt.Printf("\nsuites which would fail: %s\n", strings.Join(failing, ", "))
+	return fmt.Errorf("%d suite(s) would fail because of invalid credentials", len(failing))
+}
+
+func PreflightChecks() error {
+	if err := config.Init(); err != nil {
+		return err
+	}
+
+	// Cluster related requirements are skipped since the cluster might not be bootstrapped yet
+	if _, err := runPreflightReport(false); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %+v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %+v", err)
 	}
 
 	for _, binaryName := range requiredBinaries {
@@ -331,6 +510,35 @@ func PreflightChecks() error {
 	return nil
 }
 
+// Prints the effective configuration (env vars, values from the config file referenced by E2E_CONFIG_FILE and defaults)
+// with secrets masked, followed by the list of problems found for every suite
+func (Config) Show() error {
+	if err := config.Init(); err != nil {
+		return err
+	}
+	cfg, err := config.Load("")
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %+v", err)
+	}
+	cfg.Print(os.Stdout)
+
+	fmt.Println()
+	commonErr := cfg.Validate()
+	if commonErr != nil {
+		fmt.Printf("problems affecting all suites:\n%v\n", commonErr)
+	}
+	for _, suite := range preflightRegistry.Suites() {
+		if len(config.RequiredFor(suite)) == 0 {
+			continue
+		}
+		// report only problems specific to the suite
+		if err := cfg.Validate(suite); err != nil && (commonErr == nil || err.Error() != commonErr.Error()) {
+			fmt.Printf("problems affecting suite %s:\n%v\n", suite, err)
+		}
+	}
+	return commonErr
+}
+
 func setRequiredEnvVars() error {
 	// Load test jobs require no additional setup
 	if strings.Contains(jobName, "-load-test") {
@@ -1157,7 +1365,8 @@ func (Local) TestUpgrade() error {
 }
 
 func UpgradeTestsWorkflow() error {
-	ic, err := BootstrapClusterForUpgrade()
+	//Use main branch of infra-deployments in redhat-appstudio org as default version for upgrade
+	ic, err := BootstrapClusterForUpgrade(upgrade.Revision{Org: upgrade.DefaultMatrixOrg, Branch: upgrade.DefaultMatrixBranch})
 	if err != nil {
 		klog.Errorf("%s", err)
 		return err
@@ -1181,6 +1390,12 @@ func UpgradeTestsWorkflow() error {
 		return err
 	}
 
+	err = CaptureWorkloadFingerprint()
+	if err != nil {
+		klog.Errorf("%s", err)
+		return err
+	}
+
 	err = UpgradeCluster()
 	if err != nil {
 		klog.Errorf("%s", err)
@@ -1199,6 +1414,12 @@ func UpgradeTestsWorkflow() error {
 		return err
 	}
 
+	err = CompareWorkloadFingerprint()
+	if err != nil {
+		klog.Errorf("%s", err)
+		return err
+	}
+
 	err = CleanWorkload()
 	if err != nil {
 		klog.Errorf("%s", err)
@@ -1208,10 +1429,130 @@ func UpgradeTestsWorkflow() error {
 	return nil
 }
 
-func BootstrapClusterForUpgrade() (*installation.InstallAppStudio, error) {
-	//Use main branch of infra-deployments in redhat-appstudio org as default version for upgrade
-	os.Setenv("INFRA_DEPLOYMENTS_ORG", "redhat-appstudio")
-	os.Setenv("INFRA_DEPLOYMENTS_BRANCH", "main")
+// Run Konflux upgrade matrix locally: for each entry of UPGRADE_MATRIX_FILE install Konflux from one revision of
+// infra-deployments, create workload, upgrade to another revision and verify the workload
+func (Local) TestUpgradeMatrix() error {
+	if err := PreflightChecks(); err != nil {
+		klog.Errorf("error when running preflight checks: %s", err)
+		return err
+	}
+
+	return UpgradeMatrixWorkflow()
+}
+
+// Run Konflux upgrade matrix in CI
+func (ci CI) TestUpgradeMatrix() error {
+	if err := ci.init(); err != nil {
+		return fmt.Errorf("error when running ci init: %v", err)
+	}
+
+	if err := PreflightChecks(); err != nil {
+		return fmt.Errorf("error when running preflight checks: %v", err)
+	}
+
+	if err := setRequiredEnvVars(); err != nil {
+		return fmt.Errorf("error when setting up required env vars: %v", err)
+	}
+
+	return UpgradeMatrixWorkflow()
+}
+
+// UpgradeMatrixWorkflow runs the upgrade workflow for the entry of UPGRADE_MATRIX_FILE selected by UPGRADE_MATRIX_ENTRIES.
+// Every entry has to start with a cluster without Konflux, so exactly one entry is run, in CI each entry is run by a separate job.
+// The result is recorded to upgrade-matrix-report.json in the artifact directory.
+func UpgradeMatrixWorkflow() error {
+	cfg, err := config.Load("")
+	if err != nil {
+		return err
+	}
+	if cfg.UpgradeMatrixFile == "" {
+		return fmt.Errorf("UPGRADE_MATRIX_FILE env var has to be set")
+	}
+	matrix, err := upgrade.LoadMatrix(cfg.UpgradeMatrixFile)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(cfg.UpgradeMatrixEntries) == "" {
+		return fmt.Errorf("UPGRADE_MATRIX_ENTRIES env var has to select one of the entries: %s", strings.Join(matrix.Names(), ", "))
+	}
+	entries, err := matrix.Select(cfg.UpgradeMatrixEntries)
+	if err != nil {
+		return err
+	}
+	if len(entries) != 1 {
+		return fmt.Errorf("UPGRADE_MATRIX_ENTRIES selects %d entries, but every entry needs a cluster without Konflux, so only one entry can be run", len(entries))
+	}
+
+	report := &upgrade.MatrixReport{}
+	for _, entry := range entries {
+		klog.Infof("running upgrade matrix entry %s: %s -> %s", entry.Name, entry.From, entry.To)
+		result := upgrade.NewEntryResult(entry)
+		report.Entries = append(report.Entries, result)
+		if err := runUpgradeMatrixEntry(result); err != nil {
+			klog.Errorf("upgrade matrix entry %s failed: %+v", entry.Name, err)
+		}
+	}
+
+	if err := report.Save(filepath.Join(artifactDir, "upgrade-matrix-report.json")); err != nil {
+		klog.Error(err)
+	}
+	if err := report.PrintTable(os.Stdout); err != nil {
+		klog.Error(err)
+	}
+	if failed := report.Failed(); len(failed) > 0 {
+		return fmt.Errorf("upgrade matrix entries failed: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// upgradeMatrixLabels returns the label filter of the upgrade phase (create, verify or cleanup), including the workload specs
+// which are run only by the upgrade matrix
+func upgradeMatrixLabels(phase string) string {
+	return fmt.Sprintf("upgrade-%s || upgrade-matrix-%s", phase, phase)
+}
+
+func runUpgradeMatrixEntry(result *upgrade.EntryResult) error {
+	junit := func(phase string) string {
+		return fmt.Sprintf("upgrade-matrix-%s-%s-report.xml", result.Name, phase)
+	}
+	var ic *installation.InstallAppStudio
+	var workloadCreated bool
+	phases := []struct {
+		name string
+		run  func() error
+	}{
+		{"install", func() (err error) {
+			ic, err = BootstrapClusterForUpgrade(result.From)
+			return err
+		}},
+		{"check-install", func() error { return CheckClusterAfterUpgrade(ic) }},
+		{"create-workload", func() error {
+			workloadCreated = true
+			return runTests(upgradeMatrixLabels("create"), junit("create"))
+		}},
+		{"verify-workload", func() error { return runTests(upgradeMatrixLabels("verify"), junit("verify")) }},
+		{"capture-fingerprint", func() error { return runTests("upgrade-fingerprint", junit("fingerprint")) }},
+		{"upgrade", func() error { return UpgradeClusterTo(result.To) }},
+		{"check-upgrade", func() error { return CheckClusterAfterUpgrade(ic) }},
+		{"verify-upgraded-workload", func() error { return runTests(upgradeMatrixLabels("verify"), junit("verify-upgraded")) }},
+		{"compare-fingerprint", func() error { return runTests("upgrade-fingerprint-diff", junit("fingerprint-diff")) }},
+	}
+	for _, phase := range phases {
+		if err := result.Run(phase.name, phase.run); err != nil {
+			if workloadCreated {
+				// do not leave the workload behind for the next entry
+				_ = result.Run("cleanup-workload", func() error { return runTests(upgradeMatrixLabels("cleanup"), junit("cleanup")) })
+			}
+			return fmt.Errorf("phase %s: %+v", phase.name, err)
+		}
+	}
+	return result.Run("cleanup-workload", func() error { return runTests(upgradeMatrixLabels("cleanup"), junit("cleanup")) })
+}
+
+// BootstrapClusterForUpgrade installs Konflux from the given revision of infra-deployments
+func BootstrapClusterForUpgrade(from upgrade.Revision) (*installation.InstallAppStudio, error) {
+	os.Setenv("INFRA_DEPLOYMENTS_ORG", from.Org)
+	os.Setenv("INFRA_DEPLOYMENTS_BRANCH", from.Branch)
 	ic, err := installation.NewAppStudioInstallController()
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize installation controller: %+v", err)
@@ -1221,7 +1562,12 @@ func BootstrapClusterForUpgrade() (*installation.InstallAppStudio, error) {
 }
 
 func UpgradeCluster() error {
-	return MergePRInRemote(utils.GetEnv("UPGRADE_BRANCH", ""), utils.GetEnv("UPGRADE_FORK_ORGANIZATION", "redhat-appstudio"), "./tmp/infra-deployments")
+	return UpgradeClusterTo(upgrade.Revision{Org: utils.GetEnv("UPGRADE_FORK_ORGANIZATION", "redhat-appstudio"), Branch: utils.GetEnv("UPGRADE_BRANCH", "")})
+}
+
+// UpgradeClusterTo merges the given revision of infra-deployments into the preview branch the cluster is synced from
+func UpgradeClusterTo(to upgrade.Revision) error {
+	return MergePRInRemote(to.Branch, to.Org, "./tmp/infra-deployments")
 }
 
 func CheckClusterAfterUpgrade(ic *installation.InstallAppStudio) error {
@@ -1236,13 +1582,25 @@ func VerifyWorkload() error {
 	return runTests("upgrade-verify", "upgrade-verify-report.xml")
 }
 
+// CaptureWorkloadFingerprint stores a normalised snapshot of the workload resources before upgrade
+func CaptureWorkloadFingerprint() error {
+	return runTests("upgrade-fingerprint", "upgrade-fingerprint-report.xml")
+}
+
+// CompareWorkloadFingerprint fails when the workload resources regressed after upgrade, the diff is stored to artifacts
+func CompareWorkloadFingerprint() error {
+	return runTests("upgrade-fingerprint-diff", "upgrade-fingerprint-diff-report.xml")
+}
+
 func CleanWorkload() error {
 	return runTests("upgrade-cleanup", "upgrade-verify-report.xml")
 }
 
-func runTests(labelsToRun string, junitReportFile string) error {
+func runTests(labelsToRun string, junitReportFile string, extraArgs ...string) error {
 	// added --output-interceptor-mode=none to mitigate RHTAPBUGS-34
-	return sh.RunV("ginkgo", "-p", "--output-interceptor-mode=none", "--timeout=90m", fmt.Sprintf("--output-dir=%s", artifactDir), "--junit-report="+junitReportFile, "--label-filter="+labelsToRun, "./cmd", "--")
+	args := []string{"-p", "--output-interceptor-mode=none", "--timeout=90m", fmt.Sprintf("--output-dir=%s", artifactDir), "--junit-report=" + junitReportFile, "--label-filter=" + labelsToRun}
+	args = append(args, extraArgs...)
+	return sh.RunV("ginkgo", append(args, "./cmd", "--")...)
 }
 
 func CleanupRegisteredPacServers() error {
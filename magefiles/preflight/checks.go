@@ -0,0 +1,232 @@
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EnvVar checks that the environment variable is defined and not empty
+func EnvVar(name string) Check {
+	return NewCheck("env "+name, func(_ *Environment) Result {
+		if os.Getenv(name) == "" {
+			return Fail("env var %s is not defined or empty", name)
+		}
+		return Pass("defined")
+	})
+}
+
+// GithubTokenScopes checks that the GitHub token stored in tokenEnv grants all the required OAuth scopes
+func GithubTokenScopes(tokenEnv string, scopes ...string) Check {
+	return NewCheck(fmt.Sprintf("github token %s scopes", tokenEnv), func(env *Environment) Result {
+		token := os.Getenv(tokenEnv)
+		if token == "" {
+			return Fail("env var %s is not defined or empty", tokenEnv)
+		}
+		res, err := doRequest(env, http.MethodGet, env.GithubAPIURL+"/user", "Authorization", "token "+token)
+		if err != nil {
+			return Fail("%+v", err)
+		}
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return Fail("GitHub API returned status code %d", res.StatusCode)
+		}
+		header := res.Header.Get("X-OAuth-Scopes")
+		if header == "" {
+			return Skip("token does not report its scopes (fine-grained token?)")
+		}
+		granted := splitScopes(header, ",")
+		if missing := missingScopes(granted, scopes); len(missing) > 0 {
+			return Fail("missing scopes: %s", strings.Join(missing, ", "))
+		}
+		return Pass("granted scopes: %s", strings.Join(granted, ", "))
+	})
+}
+
+// GitlabTokenScopes checks that the GitLab personal access token stored in tokenEnv is active and grants the required scopes
+func GitlabTokenScopes(tokenEnv string, scopes ...string) Check {
+	return NewCheck(fmt.Sprintf("gitlab token %s scopes", tokenEnv), func(env *Environment) Result {
+		token := os.Getenv(tokenEnv)
+		if token == "" {
+			return Fail("env var %s is not defined or empty", tokenEnv)
+		}
+		res, err := doRequest(env, http.MethodGet, env.GitlabAPIURL+"/personal_access_tokens/self", "PRIVATE-TOKEN", token)
+		if err != nil {
+			return Fail("%+v", err)
+		}
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return Fail("GitLab API returned status code %d", res.StatusCode)
+		}
+		tokenInfo := struct {
+			Active bool     `json:"active"`
+			Scopes []string `json:"scopes"`
+		}{}
+		if err := json.NewDecoder(res.Body).Decode(&tokenInfo); err != nil {
+			return Fail("unable to parse GitLab API response: %+v", err)
+		}
+		if !tokenInfo.Active {
+			return Fail("token is not active")
+		}
+		if missing := missingScopes(tokenInfo.Scopes, scopes); len(missing) > 0 {
+			return Fail("missing scopes: %s", strings.Join(missing, ", "))
+		}
+		return Pass("granted scopes: %s", strings.Join(tokenInfo.Scopes, ", "))
+	})
+}
+
+// QuayOrgAdminToken checks that the Quay OAuth token stored in tokenEnv is able to administer the organization stored in orgEnv
+func QuayOrgAdminToken(tokenEnv, orgEnv string) Check {
+	return NewCheck(fmt.Sprintf("quay token %s for %s", tokenEnv, orgEnv), func(env *Environment) Result {
+		token, org := os.Getenv(tokenEnv), os.Getenv(orgEnv)
+		if token == "" || org == "" {
+			return Fail("env vars %s and %s have to be defined", tokenEnv, orgEnv)
+		}
+		// listing robot accounts requires the org admin permission
+		res, err := doRequest(env, http.MethodGet, fmt.Sprintf("%s/organization/%s/robots", env.QuayAPIURL, org), "Authorization", "Bearer "+token)
+		if err != nil {
+			return Fail("%+v", err)
+		}
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return Fail("token cannot administer organization %s, Quay API returned status code %d", org, res.StatusCode)
+		}
+		return Pass("token can administer organization %s", org)
+	})
+}
+
+// CRD checks that the cluster serves the resource in the given group version, e.g. CRD("appstudio.redhat.com/v1alpha1", "components")
+func CRD(groupVersion, resource string) Check {
+	return NewCheck(fmt.Sprintf("crd %s.%s", resource, groupVersion), func(env *Environment) Result {
+		if env.KubeClient == nil {
+			return Skip("cluster is not available")
+		}
+		list, err := env.KubeClient.Discovery().ServerResourcesForGroupVersion(groupVersion)
+		if err != nil {
+			return Fail("unable to discover resources for %s: %+v", groupVersion, err)
+		}
+		for _, r := range list.APIResources {
+			if r.Name == resource {
+				return Pass("served")
+			}
+		}
+		return Fail("resource %s is not served by %s", resource, groupVersion)
+	})
+}
+
+// DeploymentReady checks that all replicas of the deployment are ready, e.g. checks readiness of an operator
+func DeploymentReady(namespace, name string) Check {
+	return NewCheck(fmt.Sprintf("deployment %s/%s ready", namespace, name), func(env *Environment) Result {
+		if env.KubeClient == nil {
+			return Skip("cluster is not available")
+		}
+		d, err := env.KubeClient.AppsV1().Deployments(namespace).Get(context.Background(), name, metav1.GetOptions{})
+		if err != nil {
+			return Fail("%+v", err)
+		}
+		desired := int32(1)
+		if d.Spec.Replicas != nil {
+			desired = *d.Spec.Replicas
+		}
+		if d.Status.ReadyReplicas < desired {
+			return Fail("%d/%d replicas ready", d.Status.ReadyReplicas, desired)
+		}
+		return Pass("%d/%d replicas ready", d.Status.ReadyReplicas, desired)
+	})
+}
+
+// MinClusterVersion checks that the OpenShift cluster version is at least the given major.minor version, e.g. "4.14"
+func MinClusterVersion(minVersion string) Check {
+	return NewCheck("cluster version >= "+minVersion, func(env *Environment) Result {
+		if env.ConfigClient == nil {
+			return Skip("cluster is not available")
+		}
+		cv, err := env.ConfigClient.ConfigV1().ClusterVersions().Get(context.Background(), "version", metav1.GetOptions{})
+		if err != nil {
+			return Fail("unable to get cluster version: %+v", err)
+		}
+		current := cv.Status.Desired.Version
+		ok, err := versionAtLeast(current, minVersion)
+		if err != nil {
+			return Fail("%+v", err)
+		}
+		if !ok {
+			return Fail("cluster version %s is lower than %s", current, minVersion)
+		}
+		return Pass("cluster version %s", current)
+	})
+}
+
+func doRequest(env *Environment, method, url, authHeader, authValue string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(context.Background(), method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(authHeader, authValue)
+	res, err := env.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error when sending request to '%s': %+v", url, err)
+	}
+	return res, nil
+}
+
+func splitScopes(s, sep string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(s, sep) {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// missingScopes returns the required scopes which are not covered by the granted ones
+func missingScopes(granted, required []string) []string {
+	var missing []string
+	for _, r := range required {
+		if !hasScope(granted, r) {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
+
+// hasScope takes into account GitHub scope hierarchy, e.g. "admin:org" covers "read:org" and "repo" covers "repo:status"
+func hasScope(granted []string, required string) bool {
+	for _, g := range granted {
+		if g == required {
+			return true
+		}
+		if g == "repo" && (strings.HasPrefix(required, "repo:") || required == "public_repo") {
+			return true
+		}
+		if access, resource, found := strings.Cut(required, ":"); found {
+			switch {
+			case g == "admin:"+resource && (access == "read" || access == "write"):
+				return true
+			case g == "write:"+resource && access == "read":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func versionAtLeast(current, minimum string) (bool, error) {
+	var curMajor, curMinor, minMajor, minMinor int
+	if _, err := fmt.Sscanf(current, "%d.%d", &curMajor, &curMinor); err != nil {
+		return false, fmt.Errorf("unable to parse version %q: %+v", current, err)
+	}
+	if _, err := fmt.Sscanf(minimum, "%d.%d", &minMajor, &minMinor); err != nil {
+		return false, fmt.Errorf("unable to parse version %q: %+v", minimum, err)
+	}
+	if curMajor != minMajor {
+		return curMajor > minMajor, nil
+	}
+	return curMinor >= minMinor, nil
+}
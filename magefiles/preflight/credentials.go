@@ -0,0 +1,149 @@
+package preflight
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/bradleyfalzon/ghinstallation/v2"
+	"github.com/konflux-ci/e2e-tests/pkg/utils"
+	"github.com/konflux-ci/e2e-tests/pkg/utils/build"
+	"github.com/konflux-ci/image-controller/pkg/quay"
+)
+
+// GithubOrgMembership checks that the owner of the GitHub token stored in tokenEnv is an active member of the organization
+// stored in orgEnv (defaultOrg is used when orgEnv is not set)
+func GithubOrgMembership(tokenEnv, orgEnv, defaultOrg string) Check {
+	return NewCheck(fmt.Sprintf("github token %s org membership", tokenEnv), func(env *Environment) Result {
+		token := os.Getenv(tokenEnv)
+		if token == "" {
+			return Fail("env var %s is not defined or empty", tokenEnv)
+		}
+		org := utils.GetEnv(orgEnv, defaultOrg)
+		res, err := doRequest(env, http.MethodGet, fmt.Sprintf("%s/user/memberships/orgs/%s", env.GithubAPIURL, org), "Authorization", "token "+token)
+		if err != nil {
+			return Fail("%+v", err)
+		}
+		defer res.Body.Close()
+		switch res.StatusCode {
+		case http.StatusOK:
+		case http.StatusNotFound, http.StatusForbidden:
+			return Fail("token owner is not a member of the %s organization (or the token lacks read:org scope)", org)
+		default:
+			return Fail("GitHub API returned status code %d", res.StatusCode)
+		}
+		membership := struct {
+			State string `json:"state"`
+			Role  string `json:"role"`
+		}{}
+		if err := json.NewDecoder(res.Body).Decode(&membership); err != nil {
+			return Fail("unable to parse GitHub API response: %+v", err)
+		}
+		if membership.State != "active" {
+			return Fail("membership in the %s organization is %s", org, membership.State)
+		}
+		return Pass("%s of the %s organization", membership.Role, org)
+	})
+}
+
+// GithubAppInstallation checks that the GitHub App used by Pipelines as Code (see utils.GetGithubAppID) is installed
+// in the organization stored in orgEnv. The app is authenticated with the base64 encoded private key stored in privateKeyEnv.
+func GithubAppInstallation(privateKeyEnv, orgEnv, defaultOrg string) Check {
+	return NewCheck("github app installation", func(env *Environment) Result {
+		appID, err := utils.GetGithubAppID()
+		if err != nil {
+			return Fail("unable to parse GitHub App ID: %+v", err)
+		}
+		encodedKey := os.Getenv(privateKeyEnv)
+		if encodedKey == "" {
+			return Fail("env var %s is not defined or empty", privateKeyEnv)
+		}
+		privateKey, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil {
+			return Fail("env var %s is not base64 encoded: %+v", privateKeyEnv, err)
+		}
+		transport := env.HTTPClient.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		appTransport, err := ghinstallation.NewAppsTransport(transport, appID, privateKey)
+		if err != nil {
+			return Fail("unable to use the private key from %s: %+v", privateKeyEnv, err)
+		}
+		org := utils.GetEnv(orgEnv, defaultOrg)
+		client := &http.Client{Transport: appTransport, Timeout: env.HTTPClient.Timeout}
+		res, err := client.Get(fmt.Sprintf("%s/orgs/%s/installation", env.GithubAPIURL, org))
+		if err != nil {
+			return Fail("error when getting the installation of GitHub App %d: %+v", appID, err)
+		}
+		defer res.Body.Close()
+		switch res.StatusCode {
+		case http.StatusOK:
+			return Pass("GitHub App %d is installed in the %s organization", appID, org)
+		case http.StatusNotFound:
+			return Fail("GitHub App %d is not installed in the %s organization", appID, org)
+		case http.StatusUnauthorized:
+			return Fail("private key from %s does not belong to GitHub App %d", privateKeyEnv, appID)
+		default:
+			return Fail("GitHub API returned status code %d", res.StatusCode)
+		}
+	})
+}
+
+// QuayPrivateRepoSupport checks that the plan of the quay organization stored in orgEnv allows private repositories.
+// The check creates (and deletes) a sample private repository with the OAuth token stored in tokenEnv.
+func QuayPrivateRepoSupport(tokenEnv, orgEnv string) Check {
+	return NewCheck(fmt.Sprintf("quay org %s private repositories", orgEnv), func(env *Environment) Result {
+		token, org := os.Getenv(tokenEnv), os.Getenv(orgEnv)
+		if token == "" || org == "" {
+			return Fail("env vars %s and %s have to be defined", tokenEnv, orgEnv)
+		}
+		supported, err := build.DoesQuayOrgSupportPrivateRepoWithClient(quay.NewQuayClient(env.HTTPClient, token, env.QuayAPIURL), org)
+		if err != nil {
+			return Fail("%+v", err)
+		}
+		if !supported {
+			return Fail("plan of the %s organization does not allow private repositories", org)
+		}
+		return Pass("private repositories are supported")
+	})
+}
+
+// SlackBotToken checks that the Slack bot token stored in tokenEnv is valid and grants the required scopes.
+// The token is optional, the check is skipped when it is not set.
+func SlackBotToken(tokenEnv string, scopes ...string) Check {
+	return NewCheck(fmt.Sprintf("slack token %s", tokenEnv), func(env *Environment) Result {
+		token := os.Getenv(tokenEnv)
+		if token == "" {
+			return Skip("env var %s is not set, alerts will not be sent", tokenEnv)
+		}
+		res, err := doRequest(env, http.MethodPost, env.SlackAPIURL+"/auth.test", "Authorization", "Bearer "+token)
+		if err != nil {
+			return Fail("%+v", err)
+		}
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return Fail("Slack API returned status code %d", res.StatusCode)
+		}
+		auth := struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error"`
+			Team  string `json:"team"`
+			User  string `json:"user"`
+		}{}
+		if err := json.NewDecoder(res.Body).Decode(&auth); err != nil {
+			return Fail("unable to parse Slack API response: %+v", err)
+		}
+		if !auth.OK {
+			return Fail("token is not valid: %s", auth.Error)
+		}
+		granted := splitScopes(res.Header.Get("X-OAuth-Scopes"), ",")
+		if missing := missingScopes(granted, scopes); len(missing) > 0 {
+			return Fail("missing scopes: %s", strings.Join(missing, ", "))
+		}
+		return Pass("authenticated as %s in %s", auth.User, auth.Team)
+	})
+}
@@ -0,0 +1,126 @@
+package preflight
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAPI is a local stand-in for GitHub, Quay and Slack APIs
+func fakeAPI(t *testing.T, handlers map[string]http.HandlerFunc) (*httptest.Server, *Environment) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h, ok := handlers[r.Method+" "+r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		h(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &Environment{HTTPClient: server.Client(), GithubAPIURL: server.URL, QuayAPIURL: server.URL, SlackAPIURL: server.URL}
+}
+
+func TestGithubOrgMembership(t *testing.T) {
+	_, env := fakeAPI(t, map[string]http.HandlerFunc{
+		"GET /user/memberships/orgs/my-org": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte(`{"state": "active", "role": "admin"}`))
+		},
+		"GET /user/memberships/orgs/pending-org": func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"state": "pending", "role": "member"}`))
+		},
+	})
+	t.Setenv("TEST_GITHUB_TOKEN", "secret")
+
+	t.Setenv("TEST_GITHUB_ORG", "my-org")
+	assert.Equal(t, Pass("admin of the my-org organization"), GithubOrgMembership("TEST_GITHUB_TOKEN", "TEST_GITHUB_ORG", "").Run(env))
+	t.Setenv("TEST_GITHUB_ORG", "pending-org")
+	assert.Equal(t, Fail("membership in the pending-org organization is pending"), GithubOrgMembership("TEST_GITHUB_TOKEN", "TEST_GITHUB_ORG", "").Run(env))
+	t.Setenv("TEST_GITHUB_ORG", "")
+	assert.Equal(t, StatusFail, GithubOrgMembership("TEST_GITHUB_TOKEN", "TEST_GITHUB_ORG", "other-org").Run(env).Status)
+}
+
+func TestGithubAppInstallation(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	_, env := fakeAPI(t, map[string]http.HandlerFunc{
+		"GET /orgs/my-org/installation": func(w http.ResponseWriter, r *http.Request) {
+			assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "Bearer "), "the app has to authenticate with JWT")
+			_, _ = w.Write([]byte(`{"id": 1}`))
+		},
+	})
+	t.Setenv("E2E_PAC_GITHUB_APP_ID", "123")
+	t.Setenv("TEST_APP_KEY", base64.StdEncoding.EncodeToString(keyPEM))
+
+	t.Setenv("TEST_GITHUB_ORG", "my-org")
+	assert.Equal(t, StatusPass, GithubAppInstallation("TEST_APP_KEY", "TEST_GITHUB_ORG", "").Run(env).Status)
+	t.Setenv("TEST_GITHUB_ORG", "other-org")
+	assert.Equal(t, Fail("GitHub App 123 is not installed in the other-org organization"), GithubAppInstallation("TEST_APP_KEY", "TEST_GITHUB_ORG", "").Run(env))
+	t.Setenv("TEST_APP_KEY", "not a key")
+	assert.Equal(t, StatusFail, GithubAppInstallation("TEST_APP_KEY", "TEST_GITHUB_ORG", "").Run(env).Status)
+}
+
+func TestQuayPrivateRepoSupport(t *testing.T) {
+	deleted := false
+	_, env := fakeAPI(t, map[string]http.HandlerFunc{
+		"POST /repository": func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "Bearer free-plan" {
+				w.WriteHeader(http.StatusPaymentRequired)
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"namespace": "my-org", "name": "test-private-repo"}`))
+		},
+		"DELETE /repository/my-org/test-private-repo": func(w http.ResponseWriter, _ *http.Request) {
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		},
+	})
+	t.Setenv("TEST_QUAY_ORG", "my-org")
+
+	t.Setenv("TEST_QUAY_TOKEN", "paid-plan")
+	assert.Equal(t, StatusPass, QuayPrivateRepoSupport("TEST_QUAY_TOKEN", "TEST_QUAY_ORG").Run(env).Status)
+	assert.True(t, deleted, "the sample repository has to be deleted")
+
+	t.Setenv("TEST_QUAY_TOKEN", "free-plan")
+	assert.Equal(t, Fail("plan of the my-org organization does not allow private repositories"), QuayPrivateRepoSupport("TEST_QUAY_TOKEN", "TEST_QUAY_ORG").Run(env))
+}
+
+func TestSlackBotToken(t *testing.T) {
+	_, env := fakeAPI(t, map[string]http.HandlerFunc{
+		"POST /auth.test": func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer valid" {
+				_, _ = w.Write([]byte(`{"ok": false, "error": "invalid_auth"}`))
+				return
+			}
+			w.Header().Set("X-OAuth-Scopes", "chat:write,channels:read")
+			_, _ = w.Write([]byte(`{"ok": true, "team": "konflux", "user": "e2e-bot"}`))
+		},
+	})
+
+	t.Setenv("TEST_SLACK_TOKEN", "valid")
+	assert.Equal(t, Pass("authenticated as e2e-bot in konflux"), SlackBotToken("TEST_SLACK_TOKEN", "chat:write").Run(env))
+	assert.Equal(t, Fail("missing scopes: files:write"), SlackBotToken("TEST_SLACK_TOKEN", "files:write").Run(env))
+	t.Setenv("TEST_SLACK_TOKEN", "invalid")
+	assert.Equal(t, Fail("token is not valid: invalid_auth"), SlackBotToken("TEST_SLACK_TOKEN").Run(env))
+	t.Setenv("TEST_SLACK_TOKEN", "")
+	assert.Equal(t, StatusSkip, SlackBotToken("TEST_SLACK_TOKEN").Run(env).Status)
+}
+
+func TestFailingSuites(t *testing.T) {
+	r := NewRegistry()
+	r.RequireForAll(staticCheck("token", StatusFail))
+	r.Register(Suite{Label: "build", Requirements: []Check{staticCheck("a", StatusPass)}}, Suite{Label: "ec", Requirements: []Check{staticCheck("b", StatusPass)}})
+
+	assert.Equal(t, []string{"build", "ec"}, r.Run(&Environment{}).FailingSuites())
+}
@@ -0,0 +1,284 @@
+package preflight
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+)
+
+const (
+	DefaultGithubAPIURL = "https://api.github.com"
+	DefaultGitlabAPIURL = "https://gitlab.com/api/v4"
+	DefaultQuayAPIURL   = "https://quay.io/api/v1"
+	DefaultSlackAPIURL  = "https://slack.com/api"
+
+	// commonRequirements is the name used in the report for requirements shared by all suites
+	commonRequirements = "common"
+)
+
+type Status string
+
+const (
+	StatusPass Status = "PASS"
+	StatusFail Status = "FAIL"
+	StatusSkip Status = "SKIP"
+)
+
+// Result is the outcome of a single Check
+type Result struct {
+	Status  Status
+	Message string
+}
+
+func Pass(format string, args ...interface{}) Result {
+	return Result{Status: StatusPass, Message: fmt.Sprintf(format, args...)}
+}
+
+func Fail(format string, args ...interface{}) Result {
+	return Result{Status: StatusFail, Message: fmt.Sprintf(format, args...)}
+}
+
+func Skip(format string, args ...interface{}) Result {
+	return Result{Status: StatusSkip, Message: fmt.Sprintf(format, args...)}
+}
+
+// Check is a single requirement which has to be met before running tests
+type Check interface {
+	// Name identifies the check in the report. Checks with the same name are evaluated only once per run.
+	Name() string
+	Run(env *Environment) Result
+}
+
+type checkFunc struct {
+	name string
+	run  func(env *Environment) Result
+}
+
+func (c *checkFunc) Name() string {
+	return c.name
+}
+
+func (c *checkFunc) Run(env *Environment) Result {
+	return c.run(env)
+}
+
+// NewCheck creates a Check from a function
+func NewCheck(name string, run func(env *Environment) Result) Check {
+	return &checkFunc{name: name, run: run}
+}
+
+// Suite describes requirements of a test suite identified by its Ginkgo label
+type Suite struct {
+	// Ginkgo label used for selecting the suite
+	Label        string
+	Requirements []Check
+}
+
+// Environment holds the clients used by checks. Cluster clients are nil
+// when the cluster is not reachable (e.g. it was not bootstrapped yet); checks requiring them are skipped.
+type Environment struct {
+	HTTPClient   *http.Client
+	GithubAPIURL string
+	GitlabAPIURL string
+	QuayAPIURL   string
+	SlackAPIURL  string
+
+	KubeClient   kubernetes.Interface
+	ConfigClient configv1client.Interface
+}
+
+// NewEnvironment creates an environment for running checks against the public service endpoints.
+// If withCluster is true, cluster clients are created from the default kubeconfig.
+func NewEnvironment(withCluster bool) *Environment {
+	env := &Environment{
+		HTTPClient:   &http.Client{Timeout: 30 * time.Second},
+		GithubAPIURL: DefaultGithubAPIURL,
+		GitlabAPIURL: DefaultGitlabAPIURL,
+		QuayAPIURL:   DefaultQuayAPIURL,
+		SlackAPIURL:  DefaultSlackAPIURL,
+	}
+	if !withCluster {
+		return env
+	}
+
+	kubeconfig, err := config.GetConfig()
+	if err != nil {
+		klog.Warningf("cluster checks will be skipped, unable to get kubeconfig: %+v", err)
+		return env
+	}
+	if env.KubeClient, err = kubernetes.NewForConfig(kubeconfig); err != nil {
+		klog.Warningf("cluster checks will be skipped, unable to create kubernetes client: %+v", err)
+		return env
+	}
+	if env.ConfigClient, err = configv1client.NewForConfig(kubeconfig); err != nil {
+		klog.Warningf("unable to create openshift config client: %+v", err)
+	}
+	return env
+}
+
+// Registry holds requirements shared by all suites and requirements of the individual suites
+type Registry struct {
+	common []Check
+	suites []Suite
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// RequireForAll registers checks which have to pass in order to run any suite
+func (r *Registry) RequireForAll(checks ...Check) {
+	r.common = append(r.common, checks...)
+}
+
+// Register registers suite requirements. Requirements of suites with the same label are merged.
+func (r *Registry) Register(suites ...Suite) {
+	for _, s := range suites {
+		merged := false
+		for i := range r.suites {
+			if r.suites[i].Label == s.Label {
+				r.suites[i].Requirements = append(r.suites[i].Requirements, s.Requirements...)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			r.suites = append(r.suites, s)
+		}
+	}
+}
+
+// Suites returns labels of all registered suites
+func (r *Registry) Suites() []string {
+	labels := make([]string, 0, len(r.suites))
+	for _, s := range r.suites {
+		labels = append(labels, s.Label)
+	}
+	return labels
+}
+
+// Run evaluates all registered checks and returns the report
+func (r *Registry) Run(env *Environment) *Report {
+	cache := map[string]Result{}
+	run := func(c Check) Result {
+		if res, ok := cache[c.Name()]; ok {
+			return res
+		}
+		res := c.Run(env)
+		cache[c.Name()] = res
+		return res
+	}
+
+	report := &Report{}
+	for _, c := range r.common {
+		report.Entries = append(report.Entries, Entry{Suite: commonRequirements, Check: c.Name(), Result: run(c)})
+	}
+	for _, s := range r.suites {
+		for _, c := range s.Requirements {
+			report.Entries = append(report.Entries, Entry{Suite: s.Label, Check: c.Name(), Result: run(c)})
+		}
+	}
+	return report
+}
+
+// Entry is a result of a single check for a single suite
+type Entry struct {
+	Suite  string
+	Check  string
+	Result Result
+}
+
+type Report struct {
+	Entries []Entry
+}
+
+// CommonFailures returns the failed checks which are required by all suites
+func (r *Report) CommonFailures() []Entry {
+	var failures []Entry
+	for _, e := range r.Entries {
+		if e.Suite == commonRequirements && e.Result.Status == StatusFail {
+			failures = append(failures, e)
+		}
+	}
+	return failures
+}
+
+// ExcludedSuites returns sorted labels of suites with at least one failed requirement
+func (r *Report) ExcludedSuites() []string {
+	excluded := map[string]bool{}
+	for _, e := range r.Entries {
+		if e.Suite != commonRequirements && e.Result.Status == StatusFail {
+			excluded[e.Suite] = true
+		}
+	}
+	labels := make([]string, 0, len(excluded))
+	for l := range excluded {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// ApplyToLabelFilter extends the Ginkgo label filter so that suites with unmet requirements are not selected
+func (r *Report) ApplyToLabelFilter(labelFilter string) string {
+	excluded := r.ExcludedSuites()
+	if len(excluded) == 0 {
+		return labelFilter
+	}
+	exclusions := make([]string, 0, len(excluded))
+	for _, l := range excluded {
+		exclusions = append(exclusions, "!"+l)
+	}
+	if strings.TrimSpace(labelFilter) == "" {
+		return strings.Join(exclusions, " && ")
+	}
+	return fmt.Sprintf("(%s) && %s", labelFilter, strings.Join(exclusions, " && "))
+}
+
+// FailingSuites returns sorted labels of suites which would fail, i.e. all suites in the report
+// if any of the common requirements is not met, otherwise the suites with unmet requirements
+func (r *Report) FailingSuites() []string {
+	if len(r.CommonFailures()) == 0 {
+		return r.ExcludedSuites()
+	}
+	all := map[string]bool{}
+	for _, e := range r.Entries {
+		if e.Suite != commonRequirements {
+			all[e.Suite] = true
+		}
+	}
+	labels := make([]string, 0, len(all))
+	for l := range all {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// PrintTable writes the results of all checks as a table
+func (r *Report) PrintTable(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SUITE\tCHECK\tSTATUS\tDETAILS")
+	for _, e := range r.Entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.Suite, e.Check, e.Result.Status, e.Result.Message)
+	}
+	tw.Flush()
+}
+
+// Print writes the report as a table followed by the suites which will be excluded
+func (r *Report) Print(w io.Writer) {
+	r.PrintTable(w)
+
+	if excluded := r.ExcludedSuites(); len(excluded) > 0 {
+		fmt.Fprintf(w, "\nsuites with unmet requirements (will be excluded): %s\n", strings.Join(excluded, ", "))
+	}
+}
@@ -0,0 +1,96 @@
+package preflight
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func staticCheck(name string, status Status) Check {
+	return NewCheck(name, func(_ *Environment) Result {
+		return Result{Status: status}
+	})
+}
+
+func TestReportExcludesSuitesWithFailedRequirements(t *testing.T) {
+	r := NewRegistry()
+	r.RequireForAll(staticCheck("common", StatusPass))
+	r.Register(
+		Suite{Label: "build", Requirements: []Check{staticCheck("a", StatusPass), staticCheck("b", StatusFail)}},
+		Suite{Label: "ec", Requirements: []Check{staticCheck("c", StatusSkip)}},
+		Suite{Label: "spi-suite", Requirements: []Check{staticCheck("b", StatusFail)}},
+	)
+
+	report := r.Run(&Environment{})
+
+	assert.Empty(t, report.CommonFailures())
+	assert.Equal(t, []string{"build", "spi-suite"}, report.ExcludedSuites())
+	assert.Equal(t, "(ec || build) && !build && !spi-suite", report.ApplyToLabelFilter("ec || build"))
+	assert.Equal(t, "!build && !spi-suite", report.ApplyToLabelFilter(""))
+
+	out := &bytes.Buffer{}
+	report.Print(out)
+	assert.Contains(t, out.String(), "SUITE")
+	assert.Contains(t, out.String(), "will be excluded): build, spi-suite")
+}
+
+func TestRegistryEvaluatesSharedCheckOnce(t *testing.T) {
+	calls := 0
+	shared := NewCheck("shared", func(_ *Environment) Result {
+		calls++
+		return Pass("")
+	})
+	r := NewRegistry()
+	r.Register(Suite{Label: "a", Requirements: []Check{shared}}, Suite{Label: "b", Requirements: []Check{shared}})
+	r.Register(Suite{Label: "a", Requirements: []Check{staticCheck("other", StatusFail)}})
+
+	report := r.Run(&Environment{})
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []string{"a", "b"}, r.Suites())
+	assert.Equal(t, []string{"a"}, report.ExcludedSuites())
+	assert.Equal(t, "(a || b) && !a", report.ApplyToLabelFilter("a || b"))
+}
+
+func TestGithubTokenScopes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+		w.Header().Set("X-OAuth-Scopes", "repo, admin:org, delete_repo")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	t.Setenv("TEST_GITHUB_TOKEN", "secret")
+	env := &Environment{HTTPClient: server.Client(), GithubAPIURL: server.URL}
+
+	assert.Equal(t, StatusPass, GithubTokenScopes("TEST_GITHUB_TOKEN", "repo:status", "read:org", "delete_repo").Run(env).Status)
+
+	res := GithubTokenScopes("TEST_GITHUB_TOKEN", "workflow").Run(env)
+	assert.Equal(t, StatusFail, res.Status)
+	assert.Equal(t, "missing scopes: workflow", res.Message)
+}
+
+func TestClusterChecksAreSkippedWithoutCluster(t *testing.T) {
+	env := &Environment{}
+	assert.Equal(t, StatusSkip, CRD("appstudio.redhat.com/v1alpha1", "components").Run(env).Status)
+	assert.Equal(t, StatusSkip, DeploymentReady("ns", "name").Run(env).Status)
+	assert.Equal(t, StatusSkip, MinClusterVersion("4.12").Run(env).Status)
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	for _, tc := range []struct {
+		current, minimum string
+		expected         bool
+	}{
+		{"4.14.3", "4.12", true},
+		{"4.12.0", "4.12", true},
+		{"4.11.9", "4.12", false},
+		{"5.0.0", "4.12", true},
+	} {
+		ok, err := versionAtLeast(tc.current, tc.minimum)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, ok, "%s >= %s", tc.current, tc.minimum)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"github.com/konflux-ci/e2e-tests/magefiles/installation"
+	"github.com/konflux-ci/e2e-tests/magefiles/preflight"
+	"github.com/konflux-ci/e2e-tests/pkg/config"
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+)
+
+// preflightRegistry holds requirements of the test suites. Suites whose requirements are not met
+// are excluded from the Ginkgo label filter when running e2e tests.
+var preflightRegistry = newPreflightRegistry()
+
+// doctorRegistry holds credentials (and their permissions) required by the test suites, used by the doctor target
+var doctorRegistry = newDoctorRegistry()
+
+func newPreflightRegistry() *preflight.Registry {
+	r := preflight.NewRegistry()
+
+	// Env vars required by the suites are declared in the config.Config struct
+	r.RequireForAll(requiredEnvVars(config.CommonRequirement)...)
+	r.RequireForAll(
+		preflight.GithubTokenScopes(constants.GITHUB_TOKEN_ENV, "repo", "delete_repo"),
+		preflight.QuayOrgAdminToken("DEFAULT_QUAY_ORG_TOKEN", constants.DEFAULT_QUAY_ORG_ENV),
+	)
+
+	r.Register(
+		preflight.Suite{Label: "build", Requirements: []preflight.Check{
+			preflight.CRD("appstudio.redhat.com/v1alpha1", "components"),
+			preflight.CRD("pipelinesascode.tekton.dev/v1alpha1", "repositories"),
+			preflight.DeploymentReady("build-service", "build-service-controller-manager"),
+			// Pipelines as Code of OpenShift Pipelines
+			preflight.MinClusterVersion("4.12"),
+		}},
+		preflight.Suite{Label: "jvm-build", Requirements: []preflight.Check{
+			preflight.CRD("jvmbuildservice.io/v1alpha1", "jbsconfigs"),
+		}},
+		preflight.Suite{Label: "multi-platform", Requirements: []preflight.Check{
+			preflight.MinClusterVersion("4.12"),
+		}},
+		preflight.Suite{Label: "integration-service", Requirements: []preflight.Check{
+			preflight.CRD("appstudio.redhat.com/v1beta1", "integrationtestscenarios"),
+			preflight.DeploymentReady("integration-service", "integration-service-controller-manager"),
+		}},
+		preflight.Suite{Label: "gitlab-status-reporting", Requirements: []preflight.Check{
+			preflight.GitlabTokenScopes(constants.GITLAB_TOKEN_ENV, "api"),
+		}},
+		preflight.Suite{Label: "release-service", Requirements: []preflight.Check{
+			preflight.CRD("appstudio.redhat.com/v1alpha1", "releaseplanadmissions"),
+			preflight.DeploymentReady("release-service", "release-service-controller-manager"),
+		}},
+		preflight.Suite{Label: "release-pipelines", Requirements: []preflight.Check{
+			preflight.CRD("appstudio.redhat.com/v1alpha1", "releaseplanadmissions"),
+		}},
+		preflight.Suite{Label: "pushPyxis"},
+		preflight.Suite{Label: "ec", Requirements: []preflight.Check{
+			preflight.CRD("appstudio.redhat.com/v1alpha1", "enterprisecontractpolicies"),
+		}},
+		preflight.Suite{Label: "rhtap-demo", Requirements: []preflight.Check{
+			preflight.CRD("appstudio.redhat.com/v1alpha1", "applications"),
+		}},
+		preflight.Suite{Label: "spi-suite", Requirements: []preflight.Check{
+			preflight.CRD("appstudio.redhat.com/v1beta1", "spiaccesstokens"),
+		}},
+		preflight.Suite{Label: "remote-secret", Requirements: []preflight.Check{
+			preflight.CRD("appstudio.redhat.com/v1beta1", "remotesecrets"),
+		}},
+	)
+
+	for _, suite := range r.Suites() {
+		r.Register(preflight.Suite{Label: suite, Requirements: requiredEnvVars(suite)})
+	}
+
+	return r
+}
+
+func newDoctorRegistry() *preflight.Registry {
+	r := preflight.NewRegistry()
+
+	r.RequireForAll(
+		preflight.GithubTokenScopes(constants.GITHUB_TOKEN_ENV, "repo", "delete_repo"),
+		preflight.GithubOrgMembership(constants.GITHUB_TOKEN_ENV, constants.GITHUB_E2E_ORGANIZATION_ENV, installation.DEFAULT_LOCAL_FORK_ORGANIZATION),
+		preflight.QuayOrgAdminToken("DEFAULT_QUAY_ORG_TOKEN", constants.DEFAULT_QUAY_ORG_ENV),
+	)
+
+	// Suites using Pipelines as Code with the GitHub App
+	for _, suite := range []string{"build", "rhtap-demo", "integration-service"} {
+		r.Register(preflight.Suite{Label: suite, Requirements: []preflight.Check{
+			preflight.GithubAppInstallation("E2E_PAC_GITHUB_APP_PRIVATE_KEY", constants.GITHUB_E2E_ORGANIZATION_ENV, installation.DEFAULT_LOCAL_FORK_ORGANIZATION),
+		}})
+	}
+	r.Register(
+		preflight.Suite{Label: "build", Requirements: []preflight.Check{
+			preflight.QuayPrivateRepoSupport("DEFAULT_QUAY_ORG_TOKEN", constants.DEFAULT_QUAY_ORG_ENV),
+		}},
+		preflight.Suite{Label: "gitlab-status-reporting", Requirements: []preflight.Check{
+			preflight.GitlabTokenScopes(constants.GITLAB_TOKEN_ENV, "api"),
+		}},
+		// not a test suite - alerts about CI failures are sent to Slack
+		preflight.Suite{Label: "slack-alerts", Requirements: []preflight.Check{
+			preflight.SlackBotToken(constants.SLACK_BOT_TOKEN_ENV, "chat:write"),
+		}},
+	)
+
+	return r
+}
+
+func requiredEnvVars(suite string) []preflight.Check {
+	var checks []preflight.Check
+	for _, name := range config.RequiredFor(suite) {
+		checks = append(checks, preflight.EnvVar(name))
+	}
+	return checks
+}
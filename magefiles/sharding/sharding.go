@@ -0,0 +1,308 @@
+package sharding
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/reporters"
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+const (
+	// Weight of a spec without any historical data
+	defaultSpecDuration = time.Minute
+)
+
+var (
+	// Suites with labels matching these prefixes depend on each other (e.g. upgrade-create -> upgrade-verify -> upgrade-cleanup)
+	// and have to be executed within the same shard
+	dependentLabelPrefixes = []string{"upgrade-"}
+)
+
+// Unit is a group of specs which cannot be split across shards, i.e. a top-level container
+// (together with all its nested Ordered containers) or a group of dependent top-level containers
+type Unit struct {
+	// Texts of the top-level containers
+	Texts []string
+	// Code locations (file:line) of the top-level containers
+	Locations []string
+	Labels    []string
+	Specs     int
+	// Expected duration based on the historical data
+	Duration time.Duration
+	// Estimated is true if there is no historical data for the unit
+	Estimated bool
+}
+
+// Shard is a set of units assigned to a single CI job
+type Shard struct {
+	Index    int
+	Units    []Unit
+	Duration time.Duration
+}
+
+// FocusFiles returns values for Ginkgo --focus-file flags selecting the specs of the shard
+func (s *Shard) FocusFiles() []string {
+	var filters []string
+	for _, u := range s.Units {
+		for _, l := range u.Locations {
+			file, line, _ := strings.Cut(l, ":")
+			filters = append(filters, fmt.Sprintf("%s$:%s", regexp.QuoteMeta(file), line))
+		}
+	}
+	return filters
+}
+
+// History holds the spec durations gathered from previous JUnit reports
+type History struct {
+	// one entry per JUnit report, maps spec names (without the leaf node type) to durations
+	reports []map[string]time.Duration
+}
+
+// LoadHistory reads all JUnit reports (*.xml) in the directory
+func LoadHistory(dir string) (*History, error) {
+	h := &History{}
+	files, err := filepath.Glob(filepath.Join(dir, "*.xml"))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JUnit report %s: %+v", f, err)
+		}
+		if err := h.AddJUnitReport(data); err != nil {
+			return nil, fmt.Errorf("failed to parse JUnit report %s: %+v", f, err)
+		}
+	}
+	return h, nil
+}
+
+// AddJUnitReport adds spec durations from a JUnit report generated by Ginkgo
+func (h *History) AddJUnitReport(data []byte) error {
+	suites := reporters.JUnitTestSuites{}
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		return err
+	}
+	durations := map[string]time.Duration{}
+	for _, s := range suites.TestSuites {
+		for _, tc := range s.TestCases {
+			if tc.Skipped != nil || tc.Status == types.SpecStateSkipped.String() || tc.Status == types.SpecStatePending.String() {
+				continue
+			}
+			durations[stripLeafNodeType(tc.Name)] += time.Duration(tc.Time * float64(time.Second))
+		}
+	}
+	h.reports = append(h.reports, durations)
+	return nil
+}
+
+// ContainerDuration returns the average duration of all specs within the top-level container across the reports
+func (h *History) ContainerDuration(text string) (time.Duration, bool) {
+	var total time.Duration
+	var found int
+	for _, r := range h.reports {
+		var d time.Duration
+		var matched bool
+		for name, duration := range r {
+			if name == text || strings.HasPrefix(name, text+" ") {
+				d += duration
+				matched = true
+			}
+		}
+		if matched {
+			total += d
+			found++
+		}
+	}
+	if found == 0 {
+		return 0, false
+	}
+	return total / time.Duration(found), true
+}
+
+// stripLeafNodeType turns "[It] [suite text] spec text" into "[suite text] spec text"
+func stripLeafNodeType(name string) string {
+	if strings.HasPrefix(name, "[") {
+		if _, rest, found := strings.Cut(name, "] "); found {
+			return rest
+		}
+	}
+	return name
+}
+
+// LoadDryRunReport reads the Ginkgo JSON report produced with --dry-run
+func LoadDryRunReport(r io.Reader) (types.Report, error) {
+	var reports []types.Report
+	if err := json.NewDecoder(r).Decode(&reports); err != nil {
+		return types.Report{}, err
+	}
+	if len(reports) == 0 {
+		return types.Report{}, fmt.Errorf("the report does not contain any suite")
+	}
+	return reports[0], nil
+}
+
+// UnitsFromReport groups specs selected to run by their top-level containers and assigns the expected duration to them.
+// Code locations are made relative to the baseDir.
+func UnitsFromReport(report types.Report, history *History, baseDir string) []Unit {
+	byLocation := map[string]*Unit{}
+	var order []string
+	for _, spec := range report.SpecReports {
+		if spec.LeafNodeType != types.NodeTypeIt || len(spec.ContainerHierarchyTexts) == 0 ||
+			spec.State == types.SpecStateSkipped || spec.State == types.SpecStatePending {
+			continue
+		}
+		loc := spec.ContainerHierarchyLocations[0]
+		file := loc.FileName
+		if rel, err := filepath.Rel(baseDir, file); err == nil {
+			file = rel
+		}
+		key := fmt.Sprintf("%s:%d", filepath.ToSlash(file), loc.LineNumber)
+		u, ok := byLocation[key]
+		if !ok {
+			u = &Unit{Texts: []string{spec.ContainerHierarchyTexts[0]}, Locations: []string{key}, Labels: spec.ContainerHierarchyLabels[0]}
+			byLocation[key] = u
+			order = append(order, key)
+		}
+		u.Specs++
+	}
+
+	var units []Unit
+	for _, key := range order {
+		units = append(units, *byLocation[key])
+	}
+	units = mergeDependentUnits(units)
+
+	// Units without history are estimated using the average spec duration of the known units
+	var knownDuration time.Duration
+	var knownSpecs int
+	for i := range units {
+		var d time.Duration
+		var found bool
+		for _, t := range unique(units[i].Texts) {
+			if td, ok := history.ContainerDuration(t); ok {
+				d += td
+				found = true
+			}
+		}
+		if found {
+			units[i].Duration = d
+			knownDuration += d
+			knownSpecs += units[i].Specs
+		} else {
+			units[i].Estimated = true
+		}
+	}
+	specDuration := defaultSpecDuration
+	if knownSpecs > 0 {
+		specDuration = knownDuration / time.Duration(knownSpecs)
+	}
+	for i := range units {
+		if units[i].Estimated {
+			units[i].Duration = specDuration * time.Duration(units[i].Specs)
+		}
+	}
+	return units
+}
+
+// mergeDependentUnits merges units with labels matching the same dependentLabelPrefixes into a single unit
+func mergeDependentUnits(units []Unit) []Unit {
+	var merged []Unit
+	groups := map[string]int{}
+	for _, u := range units {
+		prefix := dependentPrefix(u.Labels)
+		if prefix == "" {
+			merged = append(merged, u)
+			continue
+		}
+		idx, ok := groups[prefix]
+		if !ok {
+			groups[prefix] = len(merged)
+			merged = append(merged, u)
+			continue
+		}
+		m := &merged[idx]
+		m.Texts = append(m.Texts, u.Texts...)
+		m.Locations = append(m.Locations, u.Locations...)
+		m.Labels = unique(append(m.Labels, u.Labels...))
+		m.Specs += u.Specs
+	}
+	return merged
+}
+
+func dependentPrefix(labels []string) string {
+	for _, l := range labels {
+		for _, p := range dependentLabelPrefixes {
+			if strings.HasPrefix(l, p) {
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+// Plan distributes the units across count shards so that the expected durations of the shards are balanced
+// (longest processing time first). The result is deterministic for the same input.
+func Plan(units []Unit, count int) ([]Shard, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("shard count has to be a positive number, got %d", count)
+	}
+	sorted := append([]Unit{}, units...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Duration != sorted[j].Duration {
+			return sorted[i].Duration > sorted[j].Duration
+		}
+		return sorted[i].Locations[0] < sorted[j].Locations[0]
+	})
+
+	shards := make([]Shard, count)
+	for i := range shards {
+		shards[i].Index = i
+	}
+	for _, u := range sorted {
+		lightest := 0
+		for i := range shards {
+			if shards[i].Duration < shards[lightest].Duration {
+				lightest = i
+			}
+		}
+		shards[lightest].Units = append(shards[lightest].Units, u)
+		shards[lightest].Duration += u.Duration
+	}
+	return shards, nil
+}
+
+// PrintPlan writes a human readable description of the shards
+func PrintPlan(w io.Writer, shards []Shard) {
+	for _, s := range shards {
+		fmt.Fprintf(w, "shard %d (expected duration %s):\n", s.Index, s.Duration.Round(time.Second))
+		for _, u := range s.Units {
+			estimated := ""
+			if u.Estimated {
+				estimated = ", estimated"
+			}
+			fmt.Fprintf(w, "  - %s (%d specs, %s%s)\n", strings.Join(u.Texts, " + "), u.Specs, u.Duration.Round(time.Second), estimated)
+		}
+	}
+}
+
+func unique(s []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, v := range s {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,92 @@
+package sharding
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const junitReport = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="4">
+  <testsuite name="Red Hat App Studio E2E tests" tests="4">
+    <testcase name="[It] [build-service-suite Build] creates a component [build]" classname="Red Hat App Studio E2E tests" status="passed" time="300"></testcase>
+    <testcase name="[It] [build-service-suite Build] triggers a pipeline [build]" classname="Red Hat App Studio E2E tests" status="failed" time="300"></testcase>
+    <testcase name="[It] [release-service-suite Release] releases [release-service]" classname="Red Hat App Studio E2E tests" status="passed" time="60"></testcase>
+    <testcase name="[It] [ec-suite EC] verifies [ec]" classname="Red Hat App Studio E2E tests" status="skipped" time="0">
+      <skipped message="skipped"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>`
+
+func spec(text string, line int, labels []string, leaf string) types.SpecReport {
+	return types.SpecReport{
+		ContainerHierarchyTexts:     []string{text},
+		ContainerHierarchyLocations: []types.CodeLocation{{FileName: "/repo/tests/suite.go", LineNumber: line}},
+		ContainerHierarchyLabels:    [][]string{labels},
+		LeafNodeType:                types.NodeTypeIt,
+		LeafNodeText:                leaf,
+		State:                       types.SpecStatePassed,
+	}
+}
+
+func testReport() types.Report {
+	skipped := spec("[ec-suite EC]", 40, []string{"ec"}, "verifies")
+	skipped.State = types.SpecStateSkipped
+	return types.Report{SpecReports: types.SpecReports{
+		spec("[build-service-suite Build]", 10, []string{"build"}, "creates a component"),
+		spec("[build-service-suite Build]", 10, []string{"build"}, "triggers a pipeline"),
+		spec("[release-service-suite Release]", 20, []string{"release-service"}, "releases"),
+		spec("[upgrade-suite Create]", 30, []string{"upgrade-create"}, "creates"),
+		spec("[upgrade-suite Verify]", 31, []string{"upgrade-verify"}, "verifies"),
+		spec("[spi-suite]", 35, []string{"spi-suite"}, "new spec"),
+		skipped,
+	}}
+}
+
+func TestUnitsFromReport(t *testing.T) {
+	history := &History{}
+	assert.NoError(t, history.AddJUnitReport([]byte(junitReport)))
+
+	units := UnitsFromReport(testReport(), history, "/repo")
+
+	assert.Len(t, units, 4)
+	assert.Equal(t, Unit{Texts: []string{"[build-service-suite Build]"}, Locations: []string{"tests/suite.go:10"}, Labels: []string{"build"}, Specs: 2, Duration: 10 * time.Minute}, units[0])
+	assert.Equal(t, time.Minute, units[1].Duration)
+	// upgrade suites depend on each other
+	assert.Equal(t, []string{"tests/suite.go:30", "tests/suite.go:31"}, units[2].Locations)
+	assert.Equal(t, 2, units[2].Specs)
+	// estimated using the average spec duration of the suites with history (11 minutes / 3 specs)
+	assert.True(t, units[2].Estimated)
+	assert.Equal(t, 2*(11*time.Minute/3), units[2].Duration)
+	assert.True(t, units[3].Estimated)
+}
+
+func TestPlan(t *testing.T) {
+	units := []Unit{
+		{Texts: []string{"a"}, Locations: []string{"tests/a.go:1"}, Duration: 10 * time.Minute},
+		{Texts: []string{"b"}, Locations: []string{"tests/b.go:1"}, Duration: 6 * time.Minute},
+		{Texts: []string{"c"}, Locations: []string{"tests/c.go:1"}, Duration: 5 * time.Minute},
+		{Texts: []string{"d"}, Locations: []string{"tests/d.go:1"}, Duration: 5 * time.Minute},
+	}
+
+	shards, err := Plan(units, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, shards[0].Duration)
+	assert.Equal(t, 11*time.Minute, shards[1].Duration)
+	assert.Equal(t, []string{`tests/a\.go$:1`, `tests/d\.go$:1`}, shards[0].FocusFiles())
+	assert.Equal(t, []string{`tests/b\.go$:1`, `tests/c\.go$:1`}, shards[1].FocusFiles())
+
+	_, err = Plan(units, 0)
+	assert.Error(t, err)
+}
+
+func TestPlanWithMoreShardsThanUnits(t *testing.T) {
+	shards, err := Plan([]Unit{{Texts: []string{"a"}, Locations: []string{"tests/a.go:1"}, Duration: time.Minute}}, 3)
+
+	assert.NoError(t, err)
+	assert.Len(t, shards, 3)
+	assert.Empty(t, shards[2].FocusFiles())
+}
@@ -0,0 +1,343 @@
+package testselection
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/magefile/mage/sh"
+)
+
+const (
+	ModulePath = "github.com/konflux-ci/e2e-tests"
+
+	testsDir = "tests"
+)
+
+var (
+	// Changes in these paths may affect any suite, so the full suite has to be executed.
+	// The clients are reachable by every suite through the framework, so they are shared as well.
+	sharedPaths = []string{"pkg/framework/", "pkg/clients/", "pkg/utils/", "pkg/constants/", "pkg/config/", "cmd/", "magefiles/", "go.mod", "go.sum", "Makefile", "Dockerfile"}
+	// Changes in these paths do not affect any suite
+	ignoredPaths = []string{"docs/", "tests/load-tests/", ".github/", ".vscode/"}
+	// Labels of suites which are not executed as part of the e2e suite
+	ignoredLabelPrefixes = []string{"upgrade-"}
+)
+
+// Suite is a Go package containing Ginkgo specs
+type Suite struct {
+	// Package import path
+	Package string
+	// Labels selecting the suite, taken from the framework describe nodes of the package
+	Labels []string
+	// Import paths of all packages the suite depends on (within this module)
+	Deps []string
+}
+
+// Selection is a result of the test impact analysis
+type Selection struct {
+	// Full is true if the full e2e suite has to be executed
+	Full bool
+	// Labels of the affected suites
+	Labels []string
+	// Reasoning behind the selection, one entry per changed file
+	Reasons []string
+}
+
+// LabelFilter returns the Ginkgo label filter for the selection, defaultFilter is used when the full suite has to be executed.
+// The selected labels are combined with defaultFilter, so suites excluded by default stay excluded.
+// Empty string is returned in case no suite is affected, which never happens when a Go file was changed.
+func (s *Selection) LabelFilter(defaultFilter string) string {
+	if s.Full {
+		return defaultFilter
+	}
+	if len(s.Labels) == 0 {
+		return ""
+	}
+	selected := strings.Join(s.Labels, " || ")
+	if defaultFilter == "" {
+		return selected
+	}
+	return fmt.Sprintf("(%s) && (%s)", defaultFilter, selected)
+}
+
+func (s *Selection) Print(w io.Writer) {
+	fmt.Fprintln(w, "test selection based on changed files:")
+	for _, r := range s.Reasons {
+		fmt.Fprintf(w, "  - %s\n", r)
+	}
+	switch {
+	case s.Full:
+		fmt.Fprintln(w, "=> running the full suite")
+	case len(s.Labels) == 0:
+		fmt.Fprintln(w, "=> no suite is affected")
+	default:
+		fmt.Fprintf(w, "=> running suites: %s\n", strings.Join(s.Labels, ", "))
+	}
+}
+
+// Analyze maps changed files (relative to the repository root) to the affected suites
+func Analyze(changedFiles []string, suites []Suite) *Selection {
+	selection := &Selection{}
+	labels := map[string]bool{}
+
+	for _, f := range changedFiles {
+		f = filepath.ToSlash(f)
+
+		if p, ok := matchPrefix(f, ignoredPaths); ok {
+			selection.Reasons = append(selection.Reasons, fmt.Sprintf("%s: ignored (%s)", f, p))
+			continue
+		}
+		if p, ok := matchPrefix(f, sharedPaths); ok {
+			selection.Full = true
+			selection.Reasons = append(selection.Reasons, fmt.Sprintf("%s: shared code (%s) changed", f, p))
+			continue
+		}
+		if strings.HasSuffix(f, ".md") || path.Base(f) == "OWNERS" {
+			selection.Reasons = append(selection.Reasons, fmt.Sprintf("%s: ignored (documentation)", f))
+			continue
+		}
+
+		pkg := packageOf(f)
+		var affected []string
+		for _, s := range suites {
+			if s.Package == pkg || contains(s.Deps, pkg) || (isTestsFile(f) && strings.HasPrefix(pkg, s.Package+"/")) {
+				affected = append(affected, s.Labels...)
+			}
+		}
+		isGoFile := strings.HasSuffix(f, ".go")
+		if len(affected) == 0 {
+			if isGoFile || !isTestsFile(f) {
+				// we cannot tell which suites use the file (e.g. scripts, templates, packages not found in the import graph)
+				selection.Full = true
+				selection.Reasons = append(selection.Reasons, fmt.Sprintf("%s: unable to map the file to a suite", f))
+			} else {
+				selection.Reasons = append(selection.Reasons, fmt.Sprintf("%s: package %s is not used by any suite", f, pkg))
+			}
+			continue
+		}
+		if affected = filterLabels(affected); len(affected) == 0 {
+			if isGoFile {
+				// a Go change always runs some e2e suites
+				selection.Full = true
+				selection.Reasons = append(selection.Reasons, fmt.Sprintf("%s: package %s affects only suites which are not part of the e2e run, running the full suite", f, pkg))
+			} else {
+				selection.Reasons = append(selection.Reasons, fmt.Sprintf("%s: package %s affects only suites which are not part of the e2e run", f, pkg))
+			}
+			continue
+		}
+		selection.Reasons = append(selection.Reasons, fmt.Sprintf("%s: package %s affects suites %s", f, pkg, strings.Join(affected, ", ")))
+		for _, l := range affected {
+			labels[l] = true
+		}
+	}
+
+	for l := range labels {
+		selection.Labels = append(selection.Labels, l)
+	}
+	sort.Strings(selection.Labels)
+	return selection
+}
+
+// DiscoverSuites finds all packages under the tests directory containing framework describe nodes
+// and resolves their dependencies with "go list"
+func DiscoverSuites(repoRoot string) ([]Suite, error) {
+	labelsByPackage := map[string][]string{}
+	err := filepath.WalkDir(filepath.Join(repoRoot, testsDir), func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(repoRoot, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if _, ok := matchPrefix(rel+"/", ignoredPaths); ok {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			return nil
+		}
+		found, suiteLabels, err := frameworkDescribeLabels(p)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return nil
+		}
+		pkg := packageOf(rel)
+		if len(suiteLabels) > 0 {
+			// by convention, the first label of the framework describe node identifies the suite
+			labelsByPackage[pkg] = append(labelsByPackage[pkg], suiteLabels[0])
+		} else {
+			// e.g. rhtap-demo suite uses labels only in the nested nodes, its label matches the directory name
+			labelsByPackage[pkg] = append(labelsByPackage[pkg], path.Base(pkg))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error when discovering test suites: %+v", err)
+	}
+
+	var suites []Suite
+	for pkg, labels := range labelsByPackage {
+		out, err := sh.Output("go", "list", "-deps", "-f", "{{.ImportPath}}{{range .Imports}} {{.}}{{end}}", pkg)
+		if err != nil {
+			return nil, fmt.Errorf("error when listing dependencies of package %s: %+v", pkg, err)
+		}
+		imports := map[string][]string{}
+		for _, line := range strings.Split(out, "\n") {
+			if fields := strings.Fields(line); len(fields) > 0 {
+				imports[fields[0]] = fields[1:]
+			}
+		}
+		suites = append(suites, Suite{Package: pkg, Labels: unique(labels), Deps: moduleDeps(pkg, imports)})
+	}
+	sort.Slice(suites, func(i, j int) bool { return suites[i].Package < suites[j].Package })
+	return suites, nil
+}
+
+// moduleDeps returns the packages of this module the package imports (directly or transitively) according to the import graph
+func moduleDeps(pkg string, imports map[string][]string) []string {
+	visited := map[string]bool{pkg: true}
+	queue := []string{pkg}
+	var deps []string
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current != pkg {
+			deps = append(deps, current)
+		}
+		for _, imported := range imports[current] {
+			if strings.HasPrefix(imported, ModulePath+"/") && !visited[imported] {
+				visited[imported] = true
+				queue = append(queue, imported)
+			}
+		}
+	}
+	sort.Strings(deps)
+	return deps
+}
+
+// frameworkDescribeLabels looks for the framework.*SuiteDescribe node in the file and returns its labels
+func frameworkDescribeLabels(file string) (found bool, labels []string, err error) {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, 0)
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to parse file %s: %+v", file, err)
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		ce, ok := n.(*ast.CallExpr)
+		if !ok || found {
+			return !found
+		}
+		sel, ok := ce.Fun.(*ast.SelectorExpr)
+		if !ok || !strings.HasSuffix(sel.Sel.Name, "SuiteDescribe") {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); !ok || x.Name != "framework" {
+			return true
+		}
+		found = true
+		for _, arg := range ce.Args {
+			labelCall, ok := arg.(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			if id, ok := labelCall.Fun.(*ast.Ident); !ok || id.Name != "Label" {
+				continue
+			}
+			for _, l := range labelCall.Args {
+				if lit, ok := l.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					if unquoted, err := strconv.Unquote(lit.Value); err == nil {
+						labels = append(labels, unquoted)
+					}
+				}
+			}
+		}
+		return false
+	})
+	return found, labels, nil
+}
+
+// ChangedFiles returns files changed in the current branch compared to baseRef
+func ChangedFiles(baseRef string) ([]string, error) {
+	mergeBase, err := sh.Output("git", "merge-base", "HEAD", baseRef)
+	if err != nil {
+		return nil, fmt.Errorf("error when getting merge base of HEAD and %s: %+v", baseRef, err)
+	}
+	out, err := sh.Output("git", "diff", "--name-only", mergeBase)
+	if err != nil {
+		return nil, fmt.Errorf("error when listing changed files: %+v", err)
+	}
+	var files []string
+	for _, f := range strings.Split(out, "\n") {
+		if f = strings.TrimSpace(f); f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+func packageOf(file string) string {
+	dir := path.Dir(file)
+	if dir == "." {
+		return ModulePath
+	}
+	return ModulePath + "/" + dir
+}
+
+func isTestsFile(file string) bool {
+	return strings.HasPrefix(file, testsDir+"/")
+}
+
+func matchPrefix(file string, prefixes []string) (string, bool) {
+	for _, p := range prefixes {
+		if file == p || strings.HasPrefix(file, p) {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+func filterLabels(labels []string) []string {
+	var filtered []string
+	for _, l := range unique(labels) {
+		if _, ignored := matchPrefix(l, ignoredLabelPrefixes); !ignored {
+			filtered = append(filtered, l)
+		}
+	}
+	return filtered
+}
+
+func unique(s []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, v := range s {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func contains(s []string, v string) bool {
+	for _, item := range s {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package testselection
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testSuites = []Suite{
+	{
+		Package: ModulePath + "/tests/build",
+		Labels:  []string{"build", "jvm-build"},
+		Deps:    []string{ModulePath + "/pkg/clients/has", ModulePath + "/pkg/framework", ModulePath + "/pkg/testspecs"},
+	},
+	{
+		Package: ModulePath + "/tests/release/pipelines",
+		Labels:  []string{"release-pipelines"},
+		Deps:    []string{ModulePath + "/tests/release", ModulePath + "/pkg/clients/release", ModulePath + "/pkg/framework"},
+	},
+	{
+		Package: ModulePath + "/tests/rhtap-demo",
+		Labels:  []string{"rhtap-demo"},
+		Deps:    []string{ModulePath + "/pkg/clients/has", ModulePath + "/pkg/framework", ModulePath + "/pkg/testspecs"},
+	},
+	{
+		Package: ModulePath + "/tests/upgrade",
+		Labels:  []string{"upgrade-create", "upgrade-verify"},
+		Deps:    []string{ModulePath + "/tests/upgrade/create"},
+	},
+}
+
+func TestAnalyzeSelectsSuitesImportingChangedPackages(t *testing.T) {
+	s := Analyze([]string{"pkg/testspecs/specs.go", "tests/release/releaseLib.go", "docs/Installation.md"}, testSuites)
+
+	assert.False(t, s.Full)
+	assert.Equal(t, []string{"build", "jvm-build", "release-pipelines", "rhtap-demo"}, s.Labels)
+	assert.Equal(t, "(!release-pipelines) && (build || jvm-build || release-pipelines || rhtap-demo)", s.LabelFilter("!release-pipelines"))
+	assert.Equal(t, "build || jvm-build || release-pipelines || rhtap-demo", s.LabelFilter(""))
+	assert.Len(t, s.Reasons, 3)
+}
+
+func TestAnalyzeMapsNonGoFilesInTestsToTheSuite(t *testing.T) {
+	s := Analyze([]string{"tests/rhtap-demo/config/default.yaml"}, testSuites)
+
+	assert.False(t, s.Full)
+	assert.Equal(t, []string{"rhtap-demo"}, s.Labels)
+}
+
+func TestAnalyzeFallsBackToFullSuite(t *testing.T) {
+	for _, f := range []string{"pkg/framework/framework.go", "pkg/clients/tekton/pipelineruns.go", "pkg/utils/util.go", "magefiles/magefile.go", "go.mod", "scripts/some-script.sh", "pkg/sandbox/sandbox.go", "tests/upgrade/create/createUsers.go"} {
+		s := Analyze([]string{"tests/build/build.go", f}, testSuites)
+		assert.True(t, s.Full, f)
+		assert.Equal(t, "default", s.LabelFilter("default"), f)
+	}
+}
+
+func TestAnalyzeIgnoresNonGoFilesOfSuitesOutsideOfE2ERun(t *testing.T) {
+	s := Analyze([]string{"tests/upgrade/create/README.md", "tests/upgrade/create/workload.yaml", "tests/build/README.md"}, testSuites)
+
+	assert.False(t, s.Full)
+	assert.Empty(t, s.Labels)
+	assert.Equal(t, "", s.LabelFilter("default"))
+}
+
+func TestModuleDepsFollowFramework(t *testing.T) {
+	imports := map[string][]string{
+		ModulePath + "/tests/build":         {ModulePath + "/pkg/framework", "fmt"},
+		ModulePath + "/pkg/framework":       {ModulePath + "/pkg/clients/has", ModulePath + "/pkg/clients/tekton"},
+		ModulePath + "/pkg/clients/has":     {ModulePath + "/pkg/utils"},
+		ModulePath + "/pkg/clients/tekton":  {ModulePath + "/pkg/utils"},
+		ModulePath + "/pkg/clients/release": {ModulePath + "/pkg/utils"},
+	}
+
+	assert.Equal(t, []string{ModulePath + "/pkg/clients/has", ModulePath + "/pkg/clients/tekton", ModulePath + "/pkg/framework", ModulePath + "/pkg/utils"}, moduleDeps(ModulePath+"/tests/build", imports))
+}
@@ -0,0 +1,213 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	DefaultMatrixOrg    = "redhat-appstudio"
+	DefaultMatrixBranch = "main"
+)
+
+var matrixEntryNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// Revision of the infra-deployments repository
+type Revision struct {
+	Org    string `json:"org,omitempty"`
+	Branch string `json:"branch,omitempty"`
+}
+
+func (r Revision) String() string {
+	return r.Org + "/" + r.Branch
+}
+
+// MatrixEntry is a single upgrade path: Konflux is installed from the From revision
+// and upgraded to the To revision of infra-deployments
+type MatrixEntry struct {
+	Name string   `json:"name"`
+	From Revision `json:"from"`
+	To   Revision `json:"to"`
+}
+
+// Matrix is the content of the file referenced by UPGRADE_MATRIX_FILE env var
+type Matrix struct {
+	Entries []MatrixEntry `json:"entries"`
+}
+
+// LoadMatrix reads the matrix file, fills in default revisions and validates it
+func LoadMatrix(file string) (*Matrix, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upgrade matrix file %s: %+v", file, err)
+	}
+	m := &Matrix{}
+	if err := yaml.UnmarshalStrict(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse upgrade matrix file %s: %+v", file, err)
+	}
+	for i := range m.Entries {
+		e := &m.Entries[i]
+		if e.From.Org == "" {
+			e.From.Org = DefaultMatrixOrg
+		}
+		if e.From.Branch == "" {
+			e.From.Branch = DefaultMatrixBranch
+		}
+		if e.To.Org == "" {
+			e.To.Org = DefaultMatrixOrg
+		}
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid upgrade matrix file %s: %+v", file, err)
+	}
+	return m, nil
+}
+
+func (m *Matrix) Validate() error {
+	if len(m.Entries) == 0 {
+		return fmt.Errorf("no entries defined")
+	}
+	names := map[string]bool{}
+	for i, e := range m.Entries {
+		if !matrixEntryNameRegexp.MatchString(e.Name) {
+			return fmt.Errorf("entry #%d: name %q is not valid", i, e.Name)
+		}
+		if names[e.Name] {
+			return fmt.Errorf("entry %s: duplicate name", e.Name)
+		}
+		names[e.Name] = true
+		if e.To.Branch == "" {
+			return fmt.Errorf("entry %s: branch to upgrade to has to be defined", e.Name)
+		}
+		if e.From == e.To {
+			return fmt.Errorf("entry %s: revisions to upgrade from and to are the same", e.Name)
+		}
+	}
+	return nil
+}
+
+// Names returns names of the entries in the order of the matrix file
+func (m *Matrix) Names() []string {
+	names := make([]string, 0, len(m.Entries))
+	for _, e := range m.Entries {
+		names = append(names, e.Name)
+	}
+	return names
+}
+
+// Select returns entries with the given comma separated names in the order of the matrix file, all entries are
+// returned for an empty selection
+func (m *Matrix) Select(selection string) ([]MatrixEntry, error) {
+	if strings.TrimSpace(selection) == "" {
+		return m.Entries, nil
+	}
+	byName := map[string]bool{}
+	for _, e := range m.Entries {
+		byName[e.Name] = true
+	}
+	selected := map[string]bool{}
+	for _, name := range strings.Split(selection, ",") {
+		name = strings.TrimSpace(name)
+		if !byName[name] {
+			return nil, fmt.Errorf("entry %s not found in the upgrade matrix", name)
+		}
+		selected[name] = true
+	}
+	var entries []MatrixEntry
+	for _, e := range m.Entries {
+		if selected[e.Name] {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// PhaseResult is the outcome of a single phase (install, create workload, upgrade, ...) of a matrix entry
+type PhaseResult struct {
+	Phase    string        `json:"phase"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// EntryResult records the phases run for a matrix entry
+type EntryResult struct {
+	MatrixEntry
+	Phases []PhaseResult `json:"phases"`
+	Passed bool          `json:"passed"`
+}
+
+func NewEntryResult(e MatrixEntry) *EntryResult {
+	return &EntryResult{MatrixEntry: e, Passed: true}
+}
+
+// Run runs the phase and records its result, the entry is marked as failed when the phase returns an error
+func (r *EntryResult) Run(phase string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	result := PhaseResult{Phase: phase, Duration: time.Since(start).Round(time.Second)}
+	if err != nil {
+		result.Error = err.Error()
+		r.Passed = false
+	}
+	r.Phases = append(r.Phases, result)
+	return err
+}
+
+// FailedPhase returns the first failed phase of the entry, or an empty string
+func (r *EntryResult) FailedPhase() string {
+	for _, p := range r.Phases {
+		if p.Error != "" {
+			return p.Phase
+		}
+	}
+	return ""
+}
+
+// MatrixReport contains the results of all matrix entries that were run
+type MatrixReport struct {
+	Entries []*EntryResult `json:"entries"`
+}
+
+// Failed returns names of the entries that did not pass
+func (r *MatrixReport) Failed() []string {
+	var failed []string
+	for _, e := range r.Entries {
+		if !e.Passed {
+			failed = append(failed, e.Name)
+		}
+	}
+	return failed
+}
+
+func (r *MatrixReport) PrintTable(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ENTRY\tFROM\tTO\tRESULT\tFAILED PHASE")
+	for _, e := range r.Entries {
+		result := "passed"
+		if !e.Passed {
+			result = "failed"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", e.Name, e.From, e.To, result, e.FailedPhase())
+	}
+	return w.Flush()
+}
+
+// Save writes the report in JSON format to the file
+func (r *MatrixReport) Save(file string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal upgrade matrix report: %+v", err)
+	}
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		return fmt.Errorf("failed to write upgrade matrix report %s: %+v", file, err)
+	}
+	return nil
+}
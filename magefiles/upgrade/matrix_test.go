@@ -0,0 +1,72 @@
+package upgrade
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeMatrix(t *testing.T, content string) string {
+	file := filepath.Join(t.TempDir(), "matrix.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte(content), 0600))
+	return file
+}
+
+func TestLoadMatrix(t *testing.T) {
+	m, err := LoadMatrix(writeMatrix(t, `
+entries:
+  - name: main-to-change
+    to:
+      org: redhat-appstudio-qe
+      branch: change
+  - name: release-to-main
+    from:
+      branch: release
+    to:
+      branch: main
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []MatrixEntry{
+		{Name: "main-to-change", From: Revision{Org: "redhat-appstudio", Branch: "main"}, To: Revision{Org: "redhat-appstudio-qe", Branch: "change"}},
+		{Name: "release-to-main", From: Revision{Org: "redhat-appstudio", Branch: "release"}, To: Revision{Org: "redhat-appstudio", Branch: "main"}},
+	}, m.Entries)
+
+	selected, err := m.Select("release-to-main")
+	assert.NoError(t, err)
+	assert.Equal(t, []MatrixEntry{m.Entries[1]}, selected)
+	selected, err = m.Select("")
+	assert.NoError(t, err)
+	assert.Len(t, selected, 2)
+	_, err = m.Select("unknown")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"main-to-change", "release-to-main"}, m.Names())
+}
+
+func TestLoadMatrixInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"no entries":     `entries: []`,
+		"missing branch": "entries:\n  - name: a\n    to:\n      org: redhat-appstudio-qe\n",
+		"same revisions": "entries:\n  - name: a\n    to:\n      branch: main\n",
+		"duplicate name": "entries:\n  - name: a\n    to:\n      branch: x\n  - name: a\n    to:\n      branch: y\n",
+		"unknown field":  "entries:\n  - name: a\n    target:\n      branch: x\n",
+		"invalid name":   "entries:\n  - name: a b\n    to:\n      branch: x\n",
+	} {
+		_, err := LoadMatrix(writeMatrix(t, content))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestEntryResult(t *testing.T) {
+	r := NewEntryResult(MatrixEntry{Name: "a"})
+	assert.NoError(t, r.Run("install", func() error { return nil }))
+	assert.Error(t, r.Run("upgrade", func() error { return errors.New("merge conflict") }))
+	assert.False(t, r.Passed)
+	assert.Equal(t, "upgrade", r.FailedPhase())
+	assert.Equal(t, "merge conflict", r.Phases[1].Error)
+
+	report := &MatrixReport{Entries: []*EntryResult{r, NewEntryResult(MatrixEntry{Name: "b"})}}
+	assert.Equal(t, []string{"a"}, report.Failed())
+}
@@ -0,0 +1,310 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// DefaultOperatorStallBudget is how long a ClusterOperator can be progressing (or degraded) during an upgrade
+// before it is considered to be stalled
+const DefaultOperatorStallBudget = 30 * time.Minute
+
+var machineConfigPoolResource = schema.GroupVersionResource{Group: "machineconfiguration.openshift.io", Version: "v1", Resource: "machineconfigpools"}
+
+// OperatorProgress is the state of a ClusterOperator during an upgrade
+type OperatorProgress struct {
+	Name        string
+	Version     string
+	AtTarget    bool
+	Available   bool
+	Progressing bool
+	Degraded    bool
+	Message     string
+	// Duration is how long the operator has been progressing or degraded without reaching the target version
+	Duration time.Duration
+	Stalled  bool
+}
+
+// PoolProgress is the state of a MachineConfigPool during an upgrade
+type PoolProgress struct {
+	Name             string
+	MachineCount     int64
+	UpdatedMachines  int64
+	DegradedMachines int64
+	Updating         bool
+	Degraded         bool
+}
+
+// Progress is a snapshot of the upgrade progress
+type Progress struct {
+	TargetVersion string
+	Operators     []OperatorProgress
+	Pools         []PoolProgress
+}
+
+// ProgressTracker tracks the progress of an OpenShift upgrade and detects ClusterOperators stalled
+// for longer than their budgets
+type ProgressTracker struct {
+	configClient  configv1client.Interface
+	dynamicClient dynamic.Interface
+
+	// DefaultBudget applies to operators without an entry in Budgets
+	DefaultBudget time.Duration
+	// Budgets per operator name, e.g. machine-config usually needs more time because of node reboots
+	Budgets map[string]time.Duration
+
+	now   func() time.Time
+	since map[string]time.Time
+}
+
+// NewProgressTracker creates a tracker, the dynamic client is used for MachineConfigPools and can be nil
+func NewProgressTracker(configClient configv1client.Interface, dynamicClient dynamic.Interface) *ProgressTracker {
+	return &ProgressTracker{
+		configClient:  configClient,
+		dynamicClient: dynamicClient,
+		DefaultBudget: DefaultOperatorStallBudget,
+		Budgets:       map[string]time.Duration{},
+		now:           time.Now,
+		since:         map[string]time.Time{},
+	}
+}
+
+// ParseBudgets parses comma separated operator=duration pairs, e.g. "machine-config=60m,network=40m"
+func ParseBudgets(value string) (map[string]time.Duration, error) {
+	budgets := map[string]time.Duration{}
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, duration, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("%q is not in operator=duration format", pair)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(duration))
+		if err != nil {
+			return nil, fmt.Errorf("invalid budget of operator %s: %+v", name, err)
+		}
+		budgets[strings.TrimSpace(name)] = d
+	}
+	return budgets, nil
+}
+
+func (t *ProgressTracker) budget(operator string) time.Duration {
+	if b, ok := t.Budgets[operator]; ok {
+		return b
+	}
+	return t.DefaultBudget
+}
+
+// Check gets the current state of ClusterOperators and MachineConfigPools
+func (t *ProgressTracker) Check(ctx context.Context) (*Progress, error) {
+	cv, err := t.configClient.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ClusterVersion: %+v", err)
+	}
+	operators, err := t.configClient.ConfigV1().ClusterOperators().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ClusterOperators: %+v", err)
+	}
+
+	now := t.now()
+	p := &Progress{TargetVersion: cv.Status.Desired.Version}
+	for _, co := range operators.Items {
+		op := OperatorProgress{Name: co.Name}
+		for _, v := range co.Status.Versions {
+			if v.Name == "operator" {
+				op.Version = v.Version
+			}
+		}
+		op.AtTarget = op.Version == p.TargetVersion
+		if c := findClusterOperatorStatusCondition(co.Status.Conditions, configv1.OperatorAvailable); c != nil {
+			op.Available = c.Status == configv1.ConditionTrue
+		}
+		if c := findClusterOperatorStatusCondition(co.Status.Conditions, configv1.OperatorProgressing); c != nil && c.Status == configv1.ConditionTrue {
+			op.Progressing, op.Message = true, c.Message
+		}
+		if c := findClusterOperatorStatusCondition(co.Status.Conditions, configv1.OperatorDegraded); c != nil && c.Status == configv1.ConditionTrue {
+			op.Degraded, op.Message = true, c.Message
+		}
+
+		if !op.AtTarget && (op.Progressing || op.Degraded) {
+			since, ok := t.since[co.Name]
+			if !ok {
+				since = now
+				t.since[co.Name] = now
+			}
+			op.Duration = now.Sub(since)
+			op.Stalled = op.Duration > t.budget(co.Name)
+		} else {
+			delete(t.since, co.Name)
+		}
+		p.Operators = append(p.Operators, op)
+	}
+	sort.Slice(p.Operators, func(i, j int) bool { return p.Operators[i].Name < p.Operators[j].Name })
+
+	if t.dynamicClient != nil {
+		pools, err := t.dynamicClient.Resource(machineConfigPoolResource).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list MachineConfigPools: %+v", err)
+		}
+		for _, pool := range pools.Items {
+			p.Pools = append(p.Pools, newPoolProgress(pool))
+		}
+		sort.Slice(p.Pools, func(i, j int) bool { return p.Pools[i].Name < p.Pools[j].Name })
+	}
+	return p, nil
+}
+
+func newPoolProgress(pool unstructured.Unstructured) PoolProgress {
+	pp := PoolProgress{Name: pool.GetName()}
+	pp.MachineCount, _, _ = unstructured.NestedInt64(pool.Object, "status", "machineCount")
+	pp.UpdatedMachines, _, _ = unstructured.NestedInt64(pool.Object, "status", "updatedMachineCount")
+	pp.DegradedMachines, _, _ = unstructured.NestedInt64(pool.Object, "status", "degradedMachineCount")
+	conditions, _, _ := unstructured.NestedSlice(pool.Object, "status", "conditions")
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if !ok || m["status"] != "True" {
+			continue
+		}
+		switch m["type"] {
+		case "Updating":
+			pp.Updating = true
+		case "Degraded":
+			pp.Degraded = true
+		}
+	}
+	return pp
+}
+
+// Percent returns the percentage of ClusterOperators at the target version
+func (p *Progress) Percent() int {
+	if len(p.Operators) == 0 {
+		return 0
+	}
+	atTarget := 0
+	for _, op := range p.Operators {
+		if op.AtTarget {
+			atTarget++
+		}
+	}
+	return atTarget * 100 / len(p.Operators)
+}
+
+// Progressing returns names of the operators which are progressing
+func (p *Progress) Progressing() []string {
+	var names []string
+	for _, op := range p.Operators {
+		if op.Progressing {
+			names = append(names, op.Name)
+		}
+	}
+	return names
+}
+
+// Stalled returns the operators which exceeded their budgets
+func (p *Progress) Stalled() []OperatorProgress {
+	var stalled []OperatorProgress
+	for _, op := range p.Operators {
+		if op.Stalled {
+			stalled = append(stalled, op)
+		}
+	}
+	return stalled
+}
+
+// Summary returns a single line description of the progress
+func (p *Progress) Summary() string {
+	var pools []string
+	for _, pool := range p.Pools {
+		state := fmt.Sprintf("%s %d/%d updated", pool.Name, pool.UpdatedMachines, pool.MachineCount)
+		if pool.Degraded {
+			state += fmt.Sprintf(" (degraded, %d degraded machines)", pool.DegradedMachines)
+		}
+		pools = append(pools, state)
+	}
+	progressing := p.Progressing()
+	if len(progressing) == 0 {
+		progressing = []string{"none"}
+	}
+	return fmt.Sprintf("upgrade to %s: %d%% of cluster operators at target version, progressing: %s, machine config pools: %s",
+		p.TargetVersion, p.Percent(), strings.Join(progressing, ", "), strings.Join(pools, ", "))
+}
+
+// PrintDiagnostics prints the ClusterVersion history and conditions, conditions of operators that are degraded,
+// unavailable or not at the target version, and the state of MachineConfigPools
+func (t *ProgressTracker) PrintDiagnostics(ctx context.Context, out io.Writer) error {
+	cv, err := t.configClient.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get ClusterVersion: %+v", err)
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLUSTER VERSION HISTORY")
+	fmt.Fprintln(w, "STATE\tVERSION\tSTARTED\tCOMPLETED")
+	for _, h := range cv.Status.History {
+		completed := ""
+		if h.CompletionTime != nil {
+			completed = h.CompletionTime.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", h.State, h.Version, h.StartedTime.UTC().Format(time.RFC3339), completed)
+	}
+	fmt.Fprintln(w, "\nCLUSTER VERSION CONDITIONS")
+	printConditions(w, cv.Status.Conditions)
+
+	operators, err := t.configClient.ConfigV1().ClusterOperators().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list ClusterOperators: %+v", err)
+	}
+	sort.Slice(operators.Items, func(i, j int) bool { return operators.Items[i].Name < operators.Items[j].Name })
+	fmt.Fprintln(w, "\nCLUSTER OPERATORS NOT AT TARGET VERSION, DEGRADED OR UNAVAILABLE")
+	for _, co := range operators.Items {
+		version := ""
+		for _, v := range co.Status.Versions {
+			if v.Name == "operator" {
+				version = v.Version
+			}
+		}
+		available := findClusterOperatorStatusCondition(co.Status.Conditions, configv1.OperatorAvailable)
+		degraded := findClusterOperatorStatusCondition(co.Status.Conditions, configv1.OperatorDegraded)
+		if version == cv.Status.Desired.Version && (available == nil || available.Status == configv1.ConditionTrue) &&
+			(degraded == nil || degraded.Status != configv1.ConditionTrue) {
+			continue
+		}
+		fmt.Fprintf(w, "%s (version %s)\n", co.Name, version)
+		printConditions(w, co.Status.Conditions)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if t.dynamicClient != nil {
+		pools, err := t.dynamicClient.Resource(machineConfigPoolResource).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list MachineConfigPools: %+v", err)
+		}
+		fmt.Fprintln(w, "\nMACHINE CONFIG POOLS")
+		fmt.Fprintln(w, "NAME\tMACHINES\tUPDATED\tDEGRADED\tUPDATING")
+		for _, pool := range pools.Items {
+			pp := newPoolProgress(pool)
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%t\n", pp.Name, pp.MachineCount, pp.UpdatedMachines, pp.DegradedMachines, pp.Updating)
+		}
+	}
+	return w.Flush()
+}
+
+func printConditions(w io.Writer, conditions []configv1.ClusterOperatorStatusCondition) {
+	for _, c := range conditions {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", c.Type, c.Status, c.Reason, strings.Join(strings.Fields(c.Message), " "))
+	}
+}
@@ -0,0 +1,146 @@
+package upgrade
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+const targetVersion = "4.16.3"
+
+func clusterVersion() *configv1.ClusterVersion {
+	return &configv1.ClusterVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "version"},
+		Status: configv1.ClusterVersionStatus{
+			Desired: configv1.Release{Version: targetVersion},
+			History: []configv1.UpdateHistory{
+				{State: configv1.PartialUpdate, Version: targetVersion, StartedTime: metav1.NewTime(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))},
+				{State: configv1.CompletedUpdate, Version: "4.15.20", StartedTime: metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))},
+			},
+		},
+	}
+}
+
+func clusterOperator(name, version string, conditions ...configv1.ClusterOperatorStatusCondition) *configv1.ClusterOperator {
+	return &configv1.ClusterOperator{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: configv1.ClusterOperatorStatus{
+			Versions:   []configv1.OperandVersion{{Name: "operator", Version: version}},
+			Conditions: conditions,
+		},
+	}
+}
+
+func condition(t configv1.ClusterStatusConditionType, status configv1.ConditionStatus, message string) configv1.ClusterOperatorStatusCondition {
+	return configv1.ClusterOperatorStatusCondition{Type: t, Status: status, Message: message}
+}
+
+func machineConfigPool(name string, machines, updated int64, updating bool) *unstructured.Unstructured {
+	status := "False"
+	if updating {
+		status = "True"
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "machineconfiguration.openshift.io/v1",
+		"kind":       "MachineConfigPool",
+		"metadata":   map[string]interface{}{"name": name},
+		"status": map[string]interface{}{
+			"machineCount":        machines,
+			"updatedMachineCount": updated,
+			"conditions":          []interface{}{map[string]interface{}{"type": "Updating", "status": status}},
+		},
+	}}
+}
+
+func newFakeTracker(pools ...runtime.Object) (*ProgressTracker, *configfake.Clientset, *time.Time) {
+	configClient := configfake.NewSimpleClientset(
+		clusterVersion(),
+		clusterOperator("authentication", targetVersion, condition(configv1.OperatorAvailable, configv1.ConditionTrue, "")),
+		clusterOperator("machine-config", "4.15.20", condition(configv1.OperatorAvailable, configv1.ConditionTrue, ""),
+			condition(configv1.OperatorProgressing, configv1.ConditionTrue, "Working towards 4.16.3")),
+		clusterOperator("network", "4.15.20", condition(configv1.OperatorAvailable, configv1.ConditionTrue, ""),
+			condition(configv1.OperatorDegraded, configv1.ConditionTrue, "DaemonSet \"ovnkube-node\" rollout is not making progress")),
+		clusterOperator("storage", "4.15.20", condition(configv1.OperatorAvailable, configv1.ConditionTrue, "")),
+	)
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{machineConfigPoolResource: "MachineConfigPoolList"}, pools...)
+
+	now := time.Date(2024, 1, 2, 1, 0, 0, 0, time.UTC)
+	tracker := NewProgressTracker(configClient, dynamicClient)
+	tracker.DefaultBudget = 10 * time.Minute
+	tracker.Budgets = map[string]time.Duration{"machine-config": time.Hour}
+	tracker.now = func() time.Time { return now }
+	return tracker, configClient, &now
+}
+
+func TestProgress(t *testing.T) {
+	tracker, _, _ := newFakeTracker(machineConfigPool("master", 3, 1, true), machineConfigPool("worker", 3, 0, false))
+
+	progress, err := tracker.Check(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 25, progress.Percent())
+	assert.Equal(t, []string{"machine-config"}, progress.Progressing())
+	assert.Equal(t, []PoolProgress{{Name: "master", MachineCount: 3, UpdatedMachines: 1, Updating: true}, {Name: "worker", MachineCount: 3}}, progress.Pools)
+	assert.Equal(t, "upgrade to 4.16.3: 25% of cluster operators at target version, progressing: machine-config, machine config pools: master 1/3 updated, worker 0/3 updated", progress.Summary())
+	assert.Empty(t, progress.Stalled())
+}
+
+func TestStallDetection(t *testing.T) {
+	tracker, configClient, now := newFakeTracker()
+	ctx := context.Background()
+
+	_, err := tracker.Check(ctx)
+	assert.NoError(t, err)
+
+	*now = now.Add(15 * time.Minute)
+	progress, err := tracker.Check(ctx)
+	assert.NoError(t, err)
+	stalled := progress.Stalled()
+	assert.Len(t, stalled, 1, "machine-config is still within its budget")
+	assert.Equal(t, "network", stalled[0].Name)
+	assert.Equal(t, 15*time.Minute, stalled[0].Duration)
+
+	// network recovers and reaches the target version
+	network := clusterOperator("network", targetVersion, condition(configv1.OperatorAvailable, configv1.ConditionTrue, ""))
+	_, err = configClient.ConfigV1().ClusterOperators().Update(ctx, network, metav1.UpdateOptions{})
+	assert.NoError(t, err)
+	*now = now.Add(50 * time.Minute)
+	progress, err = tracker.Check(ctx)
+	assert.NoError(t, err)
+	stalled = progress.Stalled()
+	assert.Len(t, stalled, 1)
+	assert.Equal(t, "machine-config", stalled[0].Name)
+}
+
+func TestPrintDiagnostics(t *testing.T) {
+	tracker, _, _ := newFakeTracker(machineConfigPool("master", 3, 1, true))
+	out := &bytes.Buffer{}
+
+	assert.NoError(t, tracker.PrintDiagnostics(context.Background(), out))
+	assert.Contains(t, out.String(), "Partial    4.16.3   2024-01-02T00:00:00Z")
+	assert.Contains(t, out.String(), "DaemonSet \"ovnkube-node\" rollout is not making progress")
+	assert.Contains(t, out.String(), "storage (version 4.15.20)")
+	assert.NotContains(t, out.String(), "authentication")
+	assert.Contains(t, out.String(), "master  3         1")
+}
+
+func TestParseBudgets(t *testing.T) {
+	budgets, err := ParseBudgets("machine-config=60m, network=40m")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{"machine-config": time.Hour, "network": 40 * time.Minute}, budgets)
+
+	_, err = ParseBudgets("machine-config")
+	assert.Error(t, err)
+	_, err = ParseBudgets("machine-config=soon")
+	assert.Error(t, err)
+}
@@ -1,9 +1,11 @@
 package upgrade
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -18,6 +20,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
 	kubeclient "k8s.io/client-go/kubernetes"
 	podUtils "k8s.io/kubectl/pkg/util/podutils"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
@@ -32,8 +35,8 @@ const spiVaultNamespaceName = "spi-vault"
 const spiVaultPodName = "vault-0"
 
 type statusHelper struct {
-	configClientset *configv1client.Clientset
-	kubeClientSet   *kubeclient.Clientset
+	configClientset configv1client.Interface
+	kubeClientSet   kubeclient.Interface
 
 	clusterVersion  *configv1.ClusterVersion
 	currentProgress string
@@ -60,7 +63,7 @@ func (s *statusHelper) update() error {
 	return nil
 }
 
-func newStatusHelper(kcs *kubeclient.Clientset, ccs *configv1client.Clientset) (*statusHelper, error) {
+func newStatusHelper(kcs kubeclient.Interface, ccs configv1client.Interface) (*statusHelper, error) {
 	var initialVersion string
 	clusterVersion, err := ccs.ConfigV1().ClusterVersions().Get(context.TODO(), "version", metav1.GetOptions{})
 	if err != nil {
@@ -164,6 +167,11 @@ func PerformUpgrade() error {
 		return fmt.Errorf("error when creating client: %+v", err)
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("error when creating client: %+v", err)
+	}
+
 	ch.Client = clientset
 	u.Client = clientset
 
@@ -222,6 +230,10 @@ func PerformUpgrade() error {
 		return fmt.Errorf("error when triggering the upgrade: %+v", err)
 	}
 
+	tracker, err := newProgressTrackerFromEnv(clientset, dynamicClient)
+	if err != nil {
+		return err
+	}
 	err = k8swait.PollUntilContextTimeout(context.Background(), 20*time.Second, 90*time.Minute, true, func(ctx context.Context) (done bool, err error) {
 		if err := us.update(); err != nil {
 			klog.Errorf("failed to get an update about upgrade status: %+v", err)
@@ -233,10 +245,28 @@ func PerformUpgrade() error {
 			return true, nil
 		}
 		klog.Infof("upgrading from %s - current progress: %s", us.initialVersion, us.currentProgress)
+
+		progress, err := tracker.Check(ctx)
+		if err != nil {
+			klog.Errorf("failed to get progress of cluster operators: %+v", err)
+			return false, nil
+		}
+		klog.Info(progress.Summary())
+		if stalled := progress.Stalled(); len(stalled) > 0 {
+			var names []string
+			for _, op := range stalled {
+				names = append(names, fmt.Sprintf("%s (%s for %s: %s)", op.Name, conditionName(op), op.Duration.Round(time.Second), op.Message))
+			}
+			return false, fmt.Errorf("cluster operators exceeded their budgets: %s", strings.Join(names, ", "))
+		}
 		return false, nil
 	})
 	if err != nil {
-		return fmt.Errorf("timed out waiting for the upgrade to finish: %s", utils.ToPrettyJSONString(us.clusterVersion.Status))
+		saveUpgradeDiagnostics(tracker)
+		if k8swait.Interrupted(err) {
+			return fmt.Errorf("timed out waiting for the upgrade to finish: %s", utils.ToPrettyJSONString(us.clusterVersion.Status))
+		}
+		return fmt.Errorf("upgrade stalled: %+v", err)
 	}
 
 	if err := us.runPostUpgradeActions(); err != nil {
@@ -246,6 +276,43 @@ func PerformUpgrade() error {
 	return nil
 }
 
+func newProgressTrackerFromEnv(configClient configv1client.Interface, dynamicClient dynamic.Interface) (*ProgressTracker, error) {
+	tracker := NewProgressTracker(configClient, dynamicClient)
+	if budget := utils.GetEnv("UPGRADE_OPERATOR_STALL_BUDGET", ""); budget != "" {
+		d, err := time.ParseDuration(budget)
+		if err != nil {
+			return nil, fmt.Errorf("invalid UPGRADE_OPERATOR_STALL_BUDGET: %+v", err)
+		}
+		tracker.DefaultBudget = d
+	}
+	budgets, err := ParseBudgets(utils.GetEnv("UPGRADE_OPERATOR_STALL_BUDGETS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid UPGRADE_OPERATOR_STALL_BUDGETS: %+v", err)
+	}
+	tracker.Budgets = budgets
+	return tracker, nil
+}
+
+// saveUpgradeDiagnostics prints the diagnostics and stores them to the artifact directory
+func saveUpgradeDiagnostics(tracker *ProgressTracker) {
+	buf := &bytes.Buffer{}
+	if err := tracker.PrintDiagnostics(context.Background(), buf); err != nil {
+		klog.Errorf("failed to collect upgrade diagnostics: %+v", err)
+	}
+	klog.Infof("upgrade diagnostics:\n%s", buf.String())
+	file := filepath.Join(utils.GetEnv("ARTIFACT_DIR", "."), "openshift-upgrade-diagnostics.txt")
+	if err := os.WriteFile(file, buf.Bytes(), 0600); err != nil {
+		klog.Errorf("failed to store upgrade diagnostics to %s: %+v", file, err)
+	}
+}
+
+func conditionName(op OperatorProgress) string {
+	if op.Degraded {
+		return "degraded"
+	}
+	return "progressing"
+}
+
 func findClusterOperatorStatusCondition(conditions []configv1.ClusterOperatorStatusCondition, name configv1.ClusterStatusConditionType) *configv1.ClusterOperatorStatusCondition {
 	for i := range conditions {
 		if conditions[i].Type == name {
@@ -56,6 +56,15 @@ func (g *Github) ListPullRequestCommentsSince(repository string, prNumber int, s
 	return comments, nil
 }
 
+func (g *Github) CreatePullRequestComment(repository string, prNumber int, body string) (*github.IssueComment, error) {
+	comment, _, err := g.client.Issues.CreateComment(context.Background(), g.organization, repository, prNumber, &github.IssueComment{Body: &body})
+	if err != nil {
+		return nil, fmt.Errorf("error when commenting pull request number %d for the repo %s: %v", prNumber, repository, err)
+	}
+
+	return comment, nil
+}
+
 func (g *Github) MergePullRequest(repository string, prNumber int) (*github.PullRequestMergeResult, error) {
 	mergeResult, _, err := g.client.PullRequests.Merge(context.Background(), g.organization, repository, prNumber, "", &github.PullRequestOptions{})
 	if err != nil {
@@ -0,0 +1,215 @@
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// checksumsSuffix is the suffix of the assets listing the checksums of the other assets, e.g. SHA256SUMS or app_1.0.0_SHA256SUMS
+const checksumsSuffix = "SHA256SUMS"
+
+// signatureSuffix is the suffix of the detached signature of an asset, e.g. SHA256SUMS.sig
+const signatureSuffix = ".sig"
+
+// ExpectedRelease is what a GitHub release created by a release pipeline has to contain, empty fields are not checked
+type ExpectedRelease struct {
+	TagName string
+	Name    string
+	// Notes are snippets the release notes (the body of the release) have to contain
+	Notes []string
+	// Assets are the names of the assets the release has to contain
+	Assets []string
+}
+
+// VerifyRelease checks the release is published and matches the expected tag, name, notes and assets
+func VerifyRelease(release *Release, expected ExpectedRelease) error {
+	var errs []error
+	if release.GetDraft() {
+		errs = append(errs, fmt.Errorf("release %s is a draft", release.GetTagName()))
+	}
+	if expected.TagName != "" && release.GetTagName() != expected.TagName {
+		errs = append(errs, fmt.Errorf("release has tag %s, expected %s", release.GetTagName(), expected.TagName))
+	}
+	if expected.Name != "" && release.GetName() != expected.Name {
+		errs = append(errs, fmt.Errorf("release has name %q, expected %q", release.GetName(), expected.Name))
+	}
+	for _, note := range expected.Notes {
+		if !strings.Contains(release.GetBody(), note) {
+			errs = append(errs, fmt.Errorf("release notes do not contain %q", note))
+		}
+	}
+	for _, name := range expected.Assets {
+		if release.Asset(name) == nil {
+			errs = append(errs, fmt.Errorf("release has no asset %s", name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// VerifyAssetChecksums checks the checksums listed in the SHA256SUMS assets of the release match the downloaded assets
+// and every other asset (except signatures) is listed. It fails when the release has no SHA256SUMS asset.
+func VerifyAssetChecksums(release *Release) error {
+	listed := map[string]string{}
+	for _, asset := range release.Assets {
+		if !strings.HasSuffix(asset.Name, checksumsSuffix) {
+			continue
+		}
+		checksums, err := parseChecksums(asset.Content)
+		if err != nil {
+			return fmt.Errorf("failed to parse checksums asset %s: %+v", asset.Name, err)
+		}
+		for name, checksum := range checksums {
+			listed[name] = checksum
+		}
+	}
+	if len(listed) == 0 {
+		return fmt.Errorf("release %s has no %s asset", release.GetTagName(), checksumsSuffix)
+	}
+
+	var errs []error
+	for name, checksum := range listed {
+		asset := release.Asset(name)
+		if asset == nil {
+			errs = append(errs, fmt.Errorf("asset %s has a checksum but is not attached to the release", name))
+			continue
+		}
+		if asset.SHA256 != checksum {
+			errs = append(errs, fmt.Errorf("asset %s has checksum %s, expected %s", name, asset.SHA256, checksum))
+		}
+	}
+	for _, asset := range release.Assets {
+		if strings.HasSuffix(asset.Name, checksumsSuffix) || strings.HasSuffix(asset.Name, signatureSuffix) {
+			continue
+		}
+		if _, ok := listed[asset.Name]; !ok {
+			errs = append(errs, fmt.Errorf("asset %s has no checksum", asset.Name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// parseChecksums parses the output of sha256sum, "<checksum> <name>" or "<checksum> *<name>" per line
+func parseChecksums(content []byte) (map[string]string, error) {
+	checksums := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 || len(fields[0]) != sha256.Size*2 {
+			return nil, fmt.Errorf("invalid checksum line %q", line)
+		}
+		checksums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return checksums, scanner.Err()
+}
+
+// VerifyAssetSignatures checks the detached signature of every asset with a <name>.sig asset against the public key,
+// which is either an (armored) PGP public key or a PEM encoded ECDSA, RSA or Ed25519 public key (e.g. a cosign key).
+// Releases without signatures pass.
+func VerifyAssetSignatures(release *Release, publicKey []byte) error {
+	var errs []error
+	var verify func(content, signature []byte) error
+	for _, signature := range release.Assets {
+		if !strings.HasSuffix(signature.Name, signatureSuffix) {
+			continue
+		}
+		name := strings.TrimSuffix(signature.Name, signatureSuffix)
+		asset := release.Asset(name)
+		if asset == nil {
+			errs = append(errs, fmt.Errorf("signature %s has no asset %s", signature.Name, name))
+			continue
+		}
+		if verify == nil {
+			var err error
+			if verify, err = signatureVerifier(publicKey); err != nil {
+				return err
+			}
+		}
+		if err := verify(asset.Content, signature.Content); err != nil {
+			errs = append(errs, fmt.Errorf("signature %s of asset %s is not valid: %+v", signature.Name, name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// signatureVerifier returns a function verifying detached signatures made by the private key of the public key
+func signatureVerifier(publicKey []byte) (func(content, signature []byte) error, error) {
+	if len(bytes.TrimSpace(publicKey)) == 0 {
+		return nil, fmt.Errorf("the release has signatures but no public key was provided to verify them")
+	}
+	if bytes.Contains(publicKey, []byte("BEGIN PGP PUBLIC KEY BLOCK")) {
+		keyring, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(publicKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pgp public key: %+v", err)
+		}
+		return pgpVerifier(keyring), nil
+	}
+
+	block, _ := pem.Decode(publicKey)
+	if block == nil {
+		keyring, err := openpgp.ReadKeyRing(bytes.NewReader(publicKey))
+		if err != nil {
+			return nil, fmt.Errorf("public key is neither a PEM encoded nor a pgp key: %+v", err)
+		}
+		return pgpVerifier(keyring), nil
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %+v", err)
+	}
+	return func(content, signature []byte) error {
+		signature = decodeSignature(signature)
+		digest := sha256.Sum256(content)
+		switch k := key.(type) {
+		case *ecdsa.PublicKey:
+			if !ecdsa.VerifyASN1(k, digest[:], signature) {
+				return fmt.Errorf("ecdsa signature does not match")
+			}
+			return nil
+		case *rsa.PublicKey:
+			return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature)
+		case ed25519.PublicKey:
+			if !ed25519.Verify(k, content, signature) {
+				return fmt.Errorf("ed25519 signature does not match")
+			}
+			return nil
+		default:
+			return fmt.Errorf("unsupported public key type %T", key)
+		}
+	}, nil
+}
+
+func pgpVerifier(keyring openpgp.KeyRing) func(content, signature []byte) error {
+	return func(content, signature []byte) error {
+		check := openpgp.CheckDetachedSignature
+		if bytes.Contains(signature, []byte("BEGIN PGP SIGNATURE")) {
+			check = openpgp.CheckArmoredDetachedSignature
+		}
+		_, err := check(keyring, bytes.NewReader(content), bytes.NewReader(signature), nil)
+		return err
+	}
+}
+
+// decodeSignature returns the raw signature, cosign stores the signature base64 encoded
+func decodeSignature(signature []byte) []byte {
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature))); err == nil {
+		return decoded
+	}
+	return signature
+}
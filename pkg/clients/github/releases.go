@@ -0,0 +1,103 @@
+package github
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v44/github"
+	. "github.com/onsi/ginkgo/v2"
+)
+
+// ReleaseAsset is a downloaded asset of a GitHub release
+type ReleaseAsset struct {
+	Name        string
+	ContentType string
+	Content     []byte
+	// SHA256 is the hex encoded checksum of the content
+	SHA256 string
+}
+
+// Release is a GitHub release with its downloaded assets
+type Release struct {
+	*github.RepositoryRelease
+	Assets []ReleaseAsset
+}
+
+// Asset returns the asset with the name or nil when the release has no such asset
+func (r *Release) Asset(name string) *ReleaseAsset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// TagFromReleaseURL returns the tag of the release from its URL, e.g. https://github.com/org/repo/releases/tag/v1.0.0
+func TagFromReleaseURL(releaseURL string) string {
+	urlParts := strings.Split(strings.TrimSpace(releaseURL), "/")
+	return urlParts[len(urlParts)-1]
+}
+
+// GetReleaseWithAssets returns the release with the tag and downloads all its assets
+func (g *Github) GetReleaseWithAssets(owner, repositoryName, tagName string) (*Release, error) {
+	ctx := context.Background()
+	repositoryRelease, _, err := g.client.Repositories.GetReleaseByTag(ctx, owner, repositoryName, tagName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get release %s in repository %s/%s: %+v", tagName, owner, repositoryName, err)
+	}
+
+	var assets []*github.ReleaseAsset
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := g.client.Repositories.ListReleaseAssets(ctx, owner, repositoryName, repositoryRelease.GetID(), opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list assets of release %s in repository %s/%s: %+v", tagName, owner, repositoryName, err)
+		}
+		assets = append(assets, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	release := &Release{RepositoryRelease: repositoryRelease}
+	for _, asset := range assets {
+		content, err := g.DownloadReleaseAsset(owner, repositoryName, asset)
+		if err != nil {
+			return nil, err
+		}
+		checksum := sha256.Sum256(content)
+		release.Assets = append(release.Assets, ReleaseAsset{
+			Name:        asset.GetName(),
+			ContentType: asset.GetContentType(),
+			Content:     content,
+			SHA256:      hex.EncodeToString(checksum[:]),
+		})
+	}
+	GinkgoWriter.Printf("Release %s in repository %s/%s has %d assets\n", tagName, owner, repositoryName, len(release.Assets))
+	return release, nil
+}
+
+// DownloadReleaseAsset returns the content of the release asset, it fails when the size of the content differs from the size reported by GitHub
+func (g *Github) DownloadReleaseAsset(owner, repositoryName string, asset *github.ReleaseAsset) ([]byte, error) {
+	rc, _, err := g.client.Repositories.DownloadReleaseAsset(context.Background(), owner, repositoryName, asset.GetID(), http.DefaultClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download release asset %s: %+v", asset.GetName(), err)
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release asset %s: %+v", asset.GetName(), err)
+	}
+	if asset.Size != nil && len(content) != asset.GetSize() {
+		return nil, fmt.Errorf("release asset %s has %d bytes, expected %d", asset.GetName(), len(content), asset.GetSize())
+	}
+	return content, nil
+}
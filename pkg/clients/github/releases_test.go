@@ -0,0 +1,140 @@
+package github
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/google/go-github/v44/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func testRelease(assets map[string]string) *Release {
+	release := &Release{RepositoryRelease: &github.RepositoryRelease{
+		TagName: github.String("v1.0.0"),
+		Name:    github.String("Release 1.0.0"),
+		Body:    github.String("Fixes the login\nSee the docs"),
+	}}
+	for name, content := range assets {
+		release.Assets = append(release.Assets, ReleaseAsset{Name: name, Content: []byte(content), SHA256: checksum(content)})
+	}
+	return release
+}
+
+func TestTagFromReleaseURL(t *testing.T) {
+	assert.Equal(t, "v1.0.0", TagFromReleaseURL("https://github.com/org/repo/releases/tag/v1.0.0\n"))
+}
+
+func TestGetReleaseWithAssets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/org/repo/releases/tags/v1.0.0":
+			fmt.Fprint(w, `{"id": 1, "tag_name": "v1.0.0", "name": "Release 1.0.0"}`)
+		case "/repos/org/repo/releases/1/assets":
+			fmt.Fprint(w, `[{"id": 10, "name": "app.zip", "size": 3}, {"id": 11, "name": "broken.zip", "size": 100}]`)
+		case "/repos/org/repo/releases/assets/10", "/repos/org/repo/releases/assets/11":
+			assert.Equal(t, "application/octet-stream", r.Header.Get("Accept"))
+			fmt.Fprint(w, "zip")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	g := &Github{client: client}
+
+	_, err := g.GetReleaseWithAssets("org", "repo", "v1.0.0")
+	assert.ErrorContains(t, err, "release asset broken.zip has 3 bytes, expected 100")
+
+	asset, err := g.DownloadReleaseAsset("org", "repo", &github.ReleaseAsset{ID: github.Int64(10), Name: github.String("app.zip"), Size: github.Int(3)})
+	assert.NoError(t, err)
+	assert.Equal(t, "zip", string(asset))
+
+	_, err = g.GetReleaseWithAssets("org", "repo", "v2.0.0")
+	assert.ErrorContains(t, err, "failed to get release v2.0.0 in repository org/repo")
+}
+
+func TestVerifyRelease(t *testing.T) {
+	release := testRelease(map[string]string{"app.zip": "zip"})
+	assert.NoError(t, VerifyRelease(release, ExpectedRelease{TagName: "v1.0.0", Notes: []string{"Fixes the login"}, Assets: []string{"app.zip"}}))
+
+	err := VerifyRelease(release, ExpectedRelease{TagName: "v1.0.1", Name: "Release 1.0.1", Notes: []string{"Adds the logout"}, Assets: []string{"app.json"}})
+	assert.ErrorContains(t, err, "release has tag v1.0.0, expected v1.0.1")
+	assert.ErrorContains(t, err, `release has name "Release 1.0.0", expected "Release 1.0.1"`)
+	assert.ErrorContains(t, err, `release notes do not contain "Adds the logout"`)
+	assert.ErrorContains(t, err, "release has no asset app.json")
+}
+
+func TestVerifyAssetChecksums(t *testing.T) {
+	sums := fmt.Sprintf("%s  app.zip\n%s *app.json\n", checksum("zip"), checksum("json"))
+	assert.NoError(t, VerifyAssetChecksums(testRelease(map[string]string{
+		"app.zip": "zip", "app.json": "json", "app_SHA256SUMS": sums, "app_SHA256SUMS.sig": "sig",
+	})))
+
+	err := VerifyAssetChecksums(testRelease(map[string]string{
+		"app.zip": "tampered", "app.tar.gz": "tar", "SHA256SUMS": sums,
+	}))
+	assert.ErrorContains(t, err, fmt.Sprintf("asset app.zip has checksum %s, expected %s", checksum("tampered"), checksum("zip")))
+	assert.ErrorContains(t, err, "asset app.json has a checksum but is not attached to the release")
+	assert.ErrorContains(t, err, "asset app.tar.gz has no checksum")
+
+	assert.ErrorContains(t, VerifyAssetChecksums(testRelease(map[string]string{"app.zip": "zip"})), "release v1.0.0 has no SHA256SUMS asset")
+	assert.ErrorContains(t, VerifyAssetChecksums(testRelease(map[string]string{"SHA256SUMS": "abc app.zip"})), `invalid checksum line "abc app.zip"`)
+}
+
+func TestVerifyAssetSignaturesPGP(t *testing.T) {
+	entity, err := openpgp.NewEntity("release", "", "release@example.com", nil)
+	assert.NoError(t, err)
+	publicKey := &bytes.Buffer{}
+	w, err := armor.Encode(publicKey, openpgp.PublicKeyType, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, entity.Serialize(w))
+	assert.NoError(t, w.Close())
+
+	signature := &bytes.Buffer{}
+	assert.NoError(t, openpgp.ArmoredDetachSign(signature, entity, bytes.NewReader([]byte("sums")), nil))
+
+	assert.NoError(t, VerifyAssetSignatures(testRelease(map[string]string{"SHA256SUMS": "sums", "SHA256SUMS.sig": signature.String()}), publicKey.Bytes()))
+	assert.ErrorContains(t, VerifyAssetSignatures(testRelease(map[string]string{"SHA256SUMS": "tampered", "SHA256SUMS.sig": signature.String()}), publicKey.Bytes()),
+		"signature SHA256SUMS.sig of asset SHA256SUMS is not valid")
+}
+
+func TestVerifyAssetSignaturesPEM(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	assert.NoError(t, err)
+	publicKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	digest := sha256.Sum256([]byte("zip"))
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	assert.NoError(t, err)
+	encoded := base64.StdEncoding.EncodeToString(signature)
+
+	assert.NoError(t, VerifyAssetSignatures(testRelease(map[string]string{"app.zip": "zip", "app.zip.sig": encoded}), publicKey))
+	assert.ErrorContains(t, VerifyAssetSignatures(testRelease(map[string]string{"app.zip": "tampered", "app.zip.sig": encoded}), publicKey),
+		"ecdsa signature does not match")
+	assert.ErrorContains(t, VerifyAssetSignatures(testRelease(map[string]string{"app.json.sig": encoded}), publicKey), "signature app.json.sig has no asset app.json")
+	assert.ErrorContains(t, VerifyAssetSignatures(testRelease(map[string]string{"app.zip": "zip", "app.zip.sig": encoded}), nil), "no public key was provided")
+	assert.NoError(t, VerifyAssetSignatures(testRelease(map[string]string{"app.zip": "zip"}), nil))
+}
@@ -3,7 +3,6 @@ package github
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/konflux-ci/e2e-tests/pkg/utils"
@@ -12,8 +11,7 @@ import (
 )
 
 func (g *Github) CheckIfReleaseExist(owner, repositoryName, releaseURL string) bool {
-	urlParts := strings.Split(releaseURL, "/")
-	tagName := urlParts[len(urlParts)-1]
+	tagName := TagFromReleaseURL(releaseURL)
 	_, _, err := g.client.Repositories.GetReleaseByTag(context.Background(), owner, repositoryName, tagName)
 	if err != nil {
 		GinkgoWriter.Printf("GetReleaseByTag %s returned error in repo %s : %v\n", tagName, repositoryName, err)
@@ -24,8 +22,7 @@ func (g *Github) CheckIfReleaseExist(owner, repositoryName, releaseURL string) b
 }
 
 func (g *Github) DeleteRelease(owner, repositoryName, releaseURL string) bool {
-	urlParts := strings.Split(releaseURL, "/")
-	tagName := urlParts[len(urlParts)-1]
+	tagName := TagFromReleaseURL(releaseURL)
 	release, _, err := g.client.Repositories.GetReleaseByTag(context.Background(), owner, repositoryName, tagName)
 	if err != nil {
 		GinkgoWriter.Printf("GetReleaseByTag returned error in repo %s : %v\n", repositoryName, err)
@@ -72,12 +69,26 @@ func (g *Github) GetFile(repository, pathToFile, branchName string) (*github.Rep
 	}
 	file, _, _, err := g.client.Repositories.GetContents(context.Background(), g.organization, repository, pathToFile, opts)
 	if err != nil {
-		return nil, fmt.Errorf("error when listing file contents: %v", err)
+		return nil, fmt.Errorf("error when listing file contents: %w", err)
 	}
 
 	return file, nil
 }
 
+// GetDirectoryContents returns files and directories in the directory of the branch
+func (g *Github) GetDirectoryContents(repository, pathToDirectory, branchName string) ([]*github.RepositoryContent, error) {
+	opts := &github.RepositoryContentGetOptions{}
+	if branchName != "" {
+		opts.Ref = fmt.Sprintf(HEADS, branchName)
+	}
+	_, contents, _, err := g.client.Repositories.GetContents(context.Background(), g.organization, repository, pathToDirectory, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error when listing directory contents: %v", err)
+	}
+
+	return contents, nil
+}
+
 func (g *Github) UpdateFile(repository, pathToFile, newContent, branchName, fileSHA string) (*github.RepositoryContentResponse, error) {
 	opts := &github.RepositoryContentGetOptions{}
 	if branchName != "" {
@@ -149,3 +149,84 @@ func (gc *GitlabClient) DeleteWebhooks(projectID, clusterAppDomain string) error
 
 	return nil
 }
+
+// ListProjectWebhooks returns webhooks of the project
+func (gc *GitlabClient) ListProjectWebhooks(projectID string) ([]*gitlab.ProjectHook, error) {
+	webhooks, _, err := gc.client.Projects.ListProjectHooks(projectID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list hooks of projectID %s: %v", projectID, err)
+	}
+	return webhooks, nil
+}
+
+// ListOpenMergeRequests returns opened MergeRequests in the project
+func (gc *GitlabClient) ListOpenMergeRequests(projectID string) ([]*gitlab.MergeRequest, error) {
+	mergeRequests, _, err := gc.client.MergeRequests.ListProjectMergeRequests(projectID, &gitlab.ListProjectMergeRequestsOptions{State: gitlab.Ptr("opened")})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list MRs in projectID %s: %v", projectID, err)
+	}
+	return mergeRequests, nil
+}
+
+// AcceptMergeRequest merges the MR of IID in the project and returns the merged MR
+func (gc *GitlabClient) AcceptMergeRequest(projectID string, mergeRequestIID int) (*gitlab.MergeRequest, error) {
+	mergeRequest, _, err := gc.client.MergeRequests.AcceptMergeRequest(projectID, mergeRequestIID, &gitlab.AcceptMergeRequestOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge MR of IID %d in projectID %s: %v", mergeRequestIID, projectID, err)
+	}
+	return mergeRequest, nil
+}
+
+// GetFileContent returns the content of the file in the branch of the project
+func (gc *GitlabClient) GetFileContent(projectID, pathToFile, branchName string) (string, error) {
+	content, _, err := gc.client.RepositoryFiles.GetRawFile(projectID, pathToFile, &gitlab.GetRawFileOptions{Ref: gitlab.Ptr(branchName)})
+	if err != nil {
+		return "", fmt.Errorf("failed to get file %s from branch %s in projectID %s: %v", pathToFile, branchName, projectID, err)
+	}
+	return string(content), nil
+}
+
+// CreateMergeRequestComment adds a comment (note) to the MR of IID in the project
+func (gc *GitlabClient) CreateMergeRequestComment(projectID string, mergeRequestIID int, body string) error {
+	_, _, err := gc.client.Notes.CreateMergeRequestNote(projectID, mergeRequestIID, &gitlab.CreateMergeRequestNoteOptions{Body: gitlab.Ptr(body)})
+	if err != nil {
+		return fmt.Errorf("failed to comment MR of IID %d in projectID %s: %v", mergeRequestIID, projectID, err)
+	}
+	return nil
+}
+
+// ListFiles returns paths of files in the directory of the branch
+func (gc *GitlabClient) ListFiles(projectID, pathToDirectory, branchName string) ([]string, error) {
+	nodes, _, err := gc.client.Repositories.ListTree(projectID, &gitlab.ListTreeOptions{Path: gitlab.Ptr(pathToDirectory), Ref: gitlab.Ptr(branchName)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directory %s in branch %s in projectID %s: %v", pathToDirectory, branchName, projectID, err)
+	}
+	var files []string
+	for _, node := range nodes {
+		if node.Type == "blob" {
+			files = append(files, node.Path)
+		}
+	}
+	return files, nil
+}
+
+// CommitFile creates or updates the file in the branch and returns SHA of the commit
+func (gc *GitlabClient) CommitFile(projectID, pathToFile, fileContent, branchName string) (string, error) {
+	action := gitlab.FileCreate
+	if _, _, err := gc.client.RepositoryFiles.GetFileMetaData(projectID, pathToFile, &gitlab.GetFileMetaDataOptions{Ref: gitlab.Ptr(branchName)}); err == nil {
+		action = gitlab.FileUpdate
+	}
+	commit, _, err := gc.client.Commits.CreateCommit(projectID, &gitlab.CreateCommitOptions{
+		Branch:        gitlab.Ptr(branchName),
+		CommitMessage: gitlab.Ptr("e2e test commit message"),
+		Actions: []*gitlab.CommitActionOptions{{
+			Action:   gitlab.Ptr(action),
+			FilePath: gitlab.Ptr(pathToFile),
+			Content:  gitlab.Ptr(fileContent),
+		}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to commit file %s to branch %s in projectID %s: %v", pathToFile, branchName, projectID, err)
+	}
+	return commit.ID, nil
+}
@@ -0,0 +1,306 @@
+package has
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	appservice "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	SkipInitialChecksAnnotation = "skip-initial-checks"
+
+	defaultComponentTargetPort = 8081
+)
+
+// annotation keys of the build and image requests, taken from the annotations defined in pkg/constants
+var (
+	buildRequestAnnotation  = annotationName(constants.ComponentPaCRequestAnnotation)
+	buildPipelineAnnotation = annotationName(constants.DefaultDockerBuildPipelineBundle)
+	imageGenerateAnnotation = annotationName(constants.ImageControllerAnnotationRequestPublicRepo)
+)
+
+func annotationName(annotation map[string]string) string {
+	for name := range annotation {
+		return name
+	}
+	return ""
+}
+
+// ComponentBuilder creates Components with chainable options, e.g.
+//
+//	component, err := fw.AsKubeAdmin.HasController.NewComponentBuilder(name, namespace, appName).
+//		WithGitSource(gitURL).WithRevision("main").WithDockerfile("Dockerfile").
+//		WithPaC(true).WithPipelineBundle("docker-build", "latest").
+//		Create()
+//
+// Invalid combinations of options are reported by Build and Create.
+type ComponentBuilder struct {
+	controller *HasController
+	component  *appservice.Component
+
+	annotations       map[string]string
+	pac               *bool
+	pipelineName      string
+	pipelineBundle    string
+	imageVisibility   string
+	noImageRepo       bool
+	sourceOptionsUsed []string
+}
+
+// NewComponentBuilder returns a builder of a Component with the given name in the application
+func (h *HasController) NewComponentBuilder(name, namespace, applicationName string) *ComponentBuilder {
+	return &ComponentBuilder{
+		controller: h,
+		component: &appservice.Component{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: appservice.ComponentSpec{
+				ComponentName: name,
+				Application:   applicationName,
+			},
+		},
+		annotations: map[string]string{},
+	}
+}
+
+// FromSpec replaces the spec of the Component, the name is kept unless set in the spec and the application of the builder is always kept
+func (b *ComponentBuilder) FromSpec(spec appservice.ComponentSpec) *ComponentBuilder {
+	if spec.ComponentName == "" {
+		spec.ComponentName = b.component.Spec.ComponentName
+	}
+	spec.Application = b.component.Spec.Application
+	b.component.Spec = *spec.DeepCopy()
+	return b
+}
+
+func (b *ComponentBuilder) gitSource() *appservice.GitSource {
+	if b.component.Spec.Source.GitSource == nil {
+		b.component.Spec.Source.GitSource = &appservice.GitSource{}
+	}
+	return b.component.Spec.Source.GitSource
+}
+
+// WithGitSource sets the URL of the git repository with the Component source code
+func (b *ComponentBuilder) WithGitSource(url string) *ComponentBuilder {
+	b.gitSource().URL = url
+	return b
+}
+
+// WithRevision sets the git branch, tag or commit to build
+func (b *ComponentBuilder) WithRevision(revision string) *ComponentBuilder {
+	b.gitSource().Revision = revision
+	b.sourceOptionsUsed = append(b.sourceOptionsUsed, "revision")
+	return b
+}
+
+// WithContext sets the directory within the git repository with the Component source code
+func (b *ComponentBuilder) WithContext(context string) *ComponentBuilder {
+	b.gitSource().Context = context
+	b.sourceOptionsUsed = append(b.sourceOptionsUsed, "context")
+	return b
+}
+
+// WithDockerfile sets the path (or URL) of the Dockerfile
+func (b *ComponentBuilder) WithDockerfile(dockerfile string) *ComponentBuilder {
+	b.gitSource().DockerfileURL = dockerfile
+	b.sourceOptionsUsed = append(b.sourceOptionsUsed, "dockerfile")
+	return b
+}
+
+// WithPaC requests Pipelines as Code configuration (a PR with PipelineRun definitions is sent to the repository)
+// when enabled, or a single simple build when disabled
+func (b *ComponentBuilder) WithPaC(enabled bool) *ComponentBuilder {
+	b.pac = &enabled
+	return b
+}
+
+// WithPipelineBundle selects the build pipeline by its name and bundle, an empty bundle means "latest"
+func (b *ComponentBuilder) WithPipelineBundle(name, bundle string) *ComponentBuilder {
+	if bundle == "" {
+		bundle = "latest"
+	}
+	b.pipelineName, b.pipelineBundle = name, bundle
+	return b
+}
+
+// WithImageRepository requests image-controller to generate an image repository with "public" or "private" visibility
+func (b *ComponentBuilder) WithImageRepository(visibility string) *ComponentBuilder {
+	b.imageVisibility = visibility
+	return b
+}
+
+// WithOutputImage sets the container image the Component is built to, instead of a generated image repository
+func (b *ComponentBuilder) WithOutputImage(image string) *ComponentBuilder {
+	b.component.Spec.ContainerImage = image
+	return b
+}
+
+// WithNudges sets the Components whose builds are nudged (updated) with images built for this Component
+func (b *ComponentBuilder) WithNudges(componentNames ...string) *ComponentBuilder {
+	b.component.Spec.BuildNudgesRef = append(b.component.Spec.BuildNudgesRef, componentNames...)
+	return b
+}
+
+// WithAnnotations adds annotations to the Component
+func (b *ComponentBuilder) WithAnnotations(annotations map[string]string) *ComponentBuilder {
+	b.annotations = utils.MergeMaps(b.annotations, annotations)
+	return b
+}
+
+// WithSecret sets the name of the secret with git credentials
+func (b *ComponentBuilder) WithSecret(secret string) *ComponentBuilder {
+	b.component.Spec.Secret = secret
+	return b
+}
+
+// WithSkipInitialChecks sets the skip-initial-checks annotation
+func (b *ComponentBuilder) WithSkipInitialChecks(skip bool) *ComponentBuilder {
+	b.annotations[SkipInitialChecksAnnotation] = strconv.FormatBool(skip)
+	return b
+}
+
+// WithReplicas sets the number of replicas of the Component
+func (b *ComponentBuilder) WithReplicas(replicas int) *ComponentBuilder {
+	b.component.Spec.Replicas = &replicas
+	return b
+}
+
+// WithoutImageRepository doesn't request the default public image repository and Create doesn't wait
+// for image-controller to annotate the Component with it
+func (b *ComponentBuilder) WithoutImageRepository() *ComponentBuilder {
+	b.noImageRepo = true
+	return b
+}
+
+// Build validates the options and returns the Component object without creating it
+func (b *ComponentBuilder) Build() (*appservice.Component, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	component := b.component.DeepCopy()
+	annotations := utils.MergeMaps(component.Annotations, b.annotations)
+
+	if b.pac != nil {
+		request := constants.ComponentTriggerSimpleBuildAnnotation
+		if *b.pac {
+			request = constants.ComponentPaCRequestAnnotation
+		}
+		annotations = utils.MergeMaps(annotations, request)
+	}
+	if b.pipelineName != "" {
+		annotations[buildPipelineAnnotation] = fmt.Sprintf(`{"name": "%s", "bundle": "%s"}`, b.pipelineName, b.pipelineBundle)
+	}
+	switch {
+	case b.imageVisibility == "private":
+		annotations = utils.MergeMaps(annotations, constants.ImageControllerAnnotationRequestPrivateRepo)
+	case b.imageVisibility == "public":
+		annotations = utils.MergeMaps(annotations, constants.ImageControllerAnnotationRequestPublicRepo)
+	case !b.noImageRepo && component.Spec.ContainerImage == "" && annotations[imageGenerateAnnotation] == "":
+		// Generate default public image repo since nothing is mentioned specifically
+		annotations = utils.MergeMaps(annotations, constants.ImageControllerAnnotationRequestPublicRepo)
+	}
+	if len(annotations) > 0 {
+		component.Annotations = annotations
+	}
+
+	if component.Spec.TargetPort == 0 {
+		component.Spec.TargetPort = defaultComponentTargetPort
+	}
+	return component, nil
+}
+
+func (b *ComponentBuilder) validate() error {
+	var errs []error
+	spec := b.component.Spec
+	for _, msg := range validation.IsDNS1123Subdomain(b.component.Name) {
+		errs = append(errs, fmt.Errorf("invalid component name %q: %s", b.component.Name, msg))
+	}
+	if b.component.Namespace == "" {
+		errs = append(errs, fmt.Errorf("namespace is not set"))
+	}
+	if spec.Application == "" {
+		errs = append(errs, fmt.Errorf("application is not set"))
+	}
+
+	if spec.Source.GitSource == nil || spec.Source.GitSource.URL == "" {
+		if len(b.sourceOptionsUsed) > 0 {
+			errs = append(errs, fmt.Errorf("%v set without a git source", b.sourceOptionsUsed))
+		}
+		if b.pac != nil {
+			errs = append(errs, fmt.Errorf("PaC can be configured only for components with a git source"))
+		}
+		if spec.Source.ComponentSourceUnion == (appservice.ComponentSourceUnion{}) && spec.ContainerImage == "" {
+			errs = append(errs, fmt.Errorf("neither git source nor container image is set"))
+		}
+	}
+
+	if b.pac != nil {
+		if request, ok := b.annotations[buildRequestAnnotation]; ok {
+			errs = append(errs, fmt.Errorf("PaC option conflicts with %s annotation %q", buildRequestAnnotation, request))
+		}
+	}
+	if b.pipelineName != "" {
+		if pipeline, ok := b.annotations[buildPipelineAnnotation]; ok {
+			errs = append(errs, fmt.Errorf("pipeline bundle option conflicts with %s annotation %q", buildPipelineAnnotation, pipeline))
+		}
+	}
+
+	if b.imageVisibility != "" {
+		if b.imageVisibility != "public" && b.imageVisibility != "private" {
+			errs = append(errs, fmt.Errorf("image repository visibility must be public or private, got %q", b.imageVisibility))
+		}
+		if spec.ContainerImage != "" {
+			errs = append(errs, fmt.Errorf("image repository cannot be generated for a component with output image %s", spec.ContainerImage))
+		}
+		if _, ok := b.annotations[imageGenerateAnnotation]; ok {
+			errs = append(errs, fmt.Errorf("image repository option conflicts with %s annotation", imageGenerateAnnotation))
+		}
+		if b.noImageRepo {
+			errs = append(errs, fmt.Errorf("image repository option conflicts with WithoutImageRepository"))
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, nudge := range spec.BuildNudgesRef {
+		switch {
+		case nudge == spec.ComponentName:
+			errs = append(errs, fmt.Errorf("component %s cannot nudge itself", nudge))
+		case seen[nudge]:
+			errs = append(errs, fmt.Errorf("component %s is nudged more than once", nudge))
+		}
+		seen[nudge] = true
+	}
+	return errors.Join(errs...)
+}
+
+// Create validates the options, creates the Component and waits for image-controller to set up its image repository
+func (b *ComponentBuilder) Create() (*appservice.Component, error) {
+	component, err := b.Build()
+	if err != nil {
+		return nil, fmt.Errorf("invalid component %s: %+v", b.component.Name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	if err := b.controller.KubeRest().Create(ctx, component); err != nil {
+		return nil, err
+	}
+
+	if !b.noImageRepo {
+		if utils.WaitUntil(b.controller.CheckForImageAnnotation(component), time.Minute*5) != nil {
+			component = b.controller.refreshComponentForErrorDebug(component)
+			return nil, fmt.Errorf("timed out when waiting for image-controller annotations to be updated on component %s in namespace %s. component: %s", component.Name, component.Namespace, utils.ToPrettyJSONString(component))
+		}
+	}
+	return component, nil
+}
@@ -0,0 +1,87 @@
+package has
+
+import (
+	"testing"
+
+	appservice "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponentBuilder(t *testing.T) {
+	h := &HasController{}
+
+	t.Run("valid component", func(t *testing.T) {
+		component, err := h.NewComponentBuilder("comp", "tenant", "app").
+			WithGitSource("https://github.com/org/repo").
+			WithRevision("main").
+			WithDockerfile("Dockerfile").
+			WithPaC(true).
+			WithPipelineBundle("docker-build", "").
+			WithImageRepository("private").
+			WithNudges("other").
+			WithAnnotations(map[string]string{"foo": "bar"}).
+			Build()
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"foo":                   "bar",
+			buildRequestAnnotation:  "configure-pac",
+			buildPipelineAnnotation: `{"name": "docker-build", "bundle": "latest"}`,
+			imageGenerateAnnotation: `{"visibility": "private"}`,
+		}, component.Annotations)
+		assert.Equal(t, "main", component.Spec.Source.GitSource.Revision)
+		assert.Equal(t, []string{"other"}, component.Spec.BuildNudgesRef)
+		assert.Equal(t, 8081, component.Spec.TargetPort)
+	})
+
+	t.Run("defaults to public image repository", func(t *testing.T) {
+		component, err := h.NewComponentBuilder("comp", "tenant", "app").WithGitSource("https://github.com/org/repo").WithPaC(false).Build()
+		assert.NoError(t, err)
+		assert.Equal(t, "trigger-simple-build", component.Annotations[buildRequestAnnotation])
+		assert.Equal(t, `{"visibility": "public"}`, component.Annotations[imageGenerateAnnotation])
+
+		component, err = h.NewComponentBuilder("comp", "tenant", "app").WithGitSource("https://github.com/org/repo").WithOutputImage("quay.io/org/comp").Build()
+		assert.NoError(t, err)
+		assert.NotContains(t, component.Annotations, imageGenerateAnnotation)
+
+		component, err = h.NewComponentBuilder("comp", "tenant", "app").WithGitSource("https://github.com/org/repo").WithoutImageRepository().Build()
+		assert.NoError(t, err)
+		assert.Nil(t, component.Annotations)
+	})
+
+	t.Run("from spec", func(t *testing.T) {
+		component, err := h.NewComponentBuilder("comp", "tenant", "app").
+			FromSpec(appservice.ComponentSpec{ComponentName: "comp", Application: "other-app", ContainerImage: "quay.io/org/image"}).
+			WithSecret("git-secret").
+			WithReplicas(2).
+			Build()
+		assert.NoError(t, err)
+		assert.Equal(t, "app", component.Spec.Application)
+		assert.Equal(t, "git-secret", component.Spec.Secret)
+		assert.Equal(t, 2, *component.Spec.Replicas)
+		assert.NotContains(t, component.Annotations, imageGenerateAnnotation)
+	})
+
+	t.Run("invalid combinations", func(t *testing.T) {
+		_, err := h.NewComponentBuilder("Comp", "tenant", "app").
+			WithContext("sub").
+			WithPaC(true).
+			WithAnnotations(map[string]string{buildRequestAnnotation: "configure-pac-no-mr"}).
+			WithImageRepository("internal").
+			WithOutputImage("quay.io/org/comp").
+			WithNudges("Comp", "other", "other").
+			Build()
+		assert.Error(t, err)
+		for _, msg := range []string{
+			`invalid component name "Comp"`,
+			"[context] set without a git source",
+			"PaC can be configured only for components with a git source",
+			"PaC option conflicts with build.appstudio.openshift.io/request annotation",
+			"visibility must be public or private",
+			"image repository cannot be generated for a component with output image",
+			"component Comp cannot nudge itself",
+			"component other is nudged more than once",
+		} {
+			assert.ErrorContains(t, err, msg)
+		}
+	})
+}
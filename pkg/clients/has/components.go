@@ -3,7 +3,6 @@ package has
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"strings"
 	"time"
 
@@ -23,8 +22,6 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog"
-	pointer "k8s.io/utils/ptr"
-	"knative.dev/pkg/apis"
 	rclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 )
@@ -69,13 +66,13 @@ func (h *HasController) GetComponentPipelineRun(componentName string, applicatio
 
 // GetComponentPipelineRunWithType returns first pipeline run for a given component labels with pipeline type within label "pipelines.appstudio.openshift.io/type" ("build", "test")
 func (h *HasController) GetComponentPipelineRunWithType(componentName string, applicationName string, namespace, pipelineType string, sha string) (*pipeline.PipelineRun, error) {
-    prs, err := h.GetComponentPipelineRunsWithType(componentName, applicationName, namespace, "", sha)
-    if err != nil {
-        return nil, err
-    } else {
-        prsVal := *prs
-        return &prsVal[0], nil
-    }
+	prs, err := h.GetComponentPipelineRunsWithType(componentName, applicationName, namespace, "", sha)
+	if err != nil {
+		return nil, err
+	} else {
+		prsVal := *prs
+		return &prsVal[0], nil
+	}
 }
 
 // GetComponentPipelineRunsWithType returns all pipeline runs for a given component labels with pipeline type within label "pipelines.appstudio.openshift.io/type" ("build", "test")
@@ -144,147 +141,48 @@ type RetryOptions struct {
 // For that case this function gives an option to pass in a pointer to a related PLR object (`prToUpdate`) which will be updated (with a valid PLR object) before the end of this function
 // and the PLR object can be then used for making assertions later in the test.
 // If there's no intention for using the original PLR object later in the test, use `nil` instead of the pointer.
+// Use WaitForComponentPipeline to get details about the attempts and failed TaskRuns.
 func (h *HasController) WaitForComponentPipelineToBeFinished(component *appservice.Component, sha string, t *tekton.TektonController, r *RetryOptions, prToUpdate *pipeline.PipelineRun) error {
-	attempts := 1
-	app := component.Spec.Application
-	pr := &pipeline.PipelineRun{}
-
-	for {
-		err := wait.PollUntilContextTimeout(context.Background(), constants.PipelineRunPollingInterval, 30*time.Minute, true, func(ctx context.Context) (done bool, err error) {
-			pr, err = h.GetComponentPipelineRun(component.GetName(), app, component.GetNamespace(), sha)
-
-			if err != nil {
-				GinkgoWriter.Printf("PipelineRun has not been created yet for the Component %s/%s\n", component.GetNamespace(), component.GetName())
-				return false, nil
-			}
-
-			GinkgoWriter.Printf("PipelineRun %s reason: %s\n", pr.Name, pr.GetStatusCondition().GetCondition(apis.ConditionSucceeded).GetReason())
-
-			if !pr.IsDone() {
-				return false, nil
-			}
-
-			if pr.GetStatusCondition().GetCondition(apis.ConditionSucceeded).IsTrue() {
-				return true, nil
-			}
-
-			var prLogs string
-			if err = t.StorePipelineRun(pr); err != nil {
-				GinkgoWriter.Printf("failed to store PipelineRun %s:%s: %s\n", pr.GetNamespace(), pr.GetName(), err.Error())
-			}
-			if prLogs, err = t.GetPipelineRunLogs(pr.Name, pr.Namespace); err != nil {
-				GinkgoWriter.Printf("failed to get logs for PipelineRun %s:%s: %s\n", pr.GetNamespace(), pr.GetName(), err.Error())
-			}
-
-			return false, fmt.Errorf(prLogs)
-		})
-
-		if err != nil {
-			if pr == nil {
-				return fmt.Errorf("PipelineRun cannot be created for the Component %s/%s", component.GetNamespace(), component.GetName())
-			}
-			GinkgoWriter.Printf("attempt %d/%d: PipelineRun %q failed: %+v", attempts, r.Retries+1, pr.GetName(), err)
-			// CouldntGetTask: Retry the PipelineRun only in case we hit the known issue https://issues.redhat.com/browse/SRVKP-2749
-			// TaskRunImagePullFailed: Retry in case of https://issues.redhat.com/browse/RHTAPBUGS-985 and https://github.com/tektoncd/pipeline/issues/7184
-			if attempts == r.Retries+1 || (!r.Always && pr.GetStatusCondition().GetCondition(apis.ConditionSucceeded).GetReason() != "CouldntGetTask" && pr.GetStatusCondition().GetCondition(apis.ConditionSucceeded).GetReason() != "TaskRunImagePullFailed") {
-				return err
-			}
-			if err = t.RemoveFinalizerFromPipelineRun(pr, constants.E2ETestFinalizerName); err != nil {
-				return fmt.Errorf("failed to remove the finalizer from pipelinerun %s:%s in order to retrigger it: %+v", pr.GetNamespace(), pr.GetName(), err)
-			}
-			if err = h.PipelineClient().TektonV1().PipelineRuns(pr.GetNamespace()).Delete(context.Background(), pr.GetName(), metav1.DeleteOptions{}); err != nil {
-				return fmt.Errorf("failed to delete PipelineRun %q from %q namespace with error: %v", pr.GetName(), pr.GetNamespace(), err)
-			}
-			if sha, err = h.RetriggerComponentPipelineRun(component, pr); err != nil {
-				return fmt.Errorf("unable to retrigger pipelinerun for component %s:%s: %+v", component.GetNamespace(), component.GetName(), err)
-			}
-			attempts++
-		} else {
-			break
-		}
+	result, err := h.WaitForComponentPipeline(component, sha, t, r)
+	if err != nil {
+		return err
 	}
 
 	// If prToUpdate variable was passed to this function, update it with the latest version of the PipelineRun object
 	if prToUpdate != nil {
-		pr.DeepCopyInto(prToUpdate)
+		result.PipelineRun.DeepCopyInto(prToUpdate)
 	}
 
 	return nil
 }
 
 // Universal method to create a component in the kubernetes clusters.
+// The component is created in the application (whatever the spec says) by the ComponentBuilder.
 func (h *HasController) CreateComponent(componentSpec appservice.ComponentSpec, namespace string, outputContainerImage string, secret string, applicationName string, skipInitialChecks bool, annotations map[string]string) (*appservice.Component, error) {
-	componentObject := &appservice.Component{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      componentSpec.ComponentName,
-			Namespace: namespace,
-			Annotations: map[string]string{
-				"skip-initial-checks": strconv.FormatBool(skipInitialChecks),
-			},
-		},
-		Spec: componentSpec,
-	}
-	componentObject.Spec.Secret = secret
-	componentObject.Spec.Application = applicationName
-
-	if len(annotations) > 0 {
-		componentObject.Annotations = utils.MergeMaps(componentObject.Annotations, annotations)
-
-	}
-
-	if componentObject.Spec.TargetPort == 0 {
-		componentObject.Spec.TargetPort = 8081
-	}
+	builder := h.NewComponentBuilder(componentSpec.ComponentName, namespace, applicationName).
+		FromSpec(componentSpec).
+		WithSecret(secret).
+		WithSkipInitialChecks(skipInitialChecks).
+		WithAnnotations(annotations)
 	if outputContainerImage != "" {
-		componentObject.Spec.ContainerImage = outputContainerImage
-	} else if componentObject.Annotations["image.redhat.com/generate"] == "" {
+		builder.WithOutputImage(outputContainerImage)
+	} else if annotations[imageGenerateAnnotation] == "" {
 		// Generate default public image repo since nothing is mentioned specifically
-		componentObject.Annotations = utils.MergeMaps(componentObject.Annotations, constants.ImageControllerAnnotationRequestPublicRepo)
+		builder.WithAnnotations(constants.ImageControllerAnnotationRequestPublicRepo)
 	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
-	defer cancel()
-	if err := h.KubeRest().Create(ctx, componentObject); err != nil {
-		return nil, err
-	}
-
-	if utils.WaitUntil(h.CheckForImageAnnotation(componentObject), time.Minute*5) != nil {
-		componentObject = h.refreshComponentForErrorDebug(componentObject)
-		return nil, fmt.Errorf("timed out when waiting for image-controller annotations to be updated on component %s in namespace %s. component: %s", componentSpec.ComponentName, namespace, utils.ToPrettyJSONString(componentObject))
-	}
-	return componentObject, nil
+	return builder.Create()
 }
 
 // CreateComponentWithDockerSource creates a component based on container image source.
 func (h *HasController) CreateComponentWithDockerSource(applicationName, componentName, namespace, gitSourceURL, containerImageSource, outputContainerImage, secret string) (*appservice.Component, error) {
-	component := &appservice.Component{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      componentName,
-			Namespace: namespace,
-		},
-		Spec: appservice.ComponentSpec{
-			ComponentName: componentName,
-			Application:   applicationName,
-			Source: appservice.ComponentSource{
-				ComponentSourceUnion: appservice.ComponentSourceUnion{
-					GitSource: &appservice.GitSource{
-						URL:           gitSourceURL,
-						DockerfileURL: containerImageSource,
-					},
-				},
-			},
-			Secret:         secret,
-			ContainerImage: outputContainerImage,
-			Replicas:       pointer.To[int](1),
-			TargetPort:     8081,
-			Route:          "",
-		},
-	}
-	err := h.KubeRest().Create(context.Background(), component)
-	if err != nil {
-		return nil, err
-	}
-	return component, nil
+	return h.NewComponentBuilder(componentName, namespace, applicationName).
+		WithGitSource(gitSourceURL).
+		WithDockerfile(containerImageSource).
+		WithSecret(secret).
+		WithOutputImage(outputContainerImage).
+		WithReplicas(1).
+		WithoutImageRepository().
+		Create()
 }
 
 // ScaleDeploymentReplicas scales the replicas of a given deployment
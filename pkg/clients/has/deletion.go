@@ -0,0 +1,407 @@
+package has
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appservice "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/konflux-ci/e2e-tests/pkg/clients/github"
+	"github.com/konflux-ci/e2e-tests/pkg/clients/gitlab"
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/utils/build"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	rclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// DependentKinds are kinds of resources which are created for Components and Applications
+// and are expected to be garbage-collected when they are deleted
+var DependentKinds = []schema.GroupVersionKind{
+	{Group: "appstudio.redhat.com", Version: "v1alpha1", Kind: "Component"},
+	{Group: "appstudio.redhat.com", Version: "v1alpha1", Kind: "ImageRepository"},
+	{Group: "tekton.dev", Version: "v1", Kind: "PipelineRun"},
+	{Group: "pipelinesascode.tekton.dev", Version: "v1alpha1", Kind: "Repository"},
+	{Group: "", Version: "v1", Kind: "Secret"},
+	{Group: "", Version: "v1", Kind: "ServiceAccount"},
+}
+
+// componentLabels are labels used by Konflux services to mark resources created for a Component
+var componentLabels = []string{"appstudio.openshift.io/component", "appstudio.redhat.com/component"}
+
+// TrackedResource is a Kubernetes resource recorded before deletion of its owner
+type TrackedResource struct {
+	Kind      string
+	Namespace string
+	Name      string
+	UID       types.UID
+	gvk       schema.GroupVersionKind
+}
+
+func (r TrackedResource) String() string {
+	return fmt.Sprintf("%s %s/%s", r.Kind, r.Namespace, r.Name)
+}
+
+// ExternalResource is a resource outside of the cluster (e.g. a Quay repository or a webhook in a git repository)
+// created for a Component
+type ExternalResource interface {
+	String() string
+	Exists() (bool, error)
+}
+
+// QuayRepository is an image repository in the default Quay organization
+type QuayRepository struct {
+	Name string
+}
+
+func (q QuayRepository) String() string {
+	return "Quay repository " + q.Name
+}
+
+func (q QuayRepository) Exists() (bool, error) {
+	return build.DoesImageRepoExistInQuay(q.Name)
+}
+
+// GitHubWebhook is a webhook in a GitHub repository whose URL contains URLSubstring (e.g. the cluster domain)
+type GitHubWebhook struct {
+	Client       *github.Github
+	Repository   string
+	URLSubstring string
+}
+
+func (w GitHubWebhook) String() string {
+	return fmt.Sprintf("webhook %s in GitHub repository %s", w.URLSubstring, w.Repository)
+}
+
+func (w GitHubWebhook) Exists() (bool, error) {
+	hooks, err := w.Client.ListRepoWebhooks(w.Repository)
+	if err != nil {
+		return false, err
+	}
+	for _, hook := range hooks {
+		if url, ok := hook.Config["url"].(string); ok && strings.Contains(url, w.URLSubstring) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GitLabWebhook is a webhook in a GitLab project whose URL contains URLSubstring (e.g. the cluster domain)
+type GitLabWebhook struct {
+	Client       *gitlab.GitlabClient
+	ProjectID    string
+	URLSubstring string
+}
+
+func (w GitLabWebhook) String() string {
+	return fmt.Sprintf("webhook %s in GitLab project %s", w.URLSubstring, w.ProjectID)
+}
+
+func (w GitLabWebhook) Exists() (bool, error) {
+	hooks, err := w.Client.ListProjectWebhooks(w.ProjectID)
+	if err != nil {
+		return false, err
+	}
+	for _, hook := range hooks {
+		if strings.Contains(hook.URL, w.URLSubstring) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeletionRecord is the set of resources which should be gone after deletion of a Component or an Application
+type DeletionRecord struct {
+	Owner     TrackedResource
+	Resources []TrackedResource
+	// Shared are resources also owned by something which is not deleted, they are not verified
+	Shared   []TrackedResource
+	External []ExternalResource
+}
+
+// DeletionReport lists resources which were not garbage-collected
+type DeletionReport struct {
+	Owner          TrackedResource
+	Leaked         []TrackedResource
+	LeakedExternal []string
+}
+
+// HasLeaks returns true when anything was not garbage-collected
+func (r *DeletionReport) HasLeaks() bool {
+	return len(r.Leaked) > 0 || len(r.LeakedExternal) > 0
+}
+
+func (r *DeletionReport) String() string {
+	if !r.HasLeaks() {
+		return fmt.Sprintf("all resources of %s were deleted", r.Owner)
+	}
+	var leaked []string
+	for _, l := range r.Leaked {
+		leaked = append(leaked, l.String())
+	}
+	leaked = append(leaked, r.LeakedExternal...)
+	return fmt.Sprintf("resources of %s were not garbage-collected: %s", r.Owner, strings.Join(leaked, ", "))
+}
+
+// RecordDependents records resources in the owner's namespace of DependentKinds which are owned (directly or transitively)
+// by the owner, or labelled for any of the components
+func RecordDependents(ctx context.Context, c rclient.Client, owner TrackedResource, components []string, external ...ExternalResource) (*DeletionRecord, error) {
+	var objs []unstructured.Unstructured
+	for _, gvk := range DependentKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := c.List(ctx, list, rclient.InNamespace(owner.Namespace)); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list %s in %s namespace: %+v", gvk.Kind, owner.Namespace, err)
+		}
+		objs = append(objs, list.Items...)
+	}
+
+	componentSet := map[string]bool{}
+	for _, name := range components {
+		componentSet[name] = true
+	}
+	labelled := func(obj *unstructured.Unstructured) bool {
+		for _, l := range componentLabels {
+			if v := obj.GetLabels()[l]; v != "" && componentSet[v] {
+				return true
+			}
+		}
+		return false
+	}
+
+	// candidates are resources owned (transitively) by the owner or labelled for the components,
+	// ownership chains are short (Component -> ImageRepository -> Secret), so iterate until no new resource is found
+	candidates := map[types.UID]bool{owner.UID: true}
+	for found := true; found; {
+		found = false
+		for i := range objs {
+			obj := &objs[i]
+			if candidates[obj.GetUID()] {
+				continue
+			}
+			if labelled(obj) {
+				candidates[obj.GetUID()], found = true, true
+				continue
+			}
+			for _, ref := range obj.GetOwnerReferences() {
+				if candidates[ref.UID] {
+					candidates[obj.GetUID()], found = true, true
+					break
+				}
+			}
+		}
+	}
+	// resources also owned by something which is not deleted are kept alive by the garbage collector, as well as their dependents
+	shared := map[types.UID]bool{}
+	for found := true; found; {
+		found = false
+		for i := range objs {
+			obj := &objs[i]
+			if !candidates[obj.GetUID()] || obj.GetUID() == owner.UID || labelled(obj) {
+				continue
+			}
+			for _, ref := range obj.GetOwnerReferences() {
+				if !candidates[ref.UID] {
+					delete(candidates, obj.GetUID())
+					shared[obj.GetUID()], found = true, true
+					break
+				}
+			}
+		}
+	}
+
+	record := &DeletionRecord{Owner: owner, External: external}
+	for i := range objs {
+		obj := &objs[i]
+		r := TrackedResource{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName(), UID: obj.GetUID(), gvk: obj.GroupVersionKind()}
+		switch {
+		case obj.GetUID() == owner.UID:
+		case candidates[obj.GetUID()]:
+			record.Resources = append(record.Resources, r)
+		case shared[obj.GetUID()]:
+			record.Shared = append(record.Shared, r)
+		}
+	}
+	sort.Slice(record.Resources, func(i, j int) bool { return record.Resources[i].String() < record.Resources[j].String() })
+	sort.Slice(record.Shared, func(i, j int) bool { return record.Shared[i].String() < record.Shared[j].String() })
+	return record, nil
+}
+
+// CheckLeaks returns the recorded resources which still exist. A resource recreated with the same name (a different UID) is not a leak.
+func CheckLeaks(ctx context.Context, c rclient.Client, record *DeletionRecord) (*DeletionReport, error) {
+	report := &DeletionReport{Owner: record.Owner}
+	for _, r := range record.Resources {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(r.gvk)
+		if err := c.Get(ctx, types.NamespacedName{Namespace: r.Namespace, Name: r.Name}, obj); err != nil {
+			if k8sErrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get %s: %+v", r, err)
+		}
+		if obj.GetUID() == r.UID {
+			report.Leaked = append(report.Leaked, r)
+		}
+	}
+	for _, e := range record.External {
+		exists, err := e.Exists()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s: %+v", e, err)
+		}
+		if exists {
+			report.LeakedExternal = append(report.LeakedExternal, e.String())
+		}
+	}
+	return report, nil
+}
+
+// RemoveE2ETestFinalizers removes the e2e-test finalizer (added by tests to keep PipelineRuns around for assertions)
+// from the recorded PipelineRuns, so they can be garbage-collected
+func RemoveE2ETestFinalizers(ctx context.Context, c rclient.Client, record *DeletionRecord) error {
+	for _, r := range record.Resources {
+		if r.Kind != "PipelineRun" {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(r.gvk)
+		if err := c.Get(ctx, types.NamespacedName{Namespace: r.Namespace, Name: r.Name}, obj); err != nil {
+			if k8sErrors.IsNotFound(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get %s: %+v", r, err)
+		}
+		if obj.GetUID() != r.UID || !controllerutil.RemoveFinalizer(obj, constants.E2ETestFinalizerName) {
+			continue
+		}
+		if err := c.Update(ctx, obj); err != nil && !k8sErrors.IsNotFound(err) {
+			return fmt.Errorf("failed to remove %s finalizer from %s: %+v", constants.E2ETestFinalizerName, r, err)
+		}
+	}
+	return nil
+}
+
+// RecordComponentDependents records everything owned by or labelled for the Component: PipelineRuns, ImageRepository and its Secrets,
+// PaC Repository, ... The Quay repository of the Component is recorded as well, when it was generated by image-controller.
+func (h *HasController) RecordComponentDependents(component *appservice.Component, external ...ExternalResource) (*DeletionRecord, error) {
+	if imageName, err := build.GetQuayImageName(component.GetAnnotations()); err == nil && imageName != "" {
+		external = append(external, QuayRepository{Name: imageName})
+	}
+	owner := TrackedResource{Kind: "Component", Namespace: component.GetNamespace(), Name: component.GetName(), UID: component.GetUID()}
+	return RecordDependents(context.Background(), h.KubeRest(), owner, []string{component.GetName()}, external...)
+}
+
+// RecordApplicationDependents records the Components of the Application and everything owned by or labelled for them
+func (h *HasController) RecordApplicationDependents(application *appservice.Application, external ...ExternalResource) (*DeletionRecord, error) {
+	components := &appservice.ComponentList{}
+	if err := h.KubeRest().List(context.Background(), components, rclient.InNamespace(application.GetNamespace())); err != nil {
+		return nil, fmt.Errorf("failed to list components in %s namespace: %+v", application.GetNamespace(), err)
+	}
+	var names []string
+	for _, c := range components.Items {
+		if c.Spec.Application != application.GetName() {
+			continue
+		}
+		names = append(names, c.GetName())
+		if imageName, err := build.GetQuayImageName(c.GetAnnotations()); err == nil && imageName != "" {
+			external = append(external, QuayRepository{Name: imageName})
+		}
+	}
+	owner := TrackedResource{Kind: "Application", Namespace: application.GetNamespace(), Name: application.GetName(), UID: application.GetUID()}
+	record, err := RecordDependents(context.Background(), h.KubeRest(), owner, names, external...)
+	if err != nil {
+		return nil, err
+	}
+	// PipelineRuns are labelled with the application as well
+	pipelineRuns := &unstructured.UnstructuredList{}
+	pipelineRuns.SetGroupVersionKind(schema.GroupVersionKind{Group: "tekton.dev", Version: "v1", Kind: "PipelineRunList"})
+	selector := labels.SelectorFromSet(map[string]string{"appstudio.openshift.io/application": application.GetName()})
+	if err := h.KubeRest().List(context.Background(), pipelineRuns, rclient.InNamespace(application.GetNamespace()), rclient.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list PipelineRuns of application %s: %+v", application.GetName(), err)
+	}
+	for _, pr := range pipelineRuns.Items {
+		r := TrackedResource{Kind: pr.GetKind(), Namespace: pr.GetNamespace(), Name: pr.GetName(), UID: pr.GetUID(), gvk: pr.GroupVersionKind()}
+		if !containsResource(record.Resources, r) {
+			record.Resources = append(record.Resources, r)
+		}
+	}
+	return record, nil
+}
+
+// VerifyDeletion waits for the recorded resources to be garbage-collected. When resources still exist after the timeout,
+// it returns their report together with an error listing them. The e2e-test finalizer is removed from the recorded
+// PipelineRuns, it would keep them from being garbage-collected.
+func (h *HasController) VerifyDeletion(record *DeletionRecord, timeout time.Duration) (*DeletionReport, error) {
+	var report *DeletionReport
+	var checkErr error
+	err := wait.PollUntilContextTimeout(context.Background(), time.Second*10, timeout, true, func(ctx context.Context) (done bool, err error) {
+		if checkErr = RemoveE2ETestFinalizers(ctx, h.KubeRest(), record); checkErr != nil {
+			return false, nil
+		}
+		report, checkErr = CheckLeaks(ctx, h.KubeRest(), record)
+		return checkErr == nil && !report.HasLeaks(), nil
+	})
+	if err != nil {
+		if report == nil {
+			return nil, fmt.Errorf("failed to verify deletion of %s: %+v (last error: %+v)", record.Owner, err, checkErr)
+		}
+		if checkErr != nil {
+			return report, fmt.Errorf("%s after %s (last error: %+v): %+v", report, timeout, checkErr, err)
+		}
+		return report, fmt.Errorf("%s after %s: %+v", report, timeout, err)
+	}
+	return report, nil
+}
+
+// DeleteComponentAndVerify deletes the Component and returns an error listing its dependent resources which were not garbage-collected
+func (h *HasController) DeleteComponentAndVerify(component *appservice.Component, timeout time.Duration, external ...ExternalResource) error {
+	record, err := h.RecordComponentDependents(component, external...)
+	if err != nil {
+		return err
+	}
+	if err := h.DeleteComponent(component.GetName(), component.GetNamespace(), true); err != nil {
+		return err
+	}
+	return reportToError(h.VerifyDeletion(record, timeout))
+}
+
+// DeleteApplicationAndVerify deletes the Application and returns an error listing its dependent resources which were not garbage-collected
+func (h *HasController) DeleteApplicationAndVerify(application *appservice.Application, timeout time.Duration, external ...ExternalResource) error {
+	record, err := h.RecordApplicationDependents(application, external...)
+	if err != nil {
+		return err
+	}
+	if err := h.DeleteApplication(application.GetName(), application.GetNamespace(), true); err != nil {
+		return err
+	}
+	return reportToError(h.VerifyDeletion(record, timeout))
+}
+
+func reportToError(report *DeletionReport, err error) error {
+	if err != nil {
+		return err
+	}
+	if report.HasLeaks() {
+		return fmt.Errorf("%s", report)
+	}
+	return nil
+}
+
+func containsResource(resources []TrackedResource, r TrackedResource) bool {
+	for _, res := range resources {
+		if res.UID == r.UID {
+			return true
+		}
+	}
+	return false
+}
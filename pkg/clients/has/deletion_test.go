@@ -0,0 +1,93 @@
+package has
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func object(apiVersion, kind, name, uid string, labels map[string]string, owners ...string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace("tenant")
+	obj.SetName(name)
+	obj.SetUID(types.UID(uid))
+	obj.SetLabels(labels)
+	var refs []metav1.OwnerReference
+	for _, owner := range owners {
+		refs = append(refs, metav1.OwnerReference{APIVersion: "v1", Kind: "Owner", Name: owner, UID: types.UID(owner)})
+	}
+	obj.SetOwnerReferences(refs)
+	return obj
+}
+
+type fakeExternal struct {
+	exists bool
+}
+
+func (f fakeExternal) String() string        { return "external repository" }
+func (f fakeExternal) Exists() (bool, error) { return f.exists, nil }
+
+func TestRecordDependents(t *testing.T) {
+	imageRepository := object("appstudio.redhat.com/v1alpha1", "ImageRepository", "imagerepository-for-comp", "imagerepo", nil, "comp")
+	pushSecret := object("v1", "Secret", "comp-push", "push-secret", nil, "imagerepo")
+	pipelineRun := object("tekton.dev/v1", "PipelineRun", "comp-on-push-abcde", "pr", map[string]string{"appstudio.openshift.io/component": "comp"})
+	sharedRepository := object("pipelinesascode.tekton.dev/v1alpha1", "Repository", "repo", "pac-repo", nil, "comp", "other-comp")
+	otherSecret := object("v1", "Secret", "other-push", "other-secret", nil, "other-imagerepo")
+	c := fake.NewClientBuilder().WithObjects(imageRepository, pushSecret, pipelineRun, sharedRepository, otherSecret).Build()
+	ctx := context.Background()
+
+	owner := TrackedResource{Kind: "Component", Namespace: "tenant", Name: "comp", UID: "comp"}
+	record, err := RecordDependents(ctx, c, owner, []string{"comp"}, fakeExternal{exists: true})
+	assert.NoError(t, err)
+	names := func(resources []TrackedResource) []string {
+		var res []string
+		for _, r := range resources {
+			res = append(res, r.String())
+		}
+		return res
+	}
+	assert.Equal(t, []string{
+		"ImageRepository tenant/imagerepository-for-comp",
+		"PipelineRun tenant/comp-on-push-abcde",
+		"Secret tenant/comp-push",
+	}, names(record.Resources))
+	assert.Equal(t, []string{"Repository tenant/repo"}, names(record.Shared))
+
+	// the garbage collector removed everything except the push secret
+	assert.NoError(t, c.Delete(ctx, imageRepository))
+	assert.NoError(t, c.Delete(ctx, pipelineRun))
+	report, err := CheckLeaks(ctx, c, record)
+	assert.NoError(t, err)
+	assert.True(t, report.HasLeaks())
+	assert.Equal(t, "resources of Component tenant/comp were not garbage-collected: Secret tenant/comp-push, external repository", report.String())
+
+	// a secret recreated with the same name is not a leak
+	assert.NoError(t, c.Delete(ctx, pushSecret))
+	assert.NoError(t, c.Create(ctx, object("v1", "Secret", "comp-push", "new-push-secret", nil)))
+	record.External = nil
+	report, err = CheckLeaks(ctx, c, record)
+	assert.NoError(t, err)
+	assert.False(t, report.HasLeaks())
+}
+
+func TestRemoveE2ETestFinalizers(t *testing.T) {
+	pipelineRun := object("tekton.dev/v1", "PipelineRun", "comp-on-push-abcde", "pr", map[string]string{"appstudio.openshift.io/component": "comp"})
+	pipelineRun.SetFinalizers([]string{"e2e-test", "chains.tekton.dev/pipelinerun"})
+	c := fake.NewClientBuilder().WithObjects(pipelineRun).Build()
+	ctx := context.Background()
+
+	owner := TrackedResource{Kind: "Component", Namespace: "tenant", Name: "comp", UID: "comp"}
+	record, err := RecordDependents(ctx, c, owner, []string{"comp"})
+	assert.NoError(t, err)
+	assert.NoError(t, RemoveE2ETestFinalizers(ctx, c, record))
+
+	assert.NoError(t, c.Get(ctx, types.NamespacedName{Namespace: "tenant", Name: "comp-on-push-abcde"}, pipelineRun))
+	assert.Equal(t, []string{"chains.tekton.dev/pipelinerun"}, pipelineRun.GetFinalizers())
+}
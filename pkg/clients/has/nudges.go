@@ -0,0 +1,150 @@
+package has
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/konflux-ci/e2e-tests/pkg/clients/github"
+	. "github.com/onsi/ginkgo/v2"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// NudgeRepository is the git repository of a nudged component, where build-service opens PRs
+// updating references to images built for the nudging component
+type NudgeRepository interface {
+	// FindNudgePullRequest returns the number of an open PR whose source branch was created for the nudging component, 0 if there's none
+	FindNudgePullRequest(nudgingComponent string) (int, error)
+	// MergeNudgePullRequest merges the PR and returns SHA of the merge commit
+	MergeNudgePullRequest(number int) (string, error)
+	// GetFileContent returns content of the file in the branch
+	GetFileContent(path, branch string) (string, error)
+}
+
+// GitHubNudgeRepository is a NudgeRepository hosted on GitHub
+type GitHubNudgeRepository struct {
+	Client     *github.Github
+	Repository string
+}
+
+func (r *GitHubNudgeRepository) FindNudgePullRequest(nudgingComponent string) (int, error) {
+	prs, err := r.Client.ListPullRequests(r.Repository)
+	if err != nil {
+		return 0, err
+	}
+	for _, pr := range prs {
+		if strings.Contains(pr.Head.GetRef(), nudgingComponent) {
+			return pr.GetNumber(), nil
+		}
+	}
+	return 0, nil
+}
+
+func (r *GitHubNudgeRepository) MergeNudgePullRequest(number int) (string, error) {
+	mergeResult, err := r.Client.MergePullRequest(r.Repository, number)
+	if err != nil {
+		return "", err
+	}
+	return mergeResult.GetSHA(), nil
+}
+
+func (r *GitHubNudgeRepository) GetFileContent(path, branch string) (string, error) {
+	file, err := r.Client.GetFile(r.Repository, path, branch)
+	if err != nil {
+		return "", err
+	}
+	return file.GetContent()
+}
+
+// WaitForComponentNudgedBy waits until build-service records the nudge relationship in the status of the nudged component
+func (h *HasController) WaitForComponentNudgedBy(nudgedComponent, nudgingComponent, namespace string, timeout time.Duration) error {
+	return wait.PollUntilContextTimeout(context.Background(), time.Second*5, timeout, true, func(ctx context.Context) (done bool, err error) {
+		component, err := h.GetComponent(nudgedComponent, namespace)
+		if err != nil {
+			GinkgoWriter.Printf("failed to get component %s: %+v\n", nudgedComponent, err)
+			return false, nil
+		}
+		for _, c := range component.Status.BuildNudgedBy {
+			if c == nudgingComponent {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+// WaitForNudgePullRequest waits for build-service to open a PR nudging the repository with images of the nudging component
+// and returns its number
+func (h *HasController) WaitForNudgePullRequest(repository NudgeRepository, nudgingComponent string, timeout time.Duration) (int, error) {
+	var number int
+	err := wait.PollUntilContextTimeout(context.Background(), time.Second*5, timeout, true, func(ctx context.Context) (done bool, err error) {
+		number, err = repository.FindNudgePullRequest(nudgingComponent)
+		if err != nil {
+			GinkgoWriter.Printf("failed to list pull requests: %+v\n", err)
+			return false, nil
+		}
+		return number != 0, nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("timed out when waiting for a nudge pull request from component %s: %+v", nudgingComponent, err)
+	}
+	return number, nil
+}
+
+// MergeNudgePullRequest merges the nudge PR (retrying while the git provider is not ready to merge it) and returns the merge commit SHA
+func (h *HasController) MergeNudgePullRequest(repository NudgeRepository, number int, timeout time.Duration) (string, error) {
+	var sha string
+	var mergeErr error
+	err := wait.PollUntilContextTimeout(context.Background(), time.Second*5, timeout, true, func(ctx context.Context) (done bool, err error) {
+		sha, mergeErr = repository.MergeNudgePullRequest(number)
+		return mergeErr == nil, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to merge nudge pull request #%d: %+v", number, mergeErr)
+	}
+	return sha, nil
+}
+
+// VerifyNudgedImageReferences checks that files in the branch of the nudged repository reference the images only by the digest.
+// The references map paths of the files to the image repositories (without tags or digests) referenced in them.
+func (h *HasController) VerifyNudgedImageReferences(repository NudgeRepository, branch, digest string, references map[string][]string) error {
+	for path, images := range references {
+		content, err := repository.GetFileContent(path, branch)
+		if err != nil {
+			return fmt.Errorf("failed to get file %s from branch %s: %+v", path, branch, err)
+		}
+		if err := checkImageReferences(content, images, digest); err != nil {
+			return fmt.Errorf("file %s was not nudged correctly: %+v", path, err)
+		}
+	}
+	return nil
+}
+
+// checkImageReferences verifies that the images are referenced in the content and every reference of them pins the digest,
+// a reference by a tag only fails the check
+func checkImageReferences(content string, images []string, digest string) error {
+	for _, image := range images {
+		found := false
+		for _, m := range regexp.MustCompile(regexp.QuoteMeta(image)+`([^\s"',]*)`).FindAllStringSubmatch(content, -1) {
+			reference := m[1]
+			if reference != "" && reference[0] != ':' && reference[0] != '@' {
+				// another image with the same prefix
+				continue
+			}
+			found = true
+			_, pinned, ok := strings.Cut(reference, "@")
+			if !ok || !strings.HasPrefix(pinned, "sha256:") {
+				return fmt.Errorf("image %s is not referenced by a digest in %s", image, m[0])
+			}
+			if pinned != digest {
+				return fmt.Errorf("image %s is referenced by digest %s instead of %s", image, pinned, digest)
+			}
+		}
+		if !found {
+			return fmt.Errorf("image %s is not referenced", image)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package has
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckImageReferences(t *testing.T) {
+	oldDigest, newDigest := "sha256:"+strings.Repeat("a", 64), "sha256:"+strings.Repeat("b", 64)
+	images := []string{"quay.io/org/parent", "quay.io/org/release"}
+
+	content := "FROM quay.io/org/parent@" + newDigest + "\nimage: quay.io/org/release@" + newDigest
+	assert.NoError(t, checkImageReferences(content, images, newDigest))
+
+	content = "FROM quay.io/org/parent@" + newDigest + "\nimage: quay.io/org/release@" + oldDigest
+	assert.ErrorContains(t, checkImageReferences(content, images, newDigest), "image quay.io/org/release is referenced by digest "+oldDigest)
+
+	assert.ErrorContains(t, checkImageReferences("FROM quay.io/org/parent:latest", images[:1], newDigest), "not referenced by a digest")
+	assert.ErrorContains(t, checkImageReferences("FROM quay.io/org/parent@"+newDigest+"\nFROM quay.io/org/parent:latest", images[:1], newDigest), "not referenced by a digest in quay.io/org/parent:latest")
+	assert.NoError(t, checkImageReferences("FROM quay.io/org/parent:v1@"+newDigest+"\nFROM quay.io/org/parent-base:latest", images[:1], newDigest))
+	assert.EqualError(t, checkImageReferences("FROM quay.io/org/parent-base@"+newDigest, images[:1], newDigest), "image quay.io/org/parent is not referenced")
+}
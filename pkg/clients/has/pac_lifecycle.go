@@ -0,0 +1,396 @@
+package has
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	gh "github.com/google/go-github/v44/github"
+	appservice "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/konflux-ci/e2e-tests/pkg/clients/github"
+	"github.com/konflux-ci/e2e-tests/pkg/clients/gitlab"
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	. "github.com/onsi/ginkgo/v2"
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	rclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	PaCEventTypePullRequest = "pull_request"
+	PaCEventTypePush        = "push"
+
+	pacDirectory = ".tekton"
+)
+
+// PaCPullRequest is a pull request (or a GitLab merge request) in a Component repository
+type PaCPullRequest struct {
+	Number       int
+	SourceBranch string
+	TargetBranch string
+	HeadSHA      string
+}
+
+// PaCGitProvider is the git provider hosting the repository of a Component onboarded to Pipelines as Code
+type PaCGitProvider interface {
+	// FindPullRequest returns an open pull request from the source branch, nil if there's none
+	FindPullRequest(sourceBranch string) (*PaCPullRequest, error)
+	// GetFiles returns contents of files in the directory of the branch, mapped by their paths
+	GetFiles(directory, branch string) (map[string]string, error)
+	// Comment adds a comment to the pull request
+	Comment(number int, body string) error
+	// CommitFile creates or updates the file in the branch and returns SHA of the commit
+	CommitFile(branch, path, content string) (string, error)
+	// Merge merges the pull request and returns SHA of the merge commit
+	Merge(number int) (string, error)
+}
+
+// GitHubPaCProvider is a PaCGitProvider for a GitHub repository
+type GitHubPaCProvider struct {
+	Client     *github.Github
+	Repository string
+}
+
+func (p *GitHubPaCProvider) FindPullRequest(sourceBranch string) (*PaCPullRequest, error) {
+	prs, err := p.Client.ListPullRequests(p.Repository)
+	if err != nil {
+		return nil, err
+	}
+	for _, pr := range prs {
+		if pr.Head.GetRef() == sourceBranch {
+			return &PaCPullRequest{Number: pr.GetNumber(), SourceBranch: sourceBranch, TargetBranch: pr.Base.GetRef(), HeadSHA: pr.Head.GetSHA()}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (p *GitHubPaCProvider) GetFiles(directory, branch string) (map[string]string, error) {
+	contents, err := p.Client.GetDirectoryContents(p.Repository, directory, branch)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]string{}
+	for _, c := range contents {
+		if c.GetType() != "file" {
+			continue
+		}
+		file, err := p.Client.GetFile(p.Repository, c.GetPath(), branch)
+		if err != nil {
+			return nil, err
+		}
+		if files[c.GetPath()], err = file.GetContent(); err != nil {
+			return nil, fmt.Errorf("failed to decode content of %s: %+v", c.GetPath(), err)
+		}
+	}
+	return files, nil
+}
+
+func (p *GitHubPaCProvider) Comment(number int, body string) error {
+	_, err := p.Client.CreatePullRequestComment(p.Repository, number, body)
+	return err
+}
+
+func (p *GitHubPaCProvider) CommitFile(branch, path, content string) (string, error) {
+	file, err := p.Client.GetFile(p.Repository, path, branch)
+	if err != nil {
+		if !isGitHubNotFound(err) {
+			return "", err
+		}
+		created, err := p.Client.CreateFile(p.Repository, path, content, branch)
+		if err != nil {
+			return "", err
+		}
+		return created.GetSHA(), nil
+	}
+	updated, err := p.Client.UpdateFile(p.Repository, path, content, branch, file.GetSHA())
+	if err != nil {
+		return "", err
+	}
+	return updated.GetSHA(), nil
+}
+
+// isGitHubNotFound returns true when the GitHub API responded to the request with 404
+func isGitHubNotFound(err error) bool {
+	var errResponse *gh.ErrorResponse
+	return errors.As(err, &errResponse) && errResponse.Response != nil && errResponse.Response.StatusCode == http.StatusNotFound
+}
+
+func (p *GitHubPaCProvider) Merge(number int) (string, error) {
+	result, err := p.Client.MergePullRequest(p.Repository, number)
+	if err != nil {
+		return "", err
+	}
+	return result.GetSHA(), nil
+}
+
+// GitLabPaCProvider is a PaCGitProvider for a GitLab project
+type GitLabPaCProvider struct {
+	Client    *gitlab.GitlabClient
+	ProjectID string
+}
+
+func (p *GitLabPaCProvider) FindPullRequest(sourceBranch string) (*PaCPullRequest, error) {
+	mrs, err := p.Client.ListOpenMergeRequests(p.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, mr := range mrs {
+		if mr.SourceBranch == sourceBranch {
+			return &PaCPullRequest{Number: mr.IID, SourceBranch: sourceBranch, TargetBranch: mr.TargetBranch, HeadSHA: mr.SHA}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (p *GitLabPaCProvider) GetFiles(directory, branch string) (map[string]string, error) {
+	paths, err := p.Client.ListFiles(p.ProjectID, directory, branch)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string]string{}
+	for _, path := range paths {
+		if files[path], err = p.Client.GetFileContent(p.ProjectID, path, branch); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func (p *GitLabPaCProvider) Comment(number int, body string) error {
+	return p.Client.CreateMergeRequestComment(p.ProjectID, number, body)
+}
+
+func (p *GitLabPaCProvider) CommitFile(branch, path, content string) (string, error) {
+	return p.Client.CommitFile(p.ProjectID, path, content, branch)
+}
+
+func (p *GitLabPaCProvider) Merge(number int) (string, error) {
+	mr, err := p.Client.AcceptMergeRequest(p.ProjectID, number)
+	if err != nil {
+		return "", err
+	}
+	if mr.MergeCommitSHA != "" {
+		return mr.MergeCommitSHA, nil
+	}
+	return mr.SHA, nil
+}
+
+// PaCLifecycle follows the Pipelines as Code onboarding of a Component: the onboarding pull request sent by build-service,
+// its PipelineRun, merging it and the push PipelineRun triggered by the merge
+type PaCLifecycle struct {
+	controller *HasController
+	component  *appservice.Component
+	provider   PaCGitProvider
+
+	// PullRequest is the onboarding pull request, HeadSHA is updated when a commit is pushed to it
+	PullRequest *PaCPullRequest
+	// MergeSHA is the SHA of the merge commit of the onboarding pull request
+	MergeSHA string
+	// Interval of polling the git provider
+	Interval time.Duration
+
+	// previousPipelineRuns are names of the PipelineRuns of the pull request which existed before the last trigger
+	previousPipelineRuns map[string]bool
+}
+
+// NewPaCLifecycle returns a helper following the PaC onboarding of the component in the repository hosted by the provider
+func (h *HasController) NewPaCLifecycle(component *appservice.Component, provider PaCGitProvider) *PaCLifecycle {
+	return &PaCLifecycle{controller: h, component: component, provider: provider, Interval: 5 * time.Second}
+}
+
+// PullRequestBranch is the branch of the onboarding pull request
+func (l *PaCLifecycle) PullRequestBranch() string {
+	return constants.PaCPullRequestBranchPrefix + l.component.GetName()
+}
+
+// WaitForPullRequest waits for build-service to send the onboarding pull request
+func (l *PaCLifecycle) WaitForPullRequest(timeout time.Duration) (*PaCPullRequest, error) {
+	err := wait.PollUntilContextTimeout(context.Background(), l.Interval, timeout, true, func(ctx context.Context) (done bool, err error) {
+		pr, err := l.provider.FindPullRequest(l.PullRequestBranch())
+		if err != nil {
+			GinkgoWriter.Printf("failed to list pull requests: %+v\n", err)
+			return false, nil
+		}
+		l.PullRequest = pr
+		return pr != nil, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("timed out when waiting for PaC pull request (branch %s) of component %s: %+v", l.PullRequestBranch(), l.component.GetName(), err)
+	}
+	return l.PullRequest, nil
+}
+
+// PipelineRunDefinitions returns PipelineRuns defined in the .tekton directory of the onboarding pull request
+func (l *PaCLifecycle) PipelineRunDefinitions() ([]*pipeline.PipelineRun, error) {
+	if l.PullRequest == nil {
+		return nil, fmt.Errorf("PaC pull request of component %s was not found yet", l.component.GetName())
+	}
+	files, err := l.provider.GetFiles(pacDirectory, l.PullRequest.SourceBranch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s directory from branch %s: %+v", pacDirectory, l.PullRequest.SourceBranch, err)
+	}
+	return ParsePipelineRunDefinitions(files)
+}
+
+// Retest triggers the pull request PipelineRun again with a /retest comment
+func (l *PaCLifecycle) Retest() error {
+	if l.PullRequest == nil {
+		return fmt.Errorf("PaC pull request of component %s was not found yet", l.component.GetName())
+	}
+	// the new PipelineRun has the same sha as the previous ones, so they must not be mistaken for it
+	previous, err := l.controller.listPaCPipelineRuns(context.Background(), l.component, PaCEventTypePullRequest, l.PullRequest.HeadSHA)
+	if err != nil {
+		return fmt.Errorf("failed to list PipelineRuns of PaC pull request #%d: %+v", l.PullRequest.Number, err)
+	}
+	l.previousPipelineRuns = map[string]bool{}
+	for _, pr := range previous {
+		l.previousPipelineRuns[pr.GetName()] = true
+	}
+	return l.provider.Comment(l.PullRequest.Number, "/retest")
+}
+
+// Push commits the file to the pull request branch, which triggers a new pull request PipelineRun
+func (l *PaCLifecycle) Push(path, content string) (string, error) {
+	if l.PullRequest == nil {
+		return "", fmt.Errorf("PaC pull request of component %s was not found yet", l.component.GetName())
+	}
+	sha, err := l.provider.CommitFile(l.PullRequest.SourceBranch, path, content)
+	if err != nil {
+		return "", err
+	}
+	l.PullRequest.HeadSHA = sha
+	l.previousPipelineRuns = nil
+	return sha, nil
+}
+
+// WaitForPullRequestPipelineRun waits for the PipelineRun of the head commit of the pull request to start
+func (l *PaCLifecycle) WaitForPullRequestPipelineRun(timeout time.Duration) (*pipeline.PipelineRun, error) {
+	if l.PullRequest == nil {
+		return nil, fmt.Errorf("PaC pull request of component %s was not found yet", l.component.GetName())
+	}
+	return l.controller.waitForPaCPipelineRun(l.component, PaCEventTypePullRequest, l.PullRequest.HeadSHA, l.previousPipelineRuns, timeout)
+}
+
+// Merge merges the pull request (retrying while the git provider is not ready to merge it)
+func (l *PaCLifecycle) Merge(timeout time.Duration) (string, error) {
+	if l.PullRequest == nil {
+		return "", fmt.Errorf("PaC pull request of component %s was not found yet", l.component.GetName())
+	}
+	var mergeErr error
+	err := wait.PollUntilContextTimeout(context.Background(), l.Interval, timeout, true, func(ctx context.Context) (done bool, err error) {
+		l.MergeSHA, mergeErr = l.provider.Merge(l.PullRequest.Number)
+		return mergeErr == nil, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to merge PaC pull request #%d: %+v", l.PullRequest.Number, mergeErr)
+	}
+	GinkgoWriter.Printf("merged result sha: %s for PR #%d\n", l.MergeSHA, l.PullRequest.Number)
+	return l.MergeSHA, nil
+}
+
+// WaitForPushPipelineRun waits for the PipelineRun triggered by merging the pull request to start
+func (l *PaCLifecycle) WaitForPushPipelineRun(timeout time.Duration) (*pipeline.PipelineRun, error) {
+	if l.MergeSHA == "" {
+		return nil, fmt.Errorf("PaC pull request of component %s was not merged yet", l.component.GetName())
+	}
+	return l.controller.waitForPaCPipelineRun(l.component, PaCEventTypePush, l.MergeSHA, nil, timeout)
+}
+
+// listPaCPipelineRuns returns PipelineRuns of the component triggered by the event for the commit
+func (h *HasController) listPaCPipelineRuns(ctx context.Context, component *appservice.Component, eventType, sha string) ([]pipeline.PipelineRun, error) {
+	selector := labels.SelectorFromSet(map[string]string{
+		"appstudio.openshift.io/component":      component.GetName(),
+		"appstudio.openshift.io/application":    component.Spec.Application,
+		"pipelinesascode.tekton.dev/event-type": eventType,
+		"pipelinesascode.tekton.dev/sha":        sha,
+	})
+	list := &pipeline.PipelineRunList{}
+	if err := h.KubeRest().List(ctx, list, &rclient.ListOptions{LabelSelector: selector, Namespace: component.GetNamespace()}); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// waitForPaCPipelineRun waits for the newest PipelineRun of the component triggered by the event for the commit to start,
+// PipelineRuns named in previous (created before the event was triggered again) are skipped
+func (h *HasController) waitForPaCPipelineRun(component *appservice.Component, eventType, sha string, previous map[string]bool, timeout time.Duration) (*pipeline.PipelineRun, error) {
+	var pr *pipeline.PipelineRun
+	err := wait.PollUntilContextTimeout(context.Background(), constants.PipelineRunPollingInterval, timeout, true, func(ctx context.Context) (done bool, err error) {
+		items, err := h.listPaCPipelineRuns(ctx, component, eventType, sha)
+		if err == nil {
+			pr = newestPipelineRun(items, previous)
+		}
+		if pr == nil {
+			GinkgoWriter.Printf("%s PipelineRun has not been created yet for the component %s/%s\n", eventType, component.GetNamespace(), component.GetName())
+			return false, nil
+		}
+		return pr.HasStarted(), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("timed out when waiting for the %s PipelineRun of the component %s/%s with sha %s to start", eventType, component.GetNamespace(), component.GetName(), sha)
+	}
+	return pr, nil
+}
+
+// newestPipelineRun returns the most recently created PipelineRun which is not named in skip, nil if there's none
+func newestPipelineRun(pipelineRuns []pipeline.PipelineRun, skip map[string]bool) *pipeline.PipelineRun {
+	var newest *pipeline.PipelineRun
+	for i := range pipelineRuns {
+		pr := &pipelineRuns[i]
+		if skip[pr.GetName()] {
+			continue
+		}
+		if newest == nil || newest.CreationTimestamp.Before(&pr.CreationTimestamp) {
+			newest = pr
+		}
+	}
+	return newest
+}
+
+// ParsePipelineRunDefinitions parses PipelineRuns from the YAML files (which can contain multiple documents), other kinds are skipped
+func ParsePipelineRunDefinitions(files map[string]string) ([]*pipeline.PipelineRun, error) {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		if ext := path.Ext(p); ext == ".yaml" || ext == ".yml" {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+
+	var pipelineRuns []*pipeline.PipelineRun
+	for _, p := range paths {
+		reader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(files[p])))
+		for {
+			doc, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %+v", p, err)
+			}
+			if len(bytes.TrimSpace(doc)) == 0 {
+				continue
+			}
+			pr := &pipeline.PipelineRun{}
+			if err := yaml.Unmarshal(doc, pr); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %+v", p, err)
+			}
+			if pr.Kind != "PipelineRun" {
+				continue
+			}
+			pipelineRuns = append(pipelineRuns, pr)
+		}
+	}
+	return pipelineRuns, nil
+}
@@ -0,0 +1,70 @@
+package has
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	gh "github.com/google/go-github/v44/github"
+	"github.com/stretchr/testify/assert"
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParsePipelineRunDefinitions(t *testing.T) {
+	files := map[string]string{
+		".tekton/comp-push.yaml": `apiVersion: tekton.dev/v1
+kind: PipelineRun
+metadata:
+  name: comp-on-push
+  annotations:
+    pipelinesascode.tekton.dev/on-event: "[push]"
+`,
+		".tekton/comp-pull-request.yaml": `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-pipelinerun
+---
+apiVersion: tekton.dev/v1
+kind: PipelineRun
+metadata:
+  name: comp-on-pull-request
+`,
+		".tekton/README.md": "kind: PipelineRun",
+	}
+
+	pipelineRuns, err := ParsePipelineRunDefinitions(files)
+	assert.NoError(t, err)
+	var names []string
+	for _, pr := range pipelineRuns {
+		names = append(names, pr.GetName())
+	}
+	assert.Equal(t, []string{"comp-on-pull-request", "comp-on-push"}, names)
+	assert.Equal(t, "[push]", pipelineRuns[1].GetAnnotations()["pipelinesascode.tekton.dev/on-event"])
+
+	_, err = ParsePipelineRunDefinitions(map[string]string{".tekton/broken.yaml": "kind: [PipelineRun"})
+	assert.Error(t, err)
+}
+
+func TestNewestPipelineRun(t *testing.T) {
+	created := func(name string, minutes int) pipeline.PipelineRun {
+		pr := pipeline.PipelineRun{}
+		pr.Name = name
+		pr.CreationTimestamp = metav1.NewTime(time.Date(2024, 1, 1, 0, minutes, 0, 0, time.UTC))
+		return pr
+	}
+	pipelineRuns := []pipeline.PipelineRun{created("first", 0), created("retest", 10), created("second", 5)}
+
+	assert.Equal(t, "retest", newestPipelineRun(pipelineRuns, nil).GetName())
+	assert.Equal(t, "second", newestPipelineRun(pipelineRuns, map[string]bool{"retest": true}).GetName())
+	assert.Nil(t, newestPipelineRun(pipelineRuns[:1], map[string]bool{"first": true}))
+}
+
+func TestIsGitHubNotFound(t *testing.T) {
+	notFound := &gh.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}, Message: "Not Found"}
+	assert.True(t, isGitHubNotFound(fmt.Errorf("error when listing file contents: %w", notFound)))
+	assert.False(t, isGitHubNotFound(&gh.ErrorResponse{Response: &http.Response{StatusCode: http.StatusForbidden}, Message: "404 Not Found"}))
+	assert.False(t, isGitHubNotFound(fmt.Errorf("404 Not Found")))
+}
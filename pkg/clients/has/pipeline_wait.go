@@ -0,0 +1,266 @@
+package has
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appservice "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/konflux-ci/e2e-tests/pkg/clients/tekton"
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	. "github.com/onsi/ginkgo/v2"
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	"knative.dev/pkg/apis"
+)
+
+// ComponentPipelineTimeout is how long a single attempt of a component PipelineRun can take
+const ComponentPipelineTimeout = 30 * time.Minute
+
+// DefaultRetryReasons are PipelineRun failure reasons caused by known infrastructure issues:
+// CouldntGetTask - https://issues.redhat.com/browse/SRVKP-2749
+// TaskRunImagePullFailed - https://issues.redhat.com/browse/RHTAPBUGS-985 and https://github.com/tektoncd/pipeline/issues/7184
+var DefaultRetryReasons = []string{"CouldntGetTask", "TaskRunImagePullFailed"}
+
+// RetryPolicy decides whether a failed component PipelineRun is re-triggered
+type RetryPolicy interface {
+	// ShouldRetry is called with the number of the failed attempt (starting from 1) and the failed PipelineRun
+	ShouldRetry(attempt int, pr *pipeline.PipelineRun) bool
+}
+
+// ReasonRetryPolicy re-triggers PipelineRuns which failed with one of the Reasons (or for any reason if Reasons are empty)
+// until MaxAttempts is reached
+type ReasonRetryPolicy struct {
+	MaxAttempts int
+	Reasons     []string
+}
+
+func (p ReasonRetryPolicy) ShouldRetry(attempt int, pr *pipeline.PipelineRun) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	if len(p.Reasons) == 0 {
+		return true
+	}
+	reason := pr.GetStatusCondition().GetCondition(apis.ConditionSucceeded).GetReason()
+	for _, r := range p.Reasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// ShouldRetry makes RetryOptions a RetryPolicy, a nil RetryOptions never retries
+func (r *RetryOptions) ShouldRetry(attempt int, pr *pipeline.PipelineRun) bool {
+	if r == nil {
+		return false
+	}
+	policy := ReasonRetryPolicy{MaxAttempts: r.Retries + 1, Reasons: DefaultRetryReasons}
+	if r.Always {
+		policy.Reasons = nil
+	}
+	return policy.ShouldRetry(attempt, pr)
+}
+
+// FailedTaskRun is a TaskRun which did not succeed
+type FailedTaskRun struct {
+	Name             string
+	PipelineTaskName string
+	Reason           string
+	// StepExitCodes maps names of terminated steps to their exit codes
+	StepExitCodes map[string]int32
+}
+
+// PipelineRunAttempt is a single run of a component pipeline
+type PipelineRunAttempt struct {
+	PipelineRun    string
+	Succeeded      bool
+	Reason         string
+	Message        string
+	Duration       time.Duration
+	FailedTaskRuns []FailedTaskRun
+}
+
+// PipelineRunResult is the outcome of waiting for a component pipeline, including re-triggered attempts
+type PipelineRunResult struct {
+	// PipelineRun is the last PipelineRun, nil if it was not created
+	PipelineRun *pipeline.PipelineRun
+	Attempts    []PipelineRunAttempt
+}
+
+// Succeeded returns true when the last attempt succeeded
+func (r *PipelineRunResult) Succeeded() bool {
+	return len(r.Attempts) > 0 && r.Attempts[len(r.Attempts)-1].Succeeded
+}
+
+// FailedTaskRuns returns the failed TaskRuns of the last attempt
+func (r *PipelineRunResult) FailedTaskRuns() []FailedTaskRun {
+	if len(r.Attempts) == 0 {
+		return nil
+	}
+	return r.Attempts[len(r.Attempts)-1].FailedTaskRuns
+}
+
+// String describes all attempts, e.g. for assertion messages
+func (r *PipelineRunResult) String() string {
+	var sb strings.Builder
+	for i, a := range r.Attempts {
+		fmt.Fprintf(&sb, "attempt %d: PipelineRun %s finished in %s with reason %s", i+1, a.PipelineRun, a.Duration.Round(time.Second), a.Reason)
+		for _, tr := range a.FailedTaskRuns {
+			fmt.Fprintf(&sb, ", TaskRun %s (%s) failed with reason %s", tr.Name, tr.PipelineTaskName, tr.Reason)
+			steps := make([]string, 0, len(tr.StepExitCodes))
+			for step := range tr.StepExitCodes {
+				steps = append(steps, step)
+			}
+			sort.Strings(steps)
+			for _, step := range steps {
+				if code := tr.StepExitCodes[step]; code != 0 {
+					fmt.Fprintf(&sb, ", step %s exited with %d", step, code)
+				}
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// newPipelineRunAttempt summarises the finished PipelineRun and its TaskRuns
+func newPipelineRunAttempt(pr *pipeline.PipelineRun, taskRuns []pipeline.TaskRun) PipelineRunAttempt {
+	condition := pr.GetStatusCondition().GetCondition(apis.ConditionSucceeded)
+	attempt := PipelineRunAttempt{
+		PipelineRun: pr.GetName(),
+		Succeeded:   condition.IsTrue(),
+		Reason:      condition.GetReason(),
+		Message:     condition.GetMessage(),
+	}
+	if pr.Status.StartTime != nil && pr.Status.CompletionTime != nil {
+		attempt.Duration = pr.Status.CompletionTime.Sub(pr.Status.StartTime.Time)
+	}
+	for _, tr := range taskRuns {
+		trCondition := tr.Status.GetCondition(apis.ConditionSucceeded)
+		if trCondition.IsTrue() {
+			continue
+		}
+		failed := FailedTaskRun{
+			Name:             tr.GetName(),
+			PipelineTaskName: tr.GetLabels()["tekton.dev/pipelineTask"],
+			Reason:           trCondition.GetReason(),
+			StepExitCodes:    map[string]int32{},
+		}
+		for _, step := range tr.Status.Steps {
+			if step.Terminated != nil {
+				failed.StepExitCodes[step.Name] = step.Terminated.ExitCode
+			}
+		}
+		attempt.FailedTaskRuns = append(attempt.FailedTaskRuns, failed)
+	}
+	return attempt
+}
+
+// WaitForComponentPipeline waits for a component PipelineRun to be finished by watching PipelineRuns of the component.
+// A failed PipelineRun is deleted and re-triggered when the policy allows it. The returned result contains all attempts
+// and is not nil even if an error is returned.
+func (h *HasController) WaitForComponentPipeline(component *appservice.Component, sha string, t *tekton.TektonController, policy RetryPolicy) (*PipelineRunResult, error) {
+	result := &PipelineRunResult{}
+	previous := map[string]bool{}
+
+	for attempt := 1; ; attempt++ {
+		pr, err := h.watchComponentPipelineRun(component, sha, previous)
+		if err != nil {
+			if pr == nil {
+				return result, fmt.Errorf("PipelineRun cannot be created for the Component %s/%s: %+v", component.GetNamespace(), component.GetName(), err)
+			}
+			result.PipelineRun = pr
+			return result, fmt.Errorf("PipelineRun %s/%s for the Component %s did not finish: %+v", pr.GetNamespace(), pr.GetName(), component.GetName(), err)
+		}
+		previous[pr.GetName()] = true
+		result.PipelineRun = pr
+		result.Attempts = append(result.Attempts, newPipelineRunAttempt(pr, h.getPipelineRunTaskRuns(pr)))
+		if result.Succeeded() {
+			return result, nil
+		}
+
+		if err = t.StorePipelineRun(pr); err != nil {
+			GinkgoWriter.Printf("failed to store PipelineRun %s:%s: %s\n", pr.GetNamespace(), pr.GetName(), err.Error())
+		}
+		GinkgoWriter.Printf("attempt %d: PipelineRun %q failed with reason %s\n", attempt, pr.GetName(), result.Attempts[attempt-1].Reason)
+		if policy == nil || !policy.ShouldRetry(attempt, pr) {
+			return result, fmt.Errorf("PipelineRun for the Component %s/%s failed:\n%s", component.GetNamespace(), component.GetName(), result)
+		}
+
+		if err = t.RemoveFinalizerFromPipelineRun(pr, constants.E2ETestFinalizerName); err != nil {
+			return result, fmt.Errorf("failed to remove the finalizer from pipelinerun %s:%s in order to retrigger it: %+v", pr.GetNamespace(), pr.GetName(), err)
+		}
+		if err = h.PipelineClient().TektonV1().PipelineRuns(pr.GetNamespace()).Delete(context.Background(), pr.GetName(), metav1.DeleteOptions{}); err != nil {
+			return result, fmt.Errorf("failed to delete PipelineRun %q from %q namespace with error: %v", pr.GetName(), pr.GetNamespace(), err)
+		}
+		if sha, err = h.RetriggerComponentPipelineRun(component, pr); err != nil {
+			return result, fmt.Errorf("unable to retrigger pipelinerun for component %s:%s: %+v", component.GetNamespace(), component.GetName(), err)
+		}
+	}
+}
+
+// watchComponentPipelineRun waits until a PipelineRun of the component (other than the previous ones) is done.
+// The last seen PipelineRun is returned together with an error on timeout.
+func (h *HasController) watchComponentPipelineRun(component *appservice.Component, sha string, previous map[string]bool) (*pipeline.PipelineRun, error) {
+	pipelineRunLabels := map[string]string{"appstudio.openshift.io/component": component.GetName(), "appstudio.openshift.io/application": component.Spec.Application}
+	if sha != "" {
+		pipelineRunLabels["pipelinesascode.tekton.dev/sha"] = sha
+	}
+	selector := labels.SelectorFromSet(pipelineRunLabels).String()
+	client := h.PipelineClient().TektonV1().PipelineRuns(component.GetNamespace())
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.LabelSelector = selector
+			return client.List(context.Background(), options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.LabelSelector = selector
+			return client.Watch(context.Background(), options)
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ComponentPipelineTimeout)
+	defer cancel()
+	var last *pipeline.PipelineRun
+	var lastReason string
+	_, err := watchtools.UntilWithSync(ctx, lw, &pipeline.PipelineRun{}, nil, func(event watch.Event) (bool, error) {
+		pr, ok := event.Object.(*pipeline.PipelineRun)
+		if !ok || event.Type == watch.Deleted || previous[pr.GetName()] {
+			return false, nil
+		}
+		reason := pr.GetStatusCondition().GetCondition(apis.ConditionSucceeded).GetReason()
+		if last == nil || last.GetName() != pr.GetName() || reason != lastReason {
+			GinkgoWriter.Printf("PipelineRun %s reason: %s\n", pr.GetName(), reason)
+		}
+		last, lastReason = pr, reason
+		return pr.IsDone(), nil
+	})
+	return last, err
+}
+
+// getPipelineRunTaskRuns returns TaskRuns of the PipelineRun, TaskRuns which cannot be fetched are skipped
+func (h *HasController) getPipelineRunTaskRuns(pr *pipeline.PipelineRun) []pipeline.TaskRun {
+	var taskRuns []pipeline.TaskRun
+	for _, child := range pr.Status.ChildReferences {
+		if child.Kind != "TaskRun" {
+			continue
+		}
+		tr := pipeline.TaskRun{}
+		if err := h.KubeRest().Get(context.Background(), types.NamespacedName{Namespace: pr.GetNamespace(), Name: child.Name}, &tr); err != nil {
+			GinkgoWriter.Printf("failed to get TaskRun %s of PipelineRun %s: %+v\n", child.Name, pr.GetName(), err)
+			continue
+		}
+		taskRuns = append(taskRuns, tr)
+	}
+	return taskRuns
+}
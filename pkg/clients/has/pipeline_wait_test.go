@@ -0,0 +1,69 @@
+package has
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+)
+
+func failedPipelineRun(reason string) *pipeline.PipelineRun {
+	start := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	end := metav1.NewTime(start.Add(90 * time.Second))
+	pr := &pipeline.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "comp-on-push-abcde"}}
+	pr.Status.StartTime, pr.Status.CompletionTime = &start, &end
+	pr.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse, Reason: reason})
+	return pr
+}
+
+func TestRetryPolicy(t *testing.T) {
+	pullFailed, otherFailure := failedPipelineRun("TaskRunImagePullFailed"), failedPipelineRun("Failed")
+
+	options := &RetryOptions{Retries: 1}
+	assert.True(t, options.ShouldRetry(1, pullFailed))
+	assert.False(t, options.ShouldRetry(2, pullFailed), "retries are exhausted")
+	assert.False(t, options.ShouldRetry(1, otherFailure))
+	assert.True(t, (&RetryOptions{Retries: 1, Always: true}).ShouldRetry(1, otherFailure))
+	assert.False(t, (*RetryOptions)(nil).ShouldRetry(1, pullFailed))
+
+	policy := ReasonRetryPolicy{MaxAttempts: 3, Reasons: []string{"Failed"}}
+	assert.True(t, policy.ShouldRetry(2, otherFailure))
+	assert.False(t, policy.ShouldRetry(1, pullFailed))
+}
+
+func TestNewPipelineRunAttempt(t *testing.T) {
+	succeeded := pipeline.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "comp-on-push-abcde-init"}}
+	succeeded.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue})
+	failed := pipeline.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "comp-on-push-abcde-build-container", Labels: map[string]string{"tekton.dev/pipelineTask": "build-container"}}}
+	failed.Status = pipeline.TaskRunStatus{
+		Status: duckv1.Status{Conditions: duckv1.Conditions{{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse, Reason: "Failed"}}},
+		TaskRunStatusFields: pipeline.TaskRunStatusFields{Steps: []pipeline.StepState{
+			{Name: "build", ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}}},
+			{Name: "push", ContainerState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}},
+		}},
+	}
+
+	attempt := newPipelineRunAttempt(failedPipelineRun("Failed"), []pipeline.TaskRun{succeeded, failed})
+	assert.Equal(t, PipelineRunAttempt{
+		PipelineRun: "comp-on-push-abcde",
+		Reason:      "Failed",
+		Duration:    90 * time.Second,
+		FailedTaskRuns: []FailedTaskRun{{
+			Name:             "comp-on-push-abcde-build-container",
+			PipelineTaskName: "build-container",
+			Reason:           "Failed",
+			StepExitCodes:    map[string]int32{"build": 1, "push": 0},
+		}},
+	}, attempt)
+
+	result := &PipelineRunResult{Attempts: []PipelineRunAttempt{attempt}}
+	assert.False(t, result.Succeeded())
+	assert.Equal(t, attempt.FailedTaskRuns, result.FailedTaskRuns())
+	assert.Equal(t, "attempt 1: PipelineRun comp-on-push-abcde finished in 1m30s with reason Failed, "+
+		"TaskRun comp-on-push-abcde-build-container (build-container) failed with reason Failed, step build exited with 1", result.String())
+}
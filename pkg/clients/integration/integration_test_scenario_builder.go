@@ -0,0 +1,238 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/devfile/library/v2/pkg/util"
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/utils"
+	"github.com/konflux-ci/e2e-tests/pkg/utils/tekton"
+	integrationv1beta1 "github.com/konflux-ci/integration-service/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// ScenarioOptionalLabel marks scenarios whose failure doesn't block the release of the tested Snapshot
+	ScenarioOptionalLabel = "test.appstudio.openshift.io/optional"
+
+	GitResolver     = "git"
+	BundlesResolver = "bundles"
+	ClusterResolver = "cluster"
+)
+
+// Names of IntegrationTestScenario contexts recognized by integration-service
+const (
+	ApplicationContext     = "application"
+	ComponentContext       = "component"
+	ComponentContextPrefix = "component_"
+	GroupContext           = "group"
+	OverrideContext        = "override"
+	PullRequestContext     = "pull_request"
+	PushContext            = "push"
+	DisabledContext        = "disabled"
+)
+
+var knownContexts = map[string]bool{
+	ApplicationContext: true,
+	ComponentContext:   true,
+	GroupContext:       true,
+	OverrideContext:    true,
+	PullRequestContext: true,
+	PushContext:        true,
+	DisabledContext:    true,
+}
+
+// IntegrationTestScenarioBuilder creates IntegrationTestScenarios with chainable options, e.g.
+//
+//	scenario, err := fw.AsKubeAdmin.IntegrationController.NewIntegrationTestScenarioBuilder(name, namespace, appName).
+//		WithGitResolver(gitURL, "main", "pipelines/integration_resolver_pipeline_pass.yaml").
+//		WithParam("SNAPSHOT_TYPE", "component").
+//		WithContext(PullRequestContext, "runs only for pull requests").
+//		Optional(true).
+//		Create()
+//
+// Invalid combinations of options are reported by Build and Create.
+type IntegrationTestScenarioBuilder struct {
+	controller *IntegrationController
+	scenario   *integrationv1beta1.IntegrationTestScenario
+}
+
+// NewIntegrationTestScenarioBuilder returns a builder of a required IntegrationTestScenario for the application,
+// a random name is generated when the name is empty
+func (i *IntegrationController) NewIntegrationTestScenarioBuilder(name, namespace, applicationName string) *IntegrationTestScenarioBuilder {
+	if name == "" {
+		name = "my-integration-test-" + util.GenerateRandomString(4)
+	}
+	return &IntegrationTestScenarioBuilder{
+		controller: i,
+		scenario: &integrationv1beta1.IntegrationTestScenario{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    utils.MergeMaps(nil, constants.IntegrationTestScenarioDefaultLabels),
+			},
+			Spec: integrationv1beta1.IntegrationTestScenarioSpec{
+				Application: applicationName,
+			},
+		},
+	}
+}
+
+// WithResolver sets a Tekton resolver and its params locating the test pipeline
+func (b *IntegrationTestScenarioBuilder) WithResolver(resolver string, params ...integrationv1beta1.ResolverParameter) *IntegrationTestScenarioBuilder {
+	b.scenario.Spec.ResolverRef = integrationv1beta1.ResolverRef{Resolver: resolver, Params: params}
+	return b
+}
+
+// WithGitResolver resolves the test pipeline from a file in a git repository
+func (b *IntegrationTestScenarioBuilder) WithGitResolver(url, revision, pathInRepo string) *IntegrationTestScenarioBuilder {
+	return b.WithResolver(GitResolver,
+		integrationv1beta1.ResolverParameter{Name: "url", Value: url},
+		integrationv1beta1.ResolverParameter{Name: "revision", Value: revision},
+		integrationv1beta1.ResolverParameter{Name: "pathInRepo", Value: pathInRepo},
+	)
+}
+
+// WithBundleResolver resolves the test pipeline with the name from a Tekton bundle
+func (b *IntegrationTestScenarioBuilder) WithBundleResolver(bundle, pipelineName string) *IntegrationTestScenarioBuilder {
+	return b.WithResolver(BundlesResolver,
+		integrationv1beta1.ResolverParameter{Name: "bundle", Value: bundle},
+		integrationv1beta1.ResolverParameter{Name: "name", Value: pipelineName},
+		integrationv1beta1.ResolverParameter{Name: "kind", Value: "pipeline"},
+	)
+}
+
+// WithClusterResolver resolves the test pipeline with the name from the namespace in the cluster
+func (b *IntegrationTestScenarioBuilder) WithClusterResolver(pipelineName, pipelineNamespace string) *IntegrationTestScenarioBuilder {
+	return b.WithResolver(ClusterResolver,
+		integrationv1beta1.ResolverParameter{Name: "name", Value: pipelineName},
+		integrationv1beta1.ResolverParameter{Name: "namespace", Value: pipelineNamespace},
+		integrationv1beta1.ResolverParameter{Name: "kind", Value: "pipeline"},
+	)
+}
+
+// WithParam passes a string param to the test pipeline
+func (b *IntegrationTestScenarioBuilder) WithParam(name, value string) *IntegrationTestScenarioBuilder {
+	b.scenario.Spec.Params = append(b.scenario.Spec.Params, integrationv1beta1.PipelineParameter{Name: name, Value: value})
+	return b
+}
+
+// WithArrayParam passes an array param to the test pipeline
+func (b *IntegrationTestScenarioBuilder) WithArrayParam(name string, values ...string) *IntegrationTestScenarioBuilder {
+	b.scenario.Spec.Params = append(b.scenario.Spec.Params, integrationv1beta1.PipelineParameter{Name: name, Values: values})
+	return b
+}
+
+// WithContext limits the scenario to Snapshots matching the context (or any other context of the scenario)
+func (b *IntegrationTestScenarioBuilder) WithContext(name, description string) *IntegrationTestScenarioBuilder {
+	b.scenario.Spec.Contexts = append(b.scenario.Spec.Contexts, integrationv1beta1.TestContext{Name: name, Description: description})
+	return b
+}
+
+// WithComponentContext limits the scenario to Snapshots created for builds of the component
+func (b *IntegrationTestScenarioBuilder) WithComponentContext(componentName string) *IntegrationTestScenarioBuilder {
+	return b.WithContext(ComponentContextPrefix+componentName, fmt.Sprintf("runs only for component %s", componentName))
+}
+
+// Optional marks the scenario as optional for release, its failure won't block the release of the Snapshot
+func (b *IntegrationTestScenarioBuilder) Optional(optional bool) *IntegrationTestScenarioBuilder {
+	b.scenario.Labels[ScenarioOptionalLabel] = fmt.Sprint(optional)
+	return b
+}
+
+// WithLabels adds labels to the scenario
+func (b *IntegrationTestScenarioBuilder) WithLabels(labels map[string]string) *IntegrationTestScenarioBuilder {
+	b.scenario.Labels = utils.MergeMaps(b.scenario.Labels, labels)
+	return b
+}
+
+// Build validates the options and returns the IntegrationTestScenario object without creating it
+func (b *IntegrationTestScenarioBuilder) Build() (*integrationv1beta1.IntegrationTestScenario, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	return b.scenario.DeepCopy(), nil
+}
+
+func (b *IntegrationTestScenarioBuilder) validate() error {
+	var errs []error
+	scenario := b.scenario
+	for _, msg := range validation.IsDNS1123Label(scenario.Name) {
+		errs = append(errs, fmt.Errorf("invalid scenario name %q: %s", scenario.Name, msg))
+	}
+	if scenario.Namespace == "" {
+		errs = append(errs, fmt.Errorf("namespace is not set"))
+	}
+	if scenario.Spec.Application == "" {
+		errs = append(errs, fmt.Errorf("application is not set"))
+	}
+
+	resolver := scenario.Spec.ResolverRef
+	if resolver.Resolver == "" {
+		errs = append(errs, fmt.Errorf("resolver is not set"))
+	}
+	resolverParams := map[string]string{}
+	for _, p := range resolver.Params {
+		if _, ok := resolverParams[p.Name]; ok {
+			errs = append(errs, fmt.Errorf("resolver param %s is set more than once", p.Name))
+		}
+		resolverParams[p.Name] = p.Value
+	}
+	for _, name := range tekton.MissingResolverParams(resolver.Resolver, resolverParams) {
+		errs = append(errs, fmt.Errorf("%s resolver requires param %s", resolver.Resolver, name))
+	}
+
+	params := map[string]bool{}
+	for _, p := range scenario.Spec.Params {
+		if params[p.Name] {
+			errs = append(errs, fmt.Errorf("param %s is set more than once", p.Name))
+		}
+		params[p.Name] = true
+		if p.Value != "" && len(p.Values) > 0 {
+			errs = append(errs, fmt.Errorf("param %s has both a string and an array value", p.Name))
+		}
+	}
+
+	contexts := map[string]bool{}
+	for _, c := range scenario.Spec.Contexts {
+		switch {
+		case contexts[c.Name]:
+			errs = append(errs, fmt.Errorf("context %s is set more than once", c.Name))
+		case strings.HasPrefix(c.Name, ComponentContextPrefix):
+			if component := strings.TrimPrefix(c.Name, ComponentContextPrefix); component == "" {
+				errs = append(errs, fmt.Errorf("context %s doesn't name a component", c.Name))
+			}
+		case !knownContexts[c.Name]:
+			errs = append(errs, fmt.Errorf("unknown context %s", c.Name))
+		}
+		contexts[c.Name] = true
+	}
+	if contexts[DisabledContext] && len(contexts) > 1 {
+		errs = append(errs, fmt.Errorf("%s context cannot be combined with other contexts", DisabledContext))
+	}
+
+	if optional, ok := scenario.Labels[ScenarioOptionalLabel]; ok && optional != "true" && optional != "false" {
+		errs = append(errs, fmt.Errorf("label %s must be true or false, got %q", ScenarioOptionalLabel, optional))
+	}
+	return errors.Join(errs...)
+}
+
+// Create validates the options and creates the IntegrationTestScenario
+func (b *IntegrationTestScenarioBuilder) Create() (*integrationv1beta1.IntegrationTestScenario, error) {
+	scenario, err := b.Build()
+	if err != nil {
+		return nil, fmt.Errorf("invalid integration test scenario %s: %+v", b.scenario.Name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	if err := b.controller.KubeRest().Create(ctx, scenario); err != nil {
+		return nil, err
+	}
+	return scenario, nil
+}
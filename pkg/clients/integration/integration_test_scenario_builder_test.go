@@ -0,0 +1,37 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegrationTestScenarioBuilder(t *testing.T) {
+	scenario, err := (&IntegrationController{}).NewIntegrationTestScenarioBuilder("its", "tenant", "app").
+		WithBundleResolver("quay.io/org/tests:latest", "e2e").
+		WithParam("SNAPSHOT_TYPE", "component").
+		WithArrayParam("PLATFORMS", "linux/amd64", "linux/arm64").
+		WithComponentContext("comp").
+		WithContext(PullRequestContext, "").
+		Optional(true).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, BundlesResolver, scenario.Spec.ResolverRef.Resolver)
+	assert.Equal(t, "true", scenario.Labels[ScenarioOptionalLabel])
+	assert.Equal(t, []string{"linux/amd64", "linux/arm64"}, scenario.Spec.Params[1].Values)
+	assert.Equal(t, "component_comp", scenario.Spec.Contexts[0].Name)
+
+	_, err = (&IntegrationController{}).NewIntegrationTestScenarioBuilder("its", "tenant", "app").
+		WithClusterResolver("e2e", "").
+		WithParam("A", "1").WithParam("A", "2").
+		WithContext(DisabledContext, "").WithContext("nightly", "").WithContext(ComponentContextPrefix, "").
+		Build()
+	assert.EqualError(t, err, "cluster resolver requires param namespace\n"+
+		"param A is set more than once\n"+
+		"unknown context nightly\n"+
+		"context component_ doesn't name a component\n"+
+		"disabled context cannot be combined with other contexts")
+
+	_, err = (&IntegrationController{}).NewIntegrationTestScenarioBuilder("its", "tenant", "app").Build()
+	assert.EqualError(t, err, "resolver is not set")
+}
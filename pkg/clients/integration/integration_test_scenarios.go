@@ -3,52 +3,15 @@ package integration
 import (
 	"context"
 
-	"github.com/devfile/library/v2/pkg/util"
-	"github.com/konflux-ci/e2e-tests/pkg/constants"
 	integrationv1beta1 "github.com/konflux-ci/integration-service/api/v1beta1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // CreateIntegrationTestScenario creates beta1 version integrationTestScenario.
 func (i *IntegrationController) CreateIntegrationTestScenario(itsName, applicationName, namespace, gitURL, revision, pathInRepo string) (*integrationv1beta1.IntegrationTestScenario, error) {
-	if itsName == "" {
-		itsName = "my-integration-test-" + util.GenerateRandomString(4)
-	}
-
-	integrationTestScenario := &integrationv1beta1.IntegrationTestScenario{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      itsName,
-			Namespace: namespace,
-			Labels:    constants.IntegrationTestScenarioDefaultLabels,
-		},
-		Spec: integrationv1beta1.IntegrationTestScenarioSpec{
-			Application: applicationName,
-			ResolverRef: integrationv1beta1.ResolverRef{
-				Resolver: "git",
-				Params: []integrationv1beta1.ResolverParameter{
-					{
-						Name:  "url",
-						Value: gitURL,
-					},
-					{
-						Name:  "revision",
-						Value: revision,
-					},
-					{
-						Name:  "pathInRepo",
-						Value: pathInRepo,
-					},
-				},
-			},
-		},
-	}
-
-	err := i.KubeRest().Create(context.Background(), integrationTestScenario)
-	if err != nil {
-		return nil, err
-	}
-	return integrationTestScenario, nil
+	return i.NewIntegrationTestScenarioBuilder(itsName, namespace, applicationName).
+		WithGitResolver(gitURL, revision, pathInRepo).
+		Create()
 }
 
 // Get return the status from the Application Custom Resource object.
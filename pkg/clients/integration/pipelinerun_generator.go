@@ -0,0 +1,151 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	appstudioApi "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/konflux-ci/e2e-tests/pkg/utils/tekton"
+	integrationv1beta1 "github.com/konflux-ci/integration-service/api/v1beta1"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// IntegrationPipelineRunFinalizer is added by integration-service to integration PipelineRuns until their results are processed
+	IntegrationPipelineRunFinalizer = "test.appstudio.openshift.io/pipelinerun"
+
+	pipelineTypeLabel        = "pipelines.appstudio.openshift.io/type"
+	applicationLabel         = "appstudio.openshift.io/application"
+	pacLabelPrefix           = "pac.test.appstudio.openshift.io"
+	buildLabelPrefix         = "build.appstudio"
+	appstudioAnnotationInfix = "appstudio.openshift.io/"
+)
+
+// IntegrationPipelineRunGenerator generates the PipelineRun integration-service would create to test the Snapshot
+// with the scenario: the pipeline is resolved by the resolver of the scenario, the scenario params and the Snapshot
+// (as the SNAPSHOT param) are passed to it and it is labelled the same way. It implements tekton.PipelineRunGenerator,
+// so it can also be run by TektonController.RunPipeline to debug a scenario pipeline.
+type IntegrationPipelineRunGenerator struct {
+	Scenario *integrationv1beta1.IntegrationTestScenario
+	Snapshot *appstudioApi.Snapshot
+	// Namespace of the PipelineRun, the namespace of the Snapshot is used when empty
+	Namespace string
+	// ExtraParams are passed to the pipeline in addition to the scenario params
+	ExtraParams []tektonv1.Param
+	// WithFinalizer adds the finalizer integration-service uses to keep the PipelineRun until it processes its results
+	WithFinalizer bool
+}
+
+var _ tekton.PipelineRunGenerator = IntegrationPipelineRunGenerator{}
+
+// Generate returns the integration PipelineRun
+func (g IntegrationPipelineRunGenerator) Generate() (*tektonv1.PipelineRun, error) {
+	if err := g.validate(); err != nil {
+		return nil, err
+	}
+	namespace := g.Namespace
+	if namespace == "" {
+		namespace = g.Snapshot.GetNamespace()
+	}
+
+	var resolverParams []tektonv1.Param
+	for _, p := range g.Scenario.Spec.ResolverRef.Params {
+		resolverParams = append(resolverParams, tektonv1.Param{Name: p.Name, Value: *tektonv1.NewStructuredValues(p.Value)})
+	}
+
+	snapshotJSON, err := json.Marshal(g.Snapshot.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal spec of snapshot %s: %+v", g.Snapshot.GetName(), err)
+	}
+	params := []tektonv1.Param{{Name: "SNAPSHOT", Value: *tektonv1.NewStructuredValues(string(snapshotJSON))}}
+	for _, p := range g.Scenario.Spec.Params {
+		value := *tektonv1.NewStructuredValues(p.Value)
+		if p.Value == "" && len(p.Values) > 0 {
+			value = tektonv1.ParamValue{Type: tektonv1.ParamTypeArray, ArrayVal: p.Values}
+		}
+		params = append(params, tektonv1.Param{Name: p.Name, Value: value})
+	}
+	params = append(params, g.ExtraParams...)
+
+	pipelineRun := &tektonv1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: g.Snapshot.GetName() + "-",
+			Namespace:    namespace,
+			Labels:       g.labels(),
+			Annotations:  g.annotations(),
+		},
+		Spec: tektonv1.PipelineRunSpec{
+			PipelineRef: &tektonv1.PipelineRef{
+				ResolverRef: tektonv1.ResolverRef{
+					Resolver: tektonv1.ResolverName(g.Scenario.Spec.ResolverRef.Resolver),
+					Params:   resolverParams,
+				},
+			},
+			Params: params,
+		},
+	}
+	if g.WithFinalizer {
+		controllerutil.AddFinalizer(pipelineRun, IntegrationPipelineRunFinalizer)
+	}
+	return pipelineRun, nil
+}
+
+func (g IntegrationPipelineRunGenerator) validate() error {
+	var errs []error
+	if g.Scenario == nil {
+		errs = append(errs, fmt.Errorf("integration test scenario is not set"))
+	} else if g.Scenario.Spec.ResolverRef.Resolver == "" {
+		errs = append(errs, fmt.Errorf("integration test scenario %s has no resolver", g.Scenario.GetName()))
+	}
+	if g.Snapshot == nil {
+		errs = append(errs, fmt.Errorf("snapshot is not set"))
+	} else if g.Namespace == "" && g.Snapshot.GetNamespace() == "" {
+		errs = append(errs, fmt.Errorf("namespace is not set"))
+	}
+	return errors.Join(errs...)
+}
+
+// labels returns labels set by integration-service: type, scenario, its optional flag, snapshot, application
+// and component, and the PaC and build labels copied from the Snapshot
+func (g IntegrationPipelineRunGenerator) labels() map[string]string {
+	labels := map[string]string{}
+	for k, v := range g.Snapshot.GetLabels() {
+		if strings.HasPrefix(k, pacLabelPrefix) || strings.HasPrefix(k, buildLabelPrefix) {
+			labels[k] = v
+		}
+	}
+	labels[pipelineTypeLabel] = "test"
+	labels[scenarioLabel] = g.Scenario.GetName()
+	if optional, ok := g.Scenario.GetLabels()[ScenarioOptionalLabel]; ok {
+		labels[ScenarioOptionalLabel] = optional
+	}
+	labels[snapshotLabel] = g.Snapshot.GetName()
+	if component, ok := g.Snapshot.GetLabels()[snapshotComponentLabel]; ok {
+		labels[snapshotComponentLabel] = component
+	}
+	labels[applicationLabel] = g.Snapshot.Spec.Application
+	return labels
+}
+
+// annotations returns App Studio annotations of the scenario and the PaC and build annotations of the Snapshot
+func (g IntegrationPipelineRunGenerator) annotations() map[string]string {
+	annotations := map[string]string{}
+	for k, v := range g.Scenario.GetAnnotations() {
+		if strings.Contains(k, appstudioAnnotationInfix) {
+			annotations[k] = v
+		}
+	}
+	for k, v := range g.Snapshot.GetAnnotations() {
+		if strings.HasPrefix(k, pacLabelPrefix) || strings.HasPrefix(k, buildLabelPrefix) {
+			annotations[k] = v
+		}
+	}
+	if len(annotations) == 0 {
+		return nil
+	}
+	return annotations
+}
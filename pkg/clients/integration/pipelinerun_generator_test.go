@@ -0,0 +1,64 @@
+package integration
+
+import (
+	"testing"
+
+	appstudioApi "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIntegrationPipelineRunGenerator(t *testing.T) {
+	scenario, err := (&IntegrationController{}).NewIntegrationTestScenarioBuilder("its", "tenant", "app").
+		WithGitResolver("https://github.com/org/tests", "main", "pipeline.yaml").
+		WithParam("SNAPSHOT_TYPE", "component").
+		WithArrayParam("PLATFORMS", "linux/amd64").
+		WithParam("EXTRA_ARGS", "").
+		Optional(true).
+		Build()
+	assert.NoError(t, err)
+	snapshot := &appstudioApi.Snapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app-abcde",
+			Namespace: "tenant",
+			Labels: map[string]string{
+				"appstudio.openshift.io/component":           "comp",
+				"pac.test.appstudio.openshift.io/event-type": "pull_request",
+				"test.appstudio.openshift.io/type":           "component",
+			},
+			Annotations: map[string]string{"build.appstudio.redhat.com/commit_sha": "abcdef"},
+		},
+		Spec: appstudioApi.SnapshotSpec{
+			Application: "app",
+			Components:  []appstudioApi.SnapshotComponent{{Name: "comp", ContainerImage: "quay.io/org/comp@sha256:1234"}},
+		},
+	}
+
+	pipelineRun, err := IntegrationPipelineRunGenerator{Scenario: scenario, Snapshot: snapshot, WithFinalizer: true}.Generate()
+	assert.NoError(t, err)
+	assert.Equal(t, "app-abcde-", pipelineRun.GenerateName)
+	assert.Equal(t, "tenant", pipelineRun.Namespace)
+	assert.Equal(t, map[string]string{
+		"pipelines.appstudio.openshift.io/type":      "test",
+		"test.appstudio.openshift.io/scenario":       "its",
+		"test.appstudio.openshift.io/optional":       "true",
+		"appstudio.openshift.io/snapshot":            "app-abcde",
+		"appstudio.openshift.io/application":         "app",
+		"appstudio.openshift.io/component":           "comp",
+		"pac.test.appstudio.openshift.io/event-type": "pull_request",
+	}, pipelineRun.Labels)
+	assert.Equal(t, map[string]string{"build.appstudio.redhat.com/commit_sha": "abcdef"}, pipelineRun.Annotations)
+	assert.Equal(t, []string{IntegrationPipelineRunFinalizer}, pipelineRun.Finalizers)
+	assert.Equal(t, tektonv1.ResolverName("git"), pipelineRun.Spec.PipelineRef.Resolver)
+	assert.Len(t, pipelineRun.Spec.PipelineRef.Params, 3)
+	assert.Equal(t, tektonv1.Params{
+		{Name: "SNAPSHOT", Value: *tektonv1.NewStructuredValues(`{"application":"app","components":[{"name":"comp","containerImage":"quay.io/org/comp@sha256:1234","source":{}}],"artifacts":{}}`)},
+		{Name: "SNAPSHOT_TYPE", Value: *tektonv1.NewStructuredValues("component")},
+		{Name: "PLATFORMS", Value: tektonv1.ParamValue{Type: tektonv1.ParamTypeArray, ArrayVal: []string{"linux/amd64"}}},
+		{Name: "EXTRA_ARGS", Value: *tektonv1.NewStructuredValues("")},
+	}, pipelineRun.Spec.Params)
+
+	_, err = IntegrationPipelineRunGenerator{Snapshot: &appstudioApi.Snapshot{}}.Generate()
+	assert.EqualError(t, err, "integration test scenario is not set\nnamespace is not set")
+}
@@ -23,36 +23,39 @@ import (
 )
 
 // CreateIntegrationPipelineRun creates new integrationPipelineRun.
+// The PipelineRun of the scenario running the integration-pipeline-pass bundle is generated by IntegrationPipelineRunGenerator.
 func (i *IntegrationController) CreateIntegrationPipelineRun(snapshotName, namespace, componentName, integrationTestScenarioName string) (*tektonv1.PipelineRun, error) {
-	testpipelineRun := &tektonv1.PipelineRun{
-		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: "component-pipelinerun" + "-",
-			Namespace:    namespace,
-			Labels: map[string]string{
-				"pipelinesascode.tekton.dev/event-type": "push",
-				"appstudio.openshift.io/component":      componentName,
-				"pipelines.appstudio.openshift.io/type": "test",
-				"appstudio.openshift.io/snapshot":       snapshotName,
-				"test.appstudio.openshift.io/scenario":  integrationTestScenarioName,
-			},
-		},
-		Spec: tektonv1.PipelineRunSpec{
-			PipelineRef: tekton.NewBundleResolverPipelineRef(
-				"integration-pipeline-pass",
-				"quay.io/redhat-appstudio/example-tekton-bundle:integration-pipeline-pass",
-			),
-			Params: []tektonv1.Param{
-				{
-					Name: "output-image",
-					Value: tektonv1.ParamValue{
-						Type:      "string",
-						StringVal: "quay.io/redhat-appstudio/sample-image",
-					},
+	scenario := &integrationv1beta1.IntegrationTestScenario{
+		ObjectMeta: metav1.ObjectMeta{Name: integrationTestScenarioName, Namespace: namespace},
+		Spec: integrationv1beta1.IntegrationTestScenarioSpec{
+			ResolverRef: integrationv1beta1.ResolverRef{
+				Resolver: BundlesResolver,
+				Params: []integrationv1beta1.ResolverParameter{
+					{Name: "name", Value: "integration-pipeline-pass"},
+					{Name: "bundle", Value: "quay.io/redhat-appstudio/example-tekton-bundle:integration-pipeline-pass"},
+					{Name: "kind", Value: "pipeline"},
 				},
 			},
 		},
 	}
-	err := i.KubeRest().Create(context.Background(), testpipelineRun)
+	snapshot := &appstudioApi.Snapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      snapshotName,
+			Namespace: namespace,
+			Labels:    map[string]string{snapshotComponentLabel: componentName},
+		},
+	}
+	testpipelineRun, err := IntegrationPipelineRunGenerator{
+		Scenario:    scenario,
+		Snapshot:    snapshot,
+		ExtraParams: []tektonv1.Param{{Name: "output-image", Value: *tektonv1.NewStructuredValues("quay.io/redhat-appstudio/sample-image")}},
+	}.Generate()
+	if err != nil {
+		return nil, err
+	}
+	testpipelineRun.Labels["pipelinesascode.tekton.dev/event-type"] = "push"
+
+	err = i.KubeRest().Create(context.Background(), testpipelineRun)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,146 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appstudioApi "github.com/konflux-ci/application-api/api/v1alpha1"
+	integrationv1beta1 "github.com/konflux-ci/integration-service/api/v1beta1"
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	. "github.com/onsi/ginkgo/v2"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	snapshotTypeLabel      = "test.appstudio.openshift.io/type"
+	snapshotComponentLabel = "appstudio.openshift.io/component"
+	snapshotEventTypeLabel = "pac.test.appstudio.openshift.io/event-type"
+	snapshotLabel          = "appstudio.openshift.io/snapshot"
+	scenarioLabel          = "test.appstudio.openshift.io/scenario"
+)
+
+// IsScenarioApplicableToSnapshot tells whether integration-service is expected to run the scenario for the Snapshot,
+// i.e. whether any context of the scenario matches the Snapshot. A scenario without contexts applies to every Snapshot.
+func IsScenarioApplicableToSnapshot(scenario *integrationv1beta1.IntegrationTestScenario, snapshot *appstudioApi.Snapshot) bool {
+	if len(scenario.Spec.Contexts) == 0 {
+		return true
+	}
+	labels := snapshot.GetLabels()
+	snapshotType := labels[snapshotTypeLabel]
+	eventType, hasEventType := labels[snapshotEventTypeLabel]
+	eventType = strings.ToLower(eventType)
+
+	for _, c := range scenario.Spec.Contexts {
+		switch {
+		case c.Name == ApplicationContext:
+			return true
+		case c.Name == ComponentContext && snapshotType == ComponentContext:
+			return true
+		case strings.HasPrefix(c.Name, ComponentContextPrefix) && snapshotType == ComponentContext &&
+			strings.TrimPrefix(c.Name, ComponentContextPrefix) == labels[snapshotComponentLabel]:
+			return true
+		case c.Name == GroupContext && snapshotType == GroupContext:
+			return true
+		case c.Name == OverrideContext && snapshotType == OverrideContext:
+			return true
+		case c.Name == PullRequestContext && (eventType == "pull_request" || eventType == "merge request"):
+			return true
+		// Snapshots without the event type were not created for a pull request
+		case c.Name == PushContext && (eventType == "push" || !hasEventType):
+			return true
+		}
+	}
+	return false
+}
+
+// ExpectedScenariosForSnapshot returns sorted names of the scenarios integration-service is expected to run for the Snapshot
+func ExpectedScenariosForSnapshot(scenarios []integrationv1beta1.IntegrationTestScenario, snapshot *appstudioApi.Snapshot) []string {
+	expected := []string{}
+	for i := range scenarios {
+		if IsScenarioApplicableToSnapshot(&scenarios[i], snapshot) {
+			expected = append(expected, scenarios[i].GetName())
+		}
+	}
+	sort.Strings(expected)
+	return expected
+}
+
+// GetSelectedScenariosForSnapshot returns sorted names of the scenarios integration-service has selected for the Snapshot so far,
+// i.e. the scenarios with an integration PipelineRun or a test status recorded in the Snapshot
+func (i *IntegrationController) GetSelectedScenariosForSnapshot(snapshot *appstudioApi.Snapshot) ([]string, error) {
+	selected := sets.New[string]()
+
+	current := &appstudioApi.Snapshot{}
+	if err := i.KubeRest().Get(context.Background(), client.ObjectKeyFromObject(snapshot), current); err != nil {
+		return nil, fmt.Errorf("failed to get snapshot %s/%s: %+v", snapshot.GetNamespace(), snapshot.GetName(), err)
+	}
+	statuses, err := intgteststat.NewSnapshotIntegrationTestStatuses(current.GetAnnotations()[SnapshotTestsStatusAnnotation])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse test statuses of snapshot %s: %+v", snapshot.GetName(), err)
+	}
+	for _, detail := range statuses.GetStatuses() {
+		selected.Insert(detail.ScenarioName)
+	}
+
+	pipelineRuns := &tektonv1.PipelineRunList{}
+	opts := []client.ListOption{
+		client.InNamespace(snapshot.GetNamespace()),
+		client.MatchingLabels{
+			"pipelines.appstudio.openshift.io/type": "test",
+			snapshotLabel:                           snapshot.GetName(),
+		},
+	}
+	if err := i.KubeRest().List(context.Background(), pipelineRuns, opts...); err != nil {
+		return nil, fmt.Errorf("failed to list integration pipelineruns of snapshot %s: %+v", snapshot.GetName(), err)
+	}
+	for _, pr := range pipelineRuns.Items {
+		if scenario := pr.GetLabels()[scenarioLabel]; scenario != "" {
+			selected.Insert(scenario)
+		}
+	}
+	return sets.List(selected), nil
+}
+
+// compareScenarioSelection returns the expected scenarios which were not selected and the selected scenarios which were not expected
+func compareScenarioSelection(expected, selected []string) (missing, unexpected []string) {
+	expectedSet, selectedSet := sets.New(expected...), sets.New(selected...)
+	return sets.List(expectedSet.Difference(selectedSet)), sets.List(selectedSet.Difference(expectedSet))
+}
+
+// VerifyScenarioSelection waits until integration-service selects exactly the expected scenarios for the Snapshot.
+// It fails as soon as a scenario which is not expected is selected, or when some expected scenario is not selected in time.
+func (i *IntegrationController) VerifyScenarioSelection(snapshot *appstudioApi.Snapshot, expected []string, timeout time.Duration) error {
+	var missing, unexpected []string
+	err := wait.PollUntilContextTimeout(context.Background(), time.Second*5, timeout, true, func(ctx context.Context) (done bool, err error) {
+		selected, err := i.GetSelectedScenariosForSnapshot(snapshot)
+		if err != nil {
+			GinkgoWriter.Printf("failed to get scenarios selected for snapshot %s: %+v\n", snapshot.GetName(), err)
+			return false, nil
+		}
+		missing, unexpected = compareScenarioSelection(expected, selected)
+		if len(unexpected) > 0 {
+			return false, fmt.Errorf("scenarios %v were selected for snapshot %s/%s but not expected (expected %v)", unexpected, snapshot.GetNamespace(), snapshot.GetName(), expected)
+		}
+		return len(missing) == 0, nil
+	})
+	if err != nil && len(unexpected) == 0 {
+		return fmt.Errorf("scenarios %v were not selected for snapshot %s/%s: %+v", missing, snapshot.GetNamespace(), snapshot.GetName(), err)
+	}
+	return err
+}
+
+// VerifyScenarioSelectionForApplication computes the scenarios expected to run for the Snapshot from the contexts
+// of all scenarios of its application, and verifies integration-service selected exactly those
+func (i *IntegrationController) VerifyScenarioSelectionForApplication(snapshot *appstudioApi.Snapshot, timeout time.Duration) error {
+	scenarios, err := i.GetIntegrationTestScenarios(snapshot.Spec.Application, snapshot.GetNamespace())
+	if err != nil {
+		return fmt.Errorf("failed to get integration test scenarios of application %s: %+v", snapshot.Spec.Application, err)
+	}
+	return i.VerifyScenarioSelection(snapshot, ExpectedScenariosForSnapshot(*scenarios, snapshot), timeout)
+}
@@ -0,0 +1,57 @@
+package integration
+
+import (
+	"testing"
+
+	appstudioApi "github.com/konflux-ci/application-api/api/v1alpha1"
+	integrationv1beta1 "github.com/konflux-ci/integration-service/api/v1beta1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestExpectedScenariosForSnapshot(t *testing.T) {
+	var scenarios []integrationv1beta1.IntegrationTestScenario
+	for name, contexts := range map[string][]string{
+		"all":              nil,
+		"application":      {ApplicationContext},
+		"comp-a":           {ComponentContextPrefix + "comp-a"},
+		"components":       {ComponentContext},
+		"pull-request":     {PullRequestContext},
+		"push-or-override": {PushContext, OverrideContext},
+		"group":            {GroupContext},
+		"disabled":         {DisabledContext},
+	} {
+		scenario := integrationv1beta1.IntegrationTestScenario{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		for _, c := range contexts {
+			scenario.Spec.Contexts = append(scenario.Spec.Contexts, integrationv1beta1.TestContext{Name: c})
+		}
+		scenarios = append(scenarios, scenario)
+	}
+	snapshot := func(labels map[string]string) *appstudioApi.Snapshot {
+		return &appstudioApi.Snapshot{ObjectMeta: metav1.ObjectMeta{Labels: labels}}
+	}
+
+	assert.Equal(t, []string{"all", "application", "comp-a", "components", "pull-request"}, ExpectedScenariosForSnapshot(scenarios, snapshot(map[string]string{
+		snapshotTypeLabel:      "component",
+		snapshotComponentLabel: "comp-a",
+		snapshotEventTypeLabel: "pull_request",
+	})))
+	assert.Equal(t, []string{"all", "application", "components", "pull-request"}, ExpectedScenariosForSnapshot(scenarios, snapshot(map[string]string{
+		snapshotTypeLabel:      "component",
+		snapshotComponentLabel: "comp-b",
+		snapshotEventTypeLabel: "Merge Request",
+	})))
+	assert.Equal(t, []string{"all", "application", "push-or-override"}, ExpectedScenariosForSnapshot(scenarios, snapshot(map[string]string{
+		snapshotTypeLabel: "override",
+	})))
+	assert.Equal(t, []string{"all", "application", "group", "pull-request"}, ExpectedScenariosForSnapshot(scenarios, snapshot(map[string]string{
+		snapshotTypeLabel:      "group",
+		snapshotEventTypeLabel: "pull_request",
+	})))
+}
+
+func TestCompareScenarioSelection(t *testing.T) {
+	missing, unexpected := compareScenarioSelection([]string{"a", "b"}, []string{"b", "c"})
+	assert.Equal(t, []string{"a"}, missing)
+	assert.Equal(t, []string{"c"}, unexpected)
+}
@@ -0,0 +1,235 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/devfile/library/v2/pkg/util"
+	appstudioApi "github.com/konflux-ci/application-api/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OverrideSnapshotType is the type of Snapshots created by users to override the global candidate list of the application
+const OverrideSnapshotType = "override"
+
+// SnapshotComponentsFromGlobalCandidates returns the global candidate list of the application made of its Components:
+// the latest promoted image of every Component and the git source with the last built commit, sorted by name.
+// Components without any promoted image are skipped, the same way integration-service skips them.
+func SnapshotComponentsFromGlobalCandidates(components []appstudioApi.Component) []appstudioApi.SnapshotComponent {
+	var snapshotComponents []appstudioApi.SnapshotComponent
+	for _, component := range components {
+		if component.Spec.ContainerImage == "" {
+			continue
+		}
+		source := *component.Spec.Source.DeepCopy()
+		if source.GitSource != nil && component.Status.LastBuiltCommit != "" {
+			source.GitSource.Revision = component.Status.LastBuiltCommit
+		}
+		snapshotComponents = append(snapshotComponents, appstudioApi.SnapshotComponent{
+			Name:           component.GetName(),
+			ContainerImage: component.Spec.ContainerImage,
+			Source:         source,
+		})
+	}
+	sort.Slice(snapshotComponents, func(i, j int) bool { return snapshotComponents[i].Name < snapshotComponents[j].Name })
+	return snapshotComponents
+}
+
+// getApplicationComponents returns the Components of the application
+func (i *IntegrationController) getApplicationComponents(applicationName, namespace string) ([]appstudioApi.Component, error) {
+	componentList := &appstudioApi.ComponentList{}
+	if err := i.KubeRest().List(context.Background(), componentList, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list components in namespace %s: %+v", namespace, err)
+	}
+	var components []appstudioApi.Component
+	for _, c := range componentList.Items {
+		if c.Spec.Application == applicationName {
+			components = append(components, c)
+		}
+	}
+	return components, nil
+}
+
+// ComposeSnapshotFromApplication returns a (not yet created) Snapshot of the current global candidate list of the application
+func (i *IntegrationController) ComposeSnapshotFromApplication(applicationName, namespace string) (*appstudioApi.Snapshot, error) {
+	components, err := i.getApplicationComponents(applicationName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return &appstudioApi.Snapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "snapshot-sample-" + util.GenerateRandomString(4),
+			Namespace: namespace,
+		},
+		Spec: appstudioApi.SnapshotSpec{
+			Application: applicationName,
+			Components:  SnapshotComponentsFromGlobalCandidates(components),
+		},
+	}, nil
+}
+
+// CreateOverrideSnapshot creates an override Snapshot of the application: the global candidate list with the given components replaced
+// (or added when the application has no promoted image of them yet)
+func (i *IntegrationController) CreateOverrideSnapshot(applicationName, namespace string, overrides ...appstudioApi.SnapshotComponent) (*appstudioApi.Snapshot, error) {
+	snapshot, err := i.ComposeSnapshotFromApplication(applicationName, namespace)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Labels = map[string]string{snapshotTypeLabel: OverrideSnapshotType}
+	snapshot.Spec.Components = overrideSnapshotComponents(snapshot.Spec.Components, overrides)
+	return snapshot, i.KubeRest().Create(context.Background(), snapshot)
+}
+
+func overrideSnapshotComponents(components, overrides []appstudioApi.SnapshotComponent) []appstudioApi.SnapshotComponent {
+	result := append([]appstudioApi.SnapshotComponent{}, components...)
+	for _, override := range overrides {
+		replaced := false
+		for idx := range result {
+			if result[idx].Name == override.Name {
+				result[idx] = override
+				replaced = true
+			}
+		}
+		if !replaced {
+			result = append(result, override)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// ComponentChange is a component present in both compared Snapshots with a different image or source
+type ComponentChange struct {
+	Name string
+	Old  appstudioApi.SnapshotComponent
+	New  appstudioApi.SnapshotComponent
+}
+
+// ImageChanged tells whether the component container image differs
+func (c ComponentChange) ImageChanged() bool {
+	return c.Old.ContainerImage != c.New.ContainerImage
+}
+
+// SourceChanged tells whether the component source (e.g. git URL or revision) differs
+func (c ComponentChange) SourceChanged() bool {
+	return !reflect.DeepEqual(c.Old.Source, c.New.Source)
+}
+
+// SnapshotDiff is the difference between components of two Snapshots, each list is sorted by the component name
+type SnapshotDiff struct {
+	Added   []appstudioApi.SnapshotComponent
+	Removed []appstudioApi.SnapshotComponent
+	Changed []ComponentChange
+}
+
+// DiffSnapshots compares components of the Snapshot with the ones of a later Snapshot
+func DiffSnapshots(from, to *appstudioApi.Snapshot) SnapshotDiff {
+	oldComponents := map[string]appstudioApi.SnapshotComponent{}
+	for _, c := range from.Spec.Components {
+		oldComponents[c.Name] = c
+	}
+	newComponents := map[string]appstudioApi.SnapshotComponent{}
+	for _, c := range to.Spec.Components {
+		newComponents[c.Name] = c
+	}
+
+	diff := SnapshotDiff{}
+	for name, n := range newComponents {
+		o, ok := oldComponents[name]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, n)
+		case !reflect.DeepEqual(o, n):
+			diff.Changed = append(diff.Changed, ComponentChange{Name: name, Old: o, New: n})
+		}
+	}
+	for name, o := range oldComponents {
+		if _, ok := newComponents[name]; !ok {
+			diff.Removed = append(diff.Removed, o)
+		}
+	}
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].Name < diff.Added[j].Name })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].Name < diff.Removed[j].Name })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Name < diff.Changed[j].Name })
+	return diff
+}
+
+// IsEmpty tells whether both Snapshots have the same components
+func (d SnapshotDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String returns the diff in a human-readable form, one component per line
+func (d SnapshotDiff) String() string {
+	var lines []string
+	for _, c := range d.Added {
+		lines = append(lines, fmt.Sprintf("+ %s: %s", c.Name, c.ContainerImage))
+	}
+	for _, c := range d.Removed {
+		lines = append(lines, fmt.Sprintf("- %s: %s", c.Name, c.ContainerImage))
+	}
+	for _, c := range d.Changed {
+		if c.ImageChanged() {
+			lines = append(lines, fmt.Sprintf("~ %s: image %s -> %s", c.Name, c.Old.ContainerImage, c.New.ContainerImage))
+		}
+		if c.SourceChanged() {
+			lines = append(lines, fmt.Sprintf("~ %s: source %s -> %s", c.Name, describeSource(c.Old.Source), describeSource(c.New.Source)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func describeSource(source appstudioApi.ComponentSource) string {
+	if source.GitSource == nil {
+		return "<none>"
+	}
+	return fmt.Sprintf("%s@%s", source.GitSource.URL, source.GitSource.Revision)
+}
+
+// globalCandidateMismatches returns the components of the Snapshot whose global candidate differs from it,
+// the revision is compared only when the Snapshot component has a git source with a revision
+func globalCandidateMismatches(snapshot *appstudioApi.Snapshot, components []appstudioApi.Component) []string {
+	byName := map[string]appstudioApi.Component{}
+	for _, c := range components {
+		byName[c.GetName()] = c
+	}
+	var mismatches []string
+	for _, sc := range snapshot.Spec.Components {
+		component, ok := byName[sc.Name]
+		switch {
+		case !ok:
+			mismatches = append(mismatches, fmt.Sprintf("component %s not found", sc.Name))
+		case component.Spec.ContainerImage != sc.ContainerImage:
+			mismatches = append(mismatches, fmt.Sprintf("component %s has image %s instead of %s", sc.Name, component.Spec.ContainerImage, sc.ContainerImage))
+		case sc.Source.GitSource != nil && sc.Source.GitSource.Revision != "" && component.Status.LastBuiltCommit != sc.Source.GitSource.Revision:
+			mismatches = append(mismatches, fmt.Sprintf("component %s has last built commit %s instead of %s", sc.Name, component.Status.LastBuiltCommit, sc.Source.GitSource.Revision))
+		}
+	}
+	return mismatches
+}
+
+// WaitForGlobalCandidatesToMatchSnapshot waits until integration-service promotes the Snapshot,
+// i.e. until the global candidate list of its application has images (and commits) of all the Snapshot components
+func (i *IntegrationController) WaitForGlobalCandidatesToMatchSnapshot(snapshot *appstudioApi.Snapshot, timeout time.Duration) error {
+	var mismatches []string
+	err := wait.PollUntilContextTimeout(context.Background(), time.Second*10, timeout, true, func(ctx context.Context) (done bool, err error) {
+		components, err := i.getApplicationComponents(snapshot.Spec.Application, snapshot.GetNamespace())
+		if err != nil {
+			GinkgoWriter.Printf("failed to get components of application %s: %+v\n", snapshot.Spec.Application, err)
+			return false, nil
+		}
+		mismatches = globalCandidateMismatches(snapshot, components)
+		return len(mismatches) == 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("global candidate list of application %s doesn't match snapshot %s: %s", snapshot.Spec.Application, snapshot.GetName(), strings.Join(mismatches, ", "))
+	}
+	return nil
+}
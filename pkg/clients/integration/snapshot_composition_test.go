@@ -0,0 +1,95 @@
+package integration
+
+import (
+	"testing"
+
+	appstudioApi "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func component(name, image, url, lastBuiltCommit string) appstudioApi.Component {
+	c := appstudioApi.Component{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	c.Spec.ContainerImage = image
+	c.Spec.Source.GitSource = &appstudioApi.GitSource{URL: url, Revision: "main"}
+	c.Status.LastBuiltCommit = lastBuiltCommit
+	return c
+}
+
+func snapshotComponent(name, image, url, revision string) appstudioApi.SnapshotComponent {
+	return appstudioApi.SnapshotComponent{
+		Name:           name,
+		ContainerImage: image,
+		Source:         appstudioApi.ComponentSource{ComponentSourceUnion: appstudioApi.ComponentSourceUnion{GitSource: &appstudioApi.GitSource{URL: url, Revision: revision}}},
+	}
+}
+
+func TestSnapshotComponentsFromGlobalCandidates(t *testing.T) {
+	components := []appstudioApi.Component{
+		component("b", "quay.io/org/b@sha256:b1", "https://github.com/org/b", "b1"),
+		component("not-built", "", "https://github.com/org/not-built", ""),
+		component("a", "quay.io/org/a@sha256:a1", "https://github.com/org/a", ""),
+	}
+	assert.Equal(t, []appstudioApi.SnapshotComponent{
+		snapshotComponent("a", "quay.io/org/a@sha256:a1", "https://github.com/org/a", "main"),
+		snapshotComponent("b", "quay.io/org/b@sha256:b1", "https://github.com/org/b", "b1"),
+	}, SnapshotComponentsFromGlobalCandidates(components))
+	assert.Equal(t, "main", components[1].Spec.Source.GitSource.Revision, "components are not modified")
+
+	assert.Equal(t, []appstudioApi.SnapshotComponent{
+		snapshotComponent("a", "quay.io/org/a@sha256:a2", "https://github.com/org/a", "a2"),
+		snapshotComponent("b", "quay.io/org/b@sha256:b1", "https://github.com/org/b", "b1"),
+		snapshotComponent("c", "quay.io/org/c@sha256:c1", "https://github.com/org/c", "c1"),
+	}, overrideSnapshotComponents(SnapshotComponentsFromGlobalCandidates(components), []appstudioApi.SnapshotComponent{
+		snapshotComponent("c", "quay.io/org/c@sha256:c1", "https://github.com/org/c", "c1"),
+		snapshotComponent("a", "quay.io/org/a@sha256:a2", "https://github.com/org/a", "a2"),
+	}))
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	from := &appstudioApi.Snapshot{Spec: appstudioApi.SnapshotSpec{Components: []appstudioApi.SnapshotComponent{
+		snapshotComponent("a", "quay.io/org/a@sha256:a1", "https://github.com/org/a", "a1"),
+		snapshotComponent("b", "quay.io/org/b@sha256:b1", "https://github.com/org/b", "b1"),
+		snapshotComponent("c", "quay.io/org/c@sha256:c1", "https://github.com/org/c", "c1"),
+	}}}
+	to := &appstudioApi.Snapshot{Spec: appstudioApi.SnapshotSpec{Components: []appstudioApi.SnapshotComponent{
+		snapshotComponent("d", "quay.io/org/d@sha256:d1", "https://github.com/org/d", "d1"),
+		snapshotComponent("c", "quay.io/org/c@sha256:c1", "https://github.com/org/c", "c2"),
+		snapshotComponent("b", "quay.io/org/b@sha256:b2", "https://github.com/org/b", "b1"),
+	}}}
+
+	diff := DiffSnapshots(from, to)
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, []string{"b", "c"}, []string{diff.Changed[0].Name, diff.Changed[1].Name})
+	assert.True(t, diff.Changed[0].ImageChanged())
+	assert.False(t, diff.Changed[0].SourceChanged())
+	assert.False(t, diff.Changed[1].ImageChanged())
+	assert.True(t, diff.Changed[1].SourceChanged())
+	assert.Equal(t, "+ d: quay.io/org/d@sha256:d1\n"+
+		"- a: quay.io/org/a@sha256:a1\n"+
+		"~ b: image quay.io/org/b@sha256:b1 -> quay.io/org/b@sha256:b2\n"+
+		"~ c: source https://github.com/org/c@c1 -> https://github.com/org/c@c2", diff.String())
+	assert.True(t, DiffSnapshots(to, to).IsEmpty())
+}
+
+func TestGlobalCandidateMismatches(t *testing.T) {
+	snapshot := &appstudioApi.Snapshot{Spec: appstudioApi.SnapshotSpec{Components: []appstudioApi.SnapshotComponent{
+		snapshotComponent("a", "quay.io/org/a@sha256:a2", "https://github.com/org/a", "a2"),
+		snapshotComponent("b", "quay.io/org/b@sha256:b1", "https://github.com/org/b", "b1"),
+		{Name: "c", ContainerImage: "quay.io/org/c@sha256:c1"},
+	}}}
+	components := []appstudioApi.Component{
+		component("a", "quay.io/org/a@sha256:a1", "https://github.com/org/a", "a1"),
+		component("b", "quay.io/org/b@sha256:b1", "https://github.com/org/b", "b0"),
+		component("c", "quay.io/org/c@sha256:c1", "https://github.com/org/c", "c0"),
+	}
+	assert.Equal(t, []string{
+		"component a has image quay.io/org/a@sha256:a1 instead of quay.io/org/a@sha256:a2",
+		"component b has last built commit b0 instead of b1",
+	}, globalCandidateMismatches(snapshot, components))
+	assert.Empty(t, globalCandidateMismatches(snapshot, []appstudioApi.Component{
+		component("a", "quay.io/org/a@sha256:a2", "https://github.com/org/a", "a2"),
+		component("b", "quay.io/org/b@sha256:b1", "https://github.com/org/b", "b1"),
+		component("c", "quay.io/org/c@sha256:c1", "https://github.com/org/c", ""),
+	}))
+}
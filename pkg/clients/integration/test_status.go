@@ -0,0 +1,255 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	appstudioApi "github.com/konflux-ci/application-api/api/v1alpha1"
+	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
+	. "github.com/onsi/ginkgo/v2"
+	"github.com/onsi/gomega/format"
+	"github.com/onsi/gomega/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Statuses of integration test scenarios reported in the Snapshot
+const (
+	TestPending    = intgteststat.IntegrationTestStatusPending
+	TestInProgress = intgteststat.IntegrationTestStatusInProgress
+	TestDeleted    = intgteststat.IntegrationTestStatusDeleted
+	TestFail       = intgteststat.IntegrationTestStatusTestFail
+	TestPassed     = intgteststat.IntegrationTestStatusTestPassed
+	TestInvalid    = intgteststat.IntegrationTestStatusTestInvalid
+)
+
+// GetIntegrationTestStatuses parses statuses of all integration test scenarios from the Snapshot annotation
+func GetIntegrationTestStatuses(snapshot *appstudioApi.Snapshot) ([]*intgteststat.IntegrationTestStatusDetail, error) {
+	statuses, err := intgteststat.NewSnapshotIntegrationTestStatuses(snapshot.GetAnnotations()[SnapshotTestsStatusAnnotation])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse integration test statuses of snapshot %s: %+v", snapshot.GetName(), err)
+	}
+	details := statuses.GetStatuses()
+	sort.Slice(details, func(i, j int) bool { return details[i].ScenarioName < details[j].ScenarioName })
+	return details, nil
+}
+
+// snapshotStatusMatcher is a Gomega matcher over the integration test statuses of a Snapshot
+type snapshotStatusMatcher struct {
+	description string
+	match       func(statuses []*intgteststat.IntegrationTestStatusDetail) bool
+	statuses    []*intgteststat.IntegrationTestStatusDetail
+}
+
+// Match parses the statuses of the given Snapshot (or *Snapshot) and matches them
+func (matcher *snapshotStatusMatcher) Match(actual interface{}) (success bool, err error) {
+	var snapshot *appstudioApi.Snapshot
+	switch s := actual.(type) {
+	case *appstudioApi.Snapshot:
+		snapshot = s
+	case appstudioApi.Snapshot:
+		snapshot = &s
+	default:
+		return false, fmt.Errorf("expected a Snapshot, got %s", format.Object(actual, 1))
+	}
+	if snapshot == nil {
+		return false, fmt.Errorf("expected a Snapshot, got nil")
+	}
+	matcher.statuses, err = GetIntegrationTestStatuses(snapshot)
+	if err != nil {
+		return false, err
+	}
+	return matcher.match(matcher.statuses), nil
+}
+
+// FailureMessage returns failure message listing the statuses of all scenarios
+func (matcher *snapshotStatusMatcher) FailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("Expected snapshot %s\n%s, but statuses are:\n%s", snapshotName(actual), matcher.description, describeStatuses(matcher.statuses))
+}
+
+// NegatedFailureMessage returns negated failure message listing the statuses of all scenarios
+func (matcher *snapshotStatusMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("Expected snapshot %s not\n%s, but statuses are:\n%s", snapshotName(actual), matcher.description, describeStatuses(matcher.statuses))
+}
+
+func snapshotName(actual interface{}) string {
+	if o, ok := actual.(client.Object); ok && o != nil {
+		return o.GetNamespace() + "/" + o.GetName()
+	}
+	if s, ok := actual.(appstudioApi.Snapshot); ok {
+		return s.GetNamespace() + "/" + s.GetName()
+	}
+	return ""
+}
+
+func describeStatuses(statuses []*intgteststat.IntegrationTestStatusDetail) string {
+	if len(statuses) == 0 {
+		return "  (no statuses reported)"
+	}
+	var lines []string
+	for _, s := range statuses {
+		lines = append(lines, fmt.Sprintf("  %s: %s (%s)", s.ScenarioName, s.Status, s.Details))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func findStatus(statuses []*intgteststat.IntegrationTestStatusDetail, scenario string) *intgteststat.IntegrationTestStatusDetail {
+	for _, s := range statuses {
+		if s.ScenarioName == scenario {
+			return s
+		}
+	}
+	return nil
+}
+
+// HaveScenarioStatus succeeds if the status of the scenario reported in the Snapshot is the given one
+func HaveScenarioStatus(scenario string, status intgteststat.IntegrationTestStatus) types.GomegaMatcher {
+	return &snapshotStatusMatcher{
+		description: fmt.Sprintf("to have scenario %s with status %s", scenario, status),
+		match: func(statuses []*intgteststat.IntegrationTestStatusDetail) bool {
+			s := findStatus(statuses, scenario)
+			return s != nil && s.Status == status
+		},
+	}
+}
+
+// HaveDetailsContaining succeeds if the details of the scenario status reported in the Snapshot contain the substring
+func HaveDetailsContaining(scenario, substring string) types.GomegaMatcher {
+	return &snapshotStatusMatcher{
+		description: fmt.Sprintf("to have scenario %s with details containing %q", scenario, substring),
+		match: func(statuses []*intgteststat.IntegrationTestStatusDetail) bool {
+			s := findStatus(statuses, scenario)
+			return s != nil && strings.Contains(s.Details, substring)
+		},
+	}
+}
+
+// HaveScenarioTestPipelineRun succeeds if the Snapshot reports the PipelineRun as the one testing the scenario
+func HaveScenarioTestPipelineRun(scenario, pipelineRunName string) types.GomegaMatcher {
+	return &snapshotStatusMatcher{
+		description: fmt.Sprintf("to have scenario %s tested by PipelineRun %s", scenario, pipelineRunName),
+		match: func(statuses []*intgteststat.IntegrationTestStatusDetail) bool {
+			s := findStatus(statuses, scenario)
+			return s != nil && s.TestPipelineRunName == pipelineRunName
+		},
+	}
+}
+
+// AllScenariosFinished succeeds if the Snapshot reports some scenarios and all of them reached a final status
+func AllScenariosFinished() types.GomegaMatcher {
+	return &snapshotStatusMatcher{
+		description: "to have all scenarios finished",
+		match: func(statuses []*intgteststat.IntegrationTestStatusDetail) bool {
+			for _, s := range statuses {
+				if !s.Status.IsFinal() {
+					return false
+				}
+			}
+			return len(statuses) > 0
+		},
+	}
+}
+
+// WaitForSnapshotToMatch polls the Snapshot until it satisfies the matcher and returns the matching Snapshot
+func (i *IntegrationController) WaitForSnapshotToMatch(snapshot *appstudioApi.Snapshot, matcher types.GomegaMatcher, timeout time.Duration) (*appstudioApi.Snapshot, error) {
+	current := &appstudioApi.Snapshot{}
+	var matchErr error
+	err := wait.PollUntilContextTimeout(context.Background(), time.Second*5, timeout, true, func(ctx context.Context) (done bool, err error) {
+		if err := i.KubeRest().Get(ctx, client.ObjectKeyFromObject(snapshot), current); err != nil {
+			GinkgoWriter.Printf("failed to get snapshot %s/%s: %+v\n", snapshot.GetNamespace(), snapshot.GetName(), err)
+			return false, nil
+		}
+		matched, err := matcher.Match(current)
+		if err != nil {
+			matchErr = err
+			return false, nil
+		}
+		return matched, nil
+	})
+	if err != nil {
+		if matchErr != nil {
+			return nil, fmt.Errorf("failed to match snapshot %s/%s: %+v", snapshot.GetNamespace(), snapshot.GetName(), matchErr)
+		}
+		return nil, fmt.Errorf("timed out when waiting for snapshot: %s", matcher.FailureMessage(current))
+	}
+	return current, nil
+}
+
+// WaitForScenarioStatus waits until the Snapshot reports the status of the scenario
+func (i *IntegrationController) WaitForScenarioStatus(snapshot *appstudioApi.Snapshot, scenario string, status intgteststat.IntegrationTestStatus, timeout time.Duration) (*appstudioApi.Snapshot, error) {
+	return i.WaitForSnapshotToMatch(snapshot, HaveScenarioStatus(scenario, status), timeout)
+}
+
+// StatusTransition is a change of a scenario status reported in the Snapshot
+type StatusTransition struct {
+	Status  intgteststat.IntegrationTestStatus
+	Time    time.Time
+	Details string
+}
+
+// IntegrationTestStatusTimeline maps names of scenarios to their status transitions ordered by time
+type IntegrationTestStatusTimeline map[string][]StatusTransition
+
+// GetIntegrationTestStatusTimeline reconstructs the status timeline of all scenarios from the Snapshot annotation
+func GetIntegrationTestStatusTimeline(snapshot *appstudioApi.Snapshot) (IntegrationTestStatusTimeline, error) {
+	timeline := IntegrationTestStatusTimeline{}
+	return timeline, timeline.Record(snapshot)
+}
+
+// Record adds transitions observed in the Snapshot to the timeline. The annotation keeps only the latest status
+// with its start and completion time, so recording the Snapshot repeatedly (e.g. while polling it) also
+// captures intermediate statuses such as Pending.
+func (t IntegrationTestStatusTimeline) Record(snapshot *appstudioApi.Snapshot) error {
+	statuses, err := GetIntegrationTestStatuses(snapshot)
+	if err != nil {
+		return err
+	}
+	for _, s := range statuses {
+		if s.StartTime != nil {
+			t.add(s.ScenarioName, StatusTransition{Status: TestInProgress, Time: *s.StartTime})
+		}
+		if s.Status.IsFinal() && s.CompletionTime != nil {
+			t.add(s.ScenarioName, StatusTransition{Status: s.Status, Time: *s.CompletionTime, Details: s.Details})
+		} else {
+			t.add(s.ScenarioName, StatusTransition{Status: s.Status, Time: s.LastUpdateTime, Details: s.Details})
+		}
+	}
+	return nil
+}
+
+func (t IntegrationTestStatusTimeline) add(scenario string, transition StatusTransition) {
+	transitions := t[scenario]
+	for i, existing := range transitions {
+		if existing.Status == transition.Status && existing.Time.Equal(transition.Time) {
+			if transition.Details != "" {
+				transitions[i].Details = transition.Details
+			}
+			return
+		}
+	}
+	transitions = append(transitions, transition)
+	sort.SliceStable(transitions, func(i, j int) bool { return transitions[i].Time.Before(transitions[j].Time) })
+	t[scenario] = transitions
+}
+
+// String returns the timeline in a human-readable form, one scenario per line
+func (t IntegrationTestStatusTimeline) String() string {
+	scenarios := make([]string, 0, len(t))
+	for scenario := range t {
+		scenarios = append(scenarios, scenario)
+	}
+	sort.Strings(scenarios)
+
+	var lines []string
+	for _, scenario := range scenarios {
+		var steps []string
+		for _, transition := range t[scenario] {
+			steps = append(steps, fmt.Sprintf("%s at %s", transition.Status, transition.Time.UTC().Format(time.RFC3339)))
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", scenario, strings.Join(steps, " -> ")))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,79 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	appstudioApi "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func snapshotWithStatuses(statuses string) *appstudioApi.Snapshot {
+	return &appstudioApi.Snapshot{ObjectMeta: metav1.ObjectMeta{
+		Name:        "snapshot",
+		Namespace:   "tenant",
+		Annotations: map[string]string{SnapshotTestsStatusAnnotation: statuses},
+	}}
+}
+
+const testStatuses = `[
+	{"scenario": "pass", "status": "TestPassed", "lastUpdateTime": "2024-01-01T10:05:00Z", "details": "Integration test passed",
+	 "startTime": "2024-01-01T10:01:00Z", "completionTime": "2024-01-01T10:05:00Z", "testPipelineRunName": "pass-abcde"},
+	{"scenario": "pending", "status": "Pending", "lastUpdateTime": "2024-01-01T10:00:00Z", "details": "Pending"}
+]`
+
+func TestSnapshotStatusMatchers(t *testing.T) {
+	snapshot := snapshotWithStatuses(testStatuses)
+
+	for _, tc := range []struct {
+		name     string
+		matched  bool
+		actual   interface{}
+		matchErr bool
+	}{
+		{name: "passed", matched: true, actual: snapshot},
+		{name: "value", matched: true, actual: *snapshot},
+		{name: "not a snapshot", actual: "snapshot", matchErr: true},
+		{name: "invalid annotation", actual: snapshotWithStatuses(`{}`), matchErr: true},
+	} {
+		matched, err := HaveScenarioStatus("pass", TestPassed).Match(tc.actual)
+		assert.Equal(t, tc.matchErr, err != nil, tc.name)
+		assert.Equal(t, tc.matched, matched, tc.name)
+	}
+
+	matched, _ := HaveScenarioStatus("pending", TestPassed).Match(snapshot)
+	assert.False(t, matched)
+	matched, _ = HaveScenarioStatus("missing", TestPending).Match(snapshot)
+	assert.False(t, matched)
+	matched, _ = HaveDetailsContaining("pass", "test passed").Match(snapshot)
+	assert.True(t, matched)
+	matched, _ = HaveScenarioTestPipelineRun("pass", "pass-abcde").Match(snapshot)
+	assert.True(t, matched)
+
+	finished := AllScenariosFinished()
+	matched, _ = finished.Match(snapshot)
+	assert.False(t, matched)
+	assert.Equal(t, "Expected snapshot tenant/snapshot\nto have all scenarios finished, but statuses are:\n"+
+		"  pass: TestPassed (Integration test passed)\n  pending: Pending (Pending)", finished.FailureMessage(snapshot))
+	matched, _ = finished.Match(snapshotWithStatuses(""))
+	assert.False(t, matched, "no scenarios reported yet")
+}
+
+func TestIntegrationTestStatusTimeline(t *testing.T) {
+	timeline, err := GetIntegrationTestStatusTimeline(snapshotWithStatuses(`[
+		{"scenario": "pass", "status": "Pending", "lastUpdateTime": "2024-01-01T10:00:00Z", "details": "Pending"}
+	]`))
+	assert.NoError(t, err)
+	assert.NoError(t, timeline.Record(snapshotWithStatuses(testStatuses)))
+	assert.NoError(t, timeline.Record(snapshotWithStatuses(testStatuses)))
+
+	at := func(minute int) time.Time { return time.Date(2024, 1, 1, 10, minute, 0, 0, time.UTC) }
+	assert.Equal(t, []StatusTransition{
+		{Status: TestPending, Time: at(0), Details: "Pending"},
+		{Status: TestInProgress, Time: at(1)},
+		{Status: TestPassed, Time: at(5), Details: "Integration test passed"},
+	}, timeline["pass"])
+	assert.Equal(t, "pass: Pending at 2024-01-01T10:00:00Z -> InProgress at 2024-01-01T10:01:00Z -> TestPassed at 2024-01-01T10:05:00Z\n"+
+		"pending: Pending at 2024-01-01T10:00:00Z", timeline.String())
+}
@@ -0,0 +1,28 @@
+package release
+
+import (
+	"github.com/konflux-ci/e2e-tests/pkg/clients/github"
+	releaseApi "github.com/konflux-ci/release-service/api/v1alpha1"
+)
+
+// ExpectedGitHubRelease returns what the GitHub release created by the release-to-github pipeline has to contain
+// according to the data the pipeline got, i.e. the data of the ReleasePlan, the ReleasePlanAdmission and the Release merged:
+// the v<product_version> tag and the release notes (synopsis, topic, description and solution)
+func ExpectedGitHubRelease(releasePlan *releaseApi.ReleasePlan, releasePlanAdmission *releaseApi.ReleasePlanAdmission, release *releaseApi.Release) (github.ExpectedRelease, error) {
+	expected := github.ExpectedRelease{}
+	data, err := MergeReleaseData(releasePlan.Spec.Data, releasePlanAdmission.Spec.Data, release.Spec.Data)
+	if err != nil {
+		return expected, err
+	}
+	if data.ReleaseNotes != nil {
+		if data.ReleaseNotes.ProductVersion != "" {
+			expected.TagName = "v" + data.ReleaseNotes.ProductVersion
+		}
+		for _, note := range []string{data.ReleaseNotes.Synopsis, data.ReleaseNotes.Topic, data.ReleaseNotes.Description, data.ReleaseNotes.Solution} {
+			if note != "" {
+				expected.Notes = append(expected.Notes, note)
+			}
+		}
+	}
+	return expected, nil
+}
@@ -0,0 +1,48 @@
+package release
+
+import (
+	"testing"
+
+	gh "github.com/google/go-github/v44/github"
+	"github.com/konflux-ci/e2e-tests/pkg/clients/github"
+	releaseApi "github.com/konflux-ci/release-service/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestExpectedGitHubRelease(t *testing.T) {
+	releasePlan := &releaseApi.ReleasePlan{Spec: releaseApi.ReleasePlanSpec{Data: &runtime.RawExtension{Raw: []byte(`{
+		"releaseNotes": {"synopsis": "Planned synopsis", "topic": "Login fixes"},
+		"custom": {"key": "value"}
+	}`)}}}
+	releasePlanAdmission := &releaseApi.ReleasePlanAdmission{Spec: releaseApi.ReleasePlanAdmissionSpec{Data: &runtime.RawExtension{Raw: []byte(`{
+		"releaseNotes": {"synopsis": "Fixes the login", "type": "RHBA", "product_version": "1.2.0"},
+		"github": {"githubSecret": "token"}
+	}`)}}}
+	release := &releaseApi.Release{Spec: releaseApi.ReleaseSpec{Data: &runtime.RawExtension{Raw: []byte(`{
+		"releaseNotes": {"description": "Login works again"}
+	}`)}}}
+
+	data, err := MergeReleaseData(releasePlan.Spec.Data, releasePlanAdmission.Spec.Data, release.Spec.Data)
+	assert.NoError(t, err)
+	assert.Equal(t, "token", data.GitHub.GitHubSecret)
+	assert.Equal(t, map[string]interface{}{"custom": map[string]interface{}{"key": "value"}}, data.Extra)
+
+	expected, err := ExpectedGitHubRelease(releasePlan, releasePlanAdmission, release)
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.2.0", expected.TagName)
+	assert.Equal(t, []string{"Fixes the login", "Login fixes", "Login works again"}, expected.Notes)
+
+	ghRelease := &github.Release{RepositoryRelease: &gh.RepositoryRelease{
+		TagName: gh.String("v1.2.0"),
+		Body:    gh.String("Fixes the login\nLogin fixes\nLogin works again"),
+	}}
+	assert.NoError(t, github.VerifyRelease(ghRelease, expected))
+	ghRelease.TagName = gh.String("v1.1.0")
+	assert.ErrorContains(t, github.VerifyRelease(ghRelease, expected), "release has tag v1.1.0, expected v1.2.0")
+
+	expected, err = ExpectedGitHubRelease(&releaseApi.ReleasePlan{}, &releaseApi.ReleasePlanAdmission{}, &releaseApi.Release{})
+	assert.NoError(t, err)
+	assert.Empty(t, expected.TagName)
+	assert.Empty(t, expected.Notes)
+}
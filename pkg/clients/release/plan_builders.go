@@ -0,0 +1,381 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/konflux-ci/e2e-tests/pkg/utils"
+	"github.com/konflux-ci/e2e-tests/pkg/utils/tekton"
+	releaseApi "github.com/konflux-ci/release-service/api/v1alpha1"
+	releaseMetadata "github.com/konflux-ci/release-service/metadata"
+	tektonutils "github.com/konflux-ci/release-service/tekton/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// validateObjectMeta checks the name and the namespace of the object are set and valid
+func validateObjectMeta(kind, name, namespace string) []error {
+	var errs []error
+	for _, msg := range validation.IsDNS1123Subdomain(name) {
+		errs = append(errs, fmt.Errorf("invalid %s name %q: %s", kind, name, msg))
+	}
+	if namespace == "" {
+		errs = append(errs, fmt.Errorf("namespace is not set"))
+	}
+	return errs
+}
+
+// validatePipelineRef checks the resolver and its params locating the pipeline
+func validatePipelineRef(pipelineRef tektonutils.PipelineRef) []error {
+	var errs []error
+	if pipelineRef.Resolver == "" {
+		errs = append(errs, fmt.Errorf("pipeline resolver is not set"))
+	}
+	params := map[string]string{}
+	for _, p := range pipelineRef.Params {
+		if _, ok := params[p.Name]; ok {
+			errs = append(errs, fmt.Errorf("pipeline resolver param %s is set more than once", p.Name))
+		}
+		params[p.Name] = p.Value
+	}
+	for _, name := range tekton.MissingResolverParams(pipelineRef.Resolver, params) {
+		errs = append(errs, fmt.Errorf("%s resolver requires param %s", pipelineRef.Resolver, name))
+	}
+	return errs
+}
+
+// catalogPipelineRef returns a reference to the pipeline at the path in the git repository of release-service-catalog
+func catalogPipelineRef(url, revision, pathInRepo string) tektonutils.PipelineRef {
+	return tektonutils.PipelineRef{
+		Resolver: "git",
+		Params: []tektonutils.Param{
+			{Name: "url", Value: url},
+			{Name: "revision", Value: revision},
+			{Name: "pathInRepo", Value: pathInRepo},
+		},
+	}
+}
+
+// ReleasePlanBuilder creates ReleasePlans with chainable options, e.g.
+//
+//	releasePlan, err := fw.AsKubeAdmin.ReleaseController.NewReleasePlanBuilder(name, devNamespace, appName, managedNamespace).
+//		WithReleaseNotes(release.ReleaseNotesData{Synopsis: "test synopsis"}).
+//		Create()
+//
+// Invalid options are reported by Build and Create.
+type ReleasePlanBuilder struct {
+	controller  *ReleaseController
+	releasePlan *releaseApi.ReleasePlan
+	data        *ReleaseData
+}
+
+// NewReleasePlanBuilder returns a builder of an auto-releasing ReleasePlan of the application to the target namespace
+func (r *ReleaseController) NewReleasePlanBuilder(name, namespace, application, targetNamespace string) *ReleasePlanBuilder {
+	return &ReleasePlanBuilder{
+		controller: r,
+		releasePlan: &releaseApi.ReleasePlan{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels: map[string]string{
+					releaseMetadata.AutoReleaseLabel: "true",
+					releaseMetadata.AttributionLabel: "true",
+				},
+			},
+			Spec: releaseApi.ReleasePlanSpec{
+				Application: application,
+				Target:      targetNamespace,
+			},
+		},
+		data: &ReleaseData{},
+	}
+}
+
+// WithAutoRelease sets whether a Release is created automatically for every Snapshot which passed the tests
+func (b *ReleasePlanBuilder) WithAutoRelease(autoRelease bool) *ReleasePlanBuilder {
+	b.releasePlan.Labels[releaseMetadata.AutoReleaseLabel] = strconv.FormatBool(autoRelease)
+	return b
+}
+
+// WithLabels adds labels to the ReleasePlan
+func (b *ReleasePlanBuilder) WithLabels(labels map[string]string) *ReleasePlanBuilder {
+	b.releasePlan.Labels = utils.MergeMaps(b.releasePlan.Labels, labels)
+	return b
+}
+
+// WithData sets the data passed to the release pipelines, it replaces any data set before
+func (b *ReleasePlanBuilder) WithData(data *ReleaseData) *ReleasePlanBuilder {
+	b.data = data
+	return b
+}
+
+// WithReleaseNotes sets the releaseNotes section of the data
+func (b *ReleasePlanBuilder) WithReleaseNotes(releaseNotes ReleaseNotesData) *ReleasePlanBuilder {
+	b.data.ReleaseNotes = &releaseNotes
+	return b
+}
+
+// WithTenantPipeline runs the pipeline in the namespace of the ReleasePlan, the pipeline is resolved by the resolver
+func (b *ReleasePlanBuilder) WithTenantPipeline(pipelineRef tektonutils.PipelineRef, serviceAccountName string, params ...tektonutils.Param) *ReleasePlanBuilder {
+	b.releasePlan.Spec.Pipeline = &tektonutils.ParameterizedPipeline{
+		Pipeline: tektonutils.Pipeline{PipelineRef: pipelineRef, ServiceAccountName: serviceAccountName},
+		Params:   params,
+	}
+	return b
+}
+
+// Data returns the data of the ReleasePlan, e.g. to validate a ReleasePlanAdmission against it
+func (b *ReleasePlanBuilder) Data() *ReleaseData {
+	return b.data
+}
+
+// Build validates the options and returns the ReleasePlan object without creating it
+func (b *ReleasePlanBuilder) Build() (*releaseApi.ReleasePlan, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	releasePlan := b.releasePlan.DeepCopy()
+	data, err := b.data.RawExtension()
+	if err != nil {
+		return nil, err
+	}
+	if string(data.Raw) != "{}" {
+		releasePlan.Spec.Data = data
+	}
+	return releasePlan, nil
+}
+
+func (b *ReleasePlanBuilder) validate() error {
+	releasePlan := b.releasePlan
+	errs := validateObjectMeta("release plan", releasePlan.Name, releasePlan.Namespace)
+	if releasePlan.Spec.Application == "" {
+		errs = append(errs, fmt.Errorf("application is not set"))
+	}
+	if pipeline := releasePlan.Spec.Pipeline; pipeline != nil {
+		errs = append(errs, validatePipelineRef(pipeline.PipelineRef)...)
+		params := map[string]bool{}
+		for _, p := range pipeline.Params {
+			if params[p.Name] {
+				errs = append(errs, fmt.Errorf("tenant pipeline param %s is set more than once", p.Name))
+			}
+			params[p.Name] = true
+		}
+	} else if releasePlan.Spec.Target == "" {
+		errs = append(errs, fmt.Errorf("either the target namespace or the tenant pipeline has to be set"))
+	}
+	if err := b.data.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Create validates the options and creates the ReleasePlan
+func (b *ReleasePlanBuilder) Create() (*releaseApi.ReleasePlan, error) {
+	releasePlan, err := b.Build()
+	if err != nil {
+		return nil, fmt.Errorf("invalid release plan %s: %+v", b.releasePlan.Name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	if err := b.controller.KubeRest().Create(ctx, releasePlan); err != nil {
+		return nil, err
+	}
+	return releasePlan, nil
+}
+
+// ReleasePlanAdmissionBuilder creates ReleasePlanAdmissions with chainable options, e.g.
+//
+//	rpa, err := fw.AsKubeAdmin.ReleaseController.NewReleasePlanAdmissionBuilder(name, managedNamespace, devNamespace, policyName, serviceAccountName, appName).
+//		WithCatalogPipeline(releasecommon.RelSvcCatalogURL, releasecommon.RelSvcCatalogRevision, "pipelines/rh-push-to-external-registry/rh-push-to-external-registry.yaml").
+//		WithMappingComponent(compName, "quay.io/org/repo", "latest").
+//		WithPyxis("stage", "pyxis").
+//		Create()
+//
+// Besides the options it validates the data contains everything the release-service-catalog pipeline expects,
+// see ReleasePipelineRequirements, so a mistake fails the test right away instead of failing the managed pipeline.
+type ReleasePlanAdmissionBuilder struct {
+	controller           *ReleaseController
+	releasePlanAdmission *releaseApi.ReleasePlanAdmission
+	data                 *ReleaseData
+	releasePlanData      *ReleaseData
+	// path of the release-service-catalog pipeline set by WithCatalogPipeline
+	catalogPipelinePath string
+}
+
+// NewReleasePlanAdmissionBuilder returns a builder of an auto-releasing ReleasePlanAdmission of the applications
+// released from the origin namespace, using the Enterprise Contract policy and running the pipeline with the service account
+func (r *ReleaseController) NewReleasePlanAdmissionBuilder(name, namespace, origin, policy, serviceAccountName string, applications ...string) *ReleasePlanAdmissionBuilder {
+	return &ReleasePlanAdmissionBuilder{
+		controller: r,
+		releasePlanAdmission: &releaseApi.ReleasePlanAdmission{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels: map[string]string{
+					releaseMetadata.AutoReleaseLabel: "true",
+				},
+			},
+			Spec: releaseApi.ReleasePlanAdmissionSpec{
+				Applications: applications,
+				Origin:       origin,
+				Pipeline:     &tektonutils.Pipeline{ServiceAccountName: serviceAccountName},
+				Policy:       policy,
+			},
+		},
+		data: &ReleaseData{},
+	}
+}
+
+// WithAutoRelease sets whether Releases of the ReleasePlans matched by the ReleasePlanAdmission are released automatically
+func (b *ReleasePlanAdmissionBuilder) WithAutoRelease(autoRelease bool) *ReleasePlanAdmissionBuilder {
+	b.releasePlanAdmission.Labels[releaseMetadata.AutoReleaseLabel] = strconv.FormatBool(autoRelease)
+	return b
+}
+
+// WithEnvironment sets the environment the applications are deployed to
+func (b *ReleasePlanAdmissionBuilder) WithEnvironment(environment string) *ReleasePlanAdmissionBuilder {
+	b.releasePlanAdmission.Spec.Environment = environment
+	return b
+}
+
+// WithPipelineRef sets the reference to the release pipeline
+func (b *ReleasePlanAdmissionBuilder) WithPipelineRef(pipelineRef tektonutils.PipelineRef) *ReleasePlanAdmissionBuilder {
+	b.releasePlanAdmission.Spec.Pipeline.PipelineRef = pipelineRef
+	b.catalogPipelinePath = ""
+	return b
+}
+
+// WithCatalogPipeline resolves the release pipeline at the path from the git repository of release-service-catalog
+func (b *ReleasePlanAdmissionBuilder) WithCatalogPipeline(url, revision, pathInRepo string) *ReleasePlanAdmissionBuilder {
+	b.WithPipelineRef(catalogPipelineRef(url, revision, pathInRepo))
+	b.catalogPipelinePath = pathInRepo
+	return b
+}
+
+// WithData sets the data passed to the release pipeline, it replaces any data set before
+func (b *ReleasePlanAdmissionBuilder) WithData(data *ReleaseData) *ReleasePlanAdmissionBuilder {
+	b.data = data
+	return b
+}
+
+// WithMappingComponent maps the component to the repository it is pushed to with the tags
+func (b *ReleasePlanAdmissionBuilder) WithMappingComponent(name, repository string, tags ...string) *ReleasePlanAdmissionBuilder {
+	if b.data.Mapping == nil {
+		b.data.Mapping = &MappingData{}
+	}
+	b.data.Mapping.Components = append(b.data.Mapping.Components, MappingComponent{Name: name, Repository: repository, Tags: tags})
+	return b
+}
+
+// WithDefaultTags sets the tags added to all mapped components
+func (b *ReleasePlanAdmissionBuilder) WithDefaultTags(tags ...string) *ReleasePlanAdmissionBuilder {
+	if b.data.Mapping == nil {
+		b.data.Mapping = &MappingData{}
+	}
+	b.data.Mapping.Defaults = &MappingDefaults{Tags: tags}
+	return b
+}
+
+// WithImages sets the images section of the data
+func (b *ReleasePlanAdmissionBuilder) WithImages(images ImagesData) *ReleasePlanAdmissionBuilder {
+	b.data.Images = &images
+	return b
+}
+
+// WithPyxis uploads the released images to the Pyxis server (e.g. stage) with the client certificate from the secret
+func (b *ReleasePlanAdmissionBuilder) WithPyxis(server, secret string) *ReleasePlanAdmissionBuilder {
+	b.data.Pyxis = &PyxisData{Server: server, Secret: secret}
+	return b
+}
+
+// WithReleaseNotes sets the releaseNotes section of the data
+func (b *ReleasePlanAdmissionBuilder) WithReleaseNotes(releaseNotes ReleaseNotesData) *ReleasePlanAdmissionBuilder {
+	b.data.ReleaseNotes = &releaseNotes
+	return b
+}
+
+// WithSigning signs the released content with the configuration from the config map
+func (b *ReleasePlanAdmissionBuilder) WithSigning(configMapName string) *ReleasePlanAdmissionBuilder {
+	b.data.Sign = &SignData{ConfigMapName: configMapName}
+	return b
+}
+
+// WithDataSection sets a section of the data which has no typed equivalent, e.g. fbc
+func (b *ReleasePlanAdmissionBuilder) WithDataSection(name string, value interface{}) *ReleasePlanAdmissionBuilder {
+	if b.data.Extra == nil {
+		b.data.Extra = map[string]interface{}{}
+	}
+	b.data.Extra[name] = value
+	return b
+}
+
+// ForReleasePlanData takes the data of the ReleasePlan into account when validating the data expected by the release pipeline
+func (b *ReleasePlanAdmissionBuilder) ForReleasePlanData(data *ReleaseData) *ReleasePlanAdmissionBuilder {
+	b.releasePlanData = data
+	return b
+}
+
+// Build validates the options and returns the ReleasePlanAdmission object without creating it
+func (b *ReleasePlanAdmissionBuilder) Build() (*releaseApi.ReleasePlanAdmission, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+	releasePlanAdmission := b.releasePlanAdmission.DeepCopy()
+	data, err := b.data.RawExtension()
+	if err != nil {
+		return nil, err
+	}
+	if string(data.Raw) != "{}" {
+		releasePlanAdmission.Spec.Data = data
+	}
+	return releasePlanAdmission, nil
+}
+
+func (b *ReleasePlanAdmissionBuilder) validate() error {
+	rpa := b.releasePlanAdmission
+	errs := validateObjectMeta("release plan admission", rpa.Name, rpa.Namespace)
+	if rpa.Spec.Origin == "" {
+		errs = append(errs, fmt.Errorf("origin namespace is not set"))
+	}
+	if len(rpa.Spec.Applications) == 0 {
+		errs = append(errs, fmt.Errorf("no application is set"))
+	}
+	if rpa.Spec.Policy == "" {
+		errs = append(errs, fmt.Errorf("policy is not set"))
+	}
+	if serviceAccountName := rpa.Spec.Pipeline.ServiceAccountName; serviceAccountName != "" {
+		for _, msg := range validation.IsDNS1123Label(serviceAccountName) {
+			errs = append(errs, fmt.Errorf("invalid service account name %q: %s", serviceAccountName, msg))
+		}
+	}
+	errs = append(errs, validatePipelineRef(rpa.Spec.Pipeline.PipelineRef)...)
+	if err := b.data.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if b.catalogPipelinePath != "" {
+		if err := ValidateReleasePipelineData(b.catalogPipelinePath, b.releasePlanData, b.data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Create validates the options and creates the ReleasePlanAdmission
+func (b *ReleasePlanAdmissionBuilder) Create() (*releaseApi.ReleasePlanAdmission, error) {
+	rpa, err := b.Build()
+	if err != nil {
+		return nil, fmt.Errorf("invalid release plan admission %s: %+v", b.releasePlanAdmission.Name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*1)
+	defer cancel()
+	if err := b.controller.KubeRest().Create(ctx, rpa); err != nil {
+		return nil, err
+	}
+	return rpa, nil
+}
@@ -0,0 +1,100 @@
+package release
+
+import (
+	"testing"
+
+	releaseMetadata "github.com/konflux-ci/release-service/metadata"
+	tektonutils "github.com/konflux-ci/release-service/tekton/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	catalogURL          = "https://github.com/konflux-ci/release-service-catalog"
+	externalRegistryRef = "pipelines/rh-push-to-external-registry/rh-push-to-external-registry.yaml"
+	advisoriesRef       = "pipelines/rh-advisories/rh-advisories.yaml"
+)
+
+func TestReleasePlanAdmissionBuilder(t *testing.T) {
+	rpa, err := (&ReleaseController{}).NewReleasePlanAdmissionBuilder("rpa", "managed", "dev", "policy", "release-service-account", "app").
+		WithCatalogPipeline(catalogURL, "staging", externalRegistryRef).
+		WithMappingComponent("comp", "quay.io/org/comp").
+		WithDefaultTags("latest").
+		WithPyxis("stage", "pyxis").
+		WithDataSection("custom", map[string]string{"key": "value"}).
+		WithAutoRelease(false).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "false", rpa.Labels[releaseMetadata.AutoReleaseLabel])
+	assert.Equal(t, []string{"app"}, rpa.Spec.Applications)
+	assert.Equal(t, "release-service-account", rpa.Spec.Pipeline.ServiceAccountName)
+	assert.Equal(t, tektonutils.Param{Name: "pathInRepo", Value: externalRegistryRef}, rpa.Spec.Pipeline.PipelineRef.Params[2])
+	assert.JSONEq(t, `{
+		"mapping": {"components": [{"name": "comp", "repository": "quay.io/org/comp"}], "defaults": {"tags": ["latest"]}},
+		"pyxis": {"server": "stage", "secret": "pyxis"},
+		"custom": {"key": "value"}
+	}`, string(rpa.Spec.Data.Raw))
+}
+
+func TestReleasePlanAdmissionBuilderValidation(t *testing.T) {
+	_, err := (&ReleaseController{}).NewReleasePlanAdmissionBuilder("Invalid_Name", "managed", "", "", "release-service-account").
+		WithPipelineRef(tektonutils.PipelineRef{Resolver: "git", Params: []tektonutils.Param{{Name: "url", Value: catalogURL}}}).
+		WithMappingComponent("comp", "").
+		WithMappingComponent("comp", "quay.io/org/comp").
+		WithReleaseNotes(ReleaseNotesData{Type: "RHXA"}).
+		Build()
+	for _, msg := range []string{
+		`invalid release plan admission name "Invalid_Name"`,
+		"origin namespace is not set",
+		"no application is set",
+		"policy is not set",
+		"git resolver requires param revision",
+		"git resolver requires param pathInRepo",
+		"mapping.components[0] has no repository",
+		"component comp is mapped more than once",
+		"releaseNotes.type RHXA is not one of [RHSA RHBA RHEA]",
+	} {
+		assert.ErrorContains(t, err, msg)
+	}
+
+	_, err = (&ReleaseController{}).NewReleasePlanAdmissionBuilder("rpa", "managed", "dev", "policy", "", "app").
+		WithCatalogPipeline(catalogURL, "staging", externalRegistryRef).
+		WithMappingComponent("comp", "quay.io/org/comp").
+		Create()
+	assert.EqualError(t, err, "invalid release plan admission rpa: release pipeline "+externalRegistryRef+" requires data [pyxis.server pyxis.secret]")
+}
+
+func TestValidateReleasePipelineData(t *testing.T) {
+	releasePlanData := &ReleaseData{ReleaseNotes: &ReleaseNotesData{Synopsis: "synopsis", Type: "RHBA"}}
+	rpaData := &ReleaseData{
+		Mapping:      &MappingData{Components: []MappingComponent{{Name: "comp", Repository: "quay.io/org/comp"}}},
+		Pyxis:        &PyxisData{Server: "stage", Secret: "pyxis"},
+		Sign:         &SignData{ConfigMapName: "signing-config"},
+		ReleaseNotes: &ReleaseNotesData{CPE: "cpe:/a:example.com", ProductID: "555", ProductName: "product"},
+	}
+	assert.EqualError(t, ValidateReleasePipelineData(advisoriesRef, nil, rpaData), "release pipeline "+advisoriesRef+" requires data [releaseNotes.product_version releaseNotes.type]")
+
+	rpaData.ReleaseNotes.ProductVersion = "v1.0"
+	// the type is merged from the release plan
+	assert.NoError(t, ValidateReleasePipelineData(advisoriesRef, releasePlanData, rpaData))
+	assert.EqualError(t, ValidateReleasePipelineData("pipelines/unknown/unknown.yaml", nil, nil), "release pipeline pipelines/unknown/unknown.yaml has no known data requirements, add them to ReleasePipelineRequirements")
+}
+
+func TestReleasePlanBuilder(t *testing.T) {
+	releasePlan, err := (&ReleaseController{}).NewReleasePlanBuilder("rp", "dev", "app", "managed").
+		WithReleaseNotes(ReleaseNotesData{Synopsis: "synopsis", References: []string{"https://server.com/ref1"}}).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "true", releasePlan.Labels[releaseMetadata.AutoReleaseLabel])
+	assert.JSONEq(t, `{"releaseNotes": {"synopsis": "synopsis", "references": ["https://server.com/ref1"]}}`, string(releasePlan.Spec.Data.Raw))
+
+	releasePlan, err = (&ReleaseController{}).NewReleasePlanBuilder("rp", "dev", "app", "managed").Build()
+	assert.NoError(t, err)
+	assert.Nil(t, releasePlan.Spec.Data)
+
+	_, err = (&ReleaseController{}).NewReleasePlanBuilder("rp", "dev", "", "").
+		WithTenantPipeline(tektonutils.PipelineRef{Resolver: "cluster"}, "", tektonutils.Param{Name: "p"}, tektonutils.Param{Name: "p"}).
+		Build()
+	assert.ErrorContains(t, err, "application is not set")
+	assert.ErrorContains(t, err, "cluster resolver requires param name")
+	assert.ErrorContains(t, err, "tenant pipeline param p is set more than once")
+}
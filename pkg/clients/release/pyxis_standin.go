@@ -0,0 +1,176 @@
+package release
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	pyxisImagesPath           = "/v1/images/id/"
+	pyxisContentManifestsPath = "/v1/content-manifests/id/"
+)
+
+// PyxisStandIn is an in-process Pyxis serving images and content manifests from fixtures.
+// Like Pyxis it requires clients to authenticate with a certificate, the one returned by Endpoint.
+type PyxisStandIn struct {
+	server           *httptest.Server
+	clientCert       []byte
+	clientKey        []byte
+	rootCAs          *x509.CertPool
+	images           map[string][]byte
+	contentManifests map[string][]byte
+}
+
+// NewPyxisStandIn starts the stand-in serving fixtures from the directory:
+// images from images/<image id>.json and content manifests from content-manifests/<content manifest id>.json
+func NewPyxisStandIn(fixturesDir string) (*PyxisStandIn, error) {
+	p := &PyxisStandIn{}
+	var err error
+	if p.images, err = loadPyxisFixtures(filepath.Join(fixturesDir, "images")); err != nil {
+		return nil, err
+	}
+	if p.contentManifests, err = loadPyxisFixtures(filepath.Join(fixturesDir, "content-manifests")); err != nil {
+		return nil, err
+	}
+
+	caCert, caKey, err := newCertificate("pyxis-stand-in-ca", nil, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	serverCert, serverKey, err := newCertificate("pyxis-stand-in", caCert, caKey, false)
+	if err != nil {
+		return nil, err
+	}
+	clientCert, clientKey, err := newCertificate("pyxis-stand-in-client", caCert, caKey, false)
+	if err != nil {
+		return nil, err
+	}
+	if p.clientCert, p.clientKey, err = encodeCertificate(clientCert, clientKey); err != nil {
+		return nil, err
+	}
+	p.rootCAs = x509.NewCertPool()
+	p.rootCAs.AddCert(caCert)
+
+	p.server = httptest.NewUnstartedServer(http.HandlerFunc(p.serveHTTP))
+	p.server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.Raw}, PrivateKey: serverKey, Leaf: serverCert}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    p.rootCAs,
+	}
+	p.server.StartTLS()
+	return p, nil
+}
+
+// Endpoint returns the API of the stand-in with a client certificate accepted by it
+func (p *PyxisStandIn) Endpoint() PyxisEndpoint {
+	return PyxisEndpoint{
+		URL:     p.server.URL + "/v1",
+		Cert:    p.clientCert,
+		Key:     p.clientKey,
+		RootCAs: p.rootCAs,
+	}
+}
+
+// Close shuts the stand-in down
+func (p *PyxisStandIn) Close() {
+	p.server.Close()
+}
+
+func (p *PyxisStandIn) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	var body []byte
+	var found bool
+	if r.Method == http.MethodGet {
+		if id, ok := strings.CutPrefix(r.URL.Path, pyxisImagesPath); ok {
+			body, found = p.images[id]
+		} else if id, ok := strings.CutPrefix(r.URL.Path, pyxisContentManifestsPath); ok {
+			body, found = p.contentManifests[id]
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprintf(w, `{"detail": "The requested URL %s was not found on the server.", "status": 404, "title": "Not Found", "type": "about:blank"}`, r.URL.Path)
+		return
+	}
+	_, _ = w.Write(body)
+}
+
+// loadPyxisFixtures returns content of the JSON files in the directory by their names without the extension
+func loadPyxisFixtures(dir string) (map[string][]byte, error) {
+	fixtures := map[string][]byte{}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Pyxis fixtures in %s: %+v", dir, err)
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Pyxis fixture %s: %+v", file, err)
+		}
+		fixtures[strings.TrimSuffix(filepath.Base(file), ".json")] = content
+	}
+	return fixtures, nil
+}
+
+// newCertificate returns a certificate for localhost signed by the parent, or a self-signed CA when the parent is nil
+func newCertificate(commonName string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, isCA bool) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key of %s: %+v", commonName, err)
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate serial number of %s: %+v", commonName, err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	if isCA {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate of %s: %+v", commonName, err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse certificate of %s: %+v", commonName, err)
+	}
+	return cert, key, nil
+}
+
+// encodeCertificate returns the certificate and the key PEM encoded, as they are stored in the Pyxis secret
+func encodeCertificate(cert *x509.Certificate, key *ecdsa.PrivateKey) ([]byte, []byte, error) {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal key of %s: %+v", cert.Subject.CommonName, err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), nil
+}
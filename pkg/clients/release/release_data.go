@@ -0,0 +1,312 @@
+package release
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// ReleaseData is the data section of a ReleasePlan or ReleasePlanAdmission passed to the release pipelines.
+// The sections used by the release-service-catalog pipelines are typed, any other section can be set in Extra.
+type ReleaseData struct {
+	Mapping      *MappingData      `json:"mapping,omitempty"`
+	Images       *ImagesData       `json:"images,omitempty"`
+	Pyxis        *PyxisData        `json:"pyxis,omitempty"`
+	ReleaseNotes *ReleaseNotesData `json:"releaseNotes,omitempty"`
+	Sign         *SignData         `json:"sign,omitempty"`
+	GitHub       *GitHubData       `json:"github,omitempty"`
+	// Extra are other sections of the data (e.g. fbc), they cannot override the typed sections
+	Extra map[string]interface{} `json:"-"`
+}
+
+// MappingData maps the components of the released Snapshot to the repositories they are pushed to
+type MappingData struct {
+	Components []MappingComponent `json:"components,omitempty"`
+	Defaults   *MappingDefaults   `json:"defaults,omitempty"`
+}
+
+// MappingComponent is the repository and tags a component is released to
+type MappingComponent struct {
+	Name       string   `json:"name"`
+	Repository string   `json:"repository"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+// MappingDefaults are applied to all mapped components
+type MappingDefaults struct {
+	Tags []string `json:"tags,omitempty"`
+}
+
+// ImagesData configures the tags added to the released images
+type ImagesData struct {
+	AddGitShaTag    *bool    `json:"addGitShaTag,omitempty"`
+	AddSourceShaTag *bool    `json:"addSourceShaTag,omitempty"`
+	AddTimestampTag *bool    `json:"addTimestampTag,omitempty"`
+	FloatingTags    []string `json:"floatingTags,omitempty"`
+}
+
+// PyxisData is the Pyxis instance the images are uploaded to and the secret with its client certificate
+type PyxisData struct {
+	Server string `json:"server"`
+	Secret string `json:"secret"`
+}
+
+// ReleaseNotesData are the release notes of the advisory created for the release
+type ReleaseNotesData struct {
+	Description    string   `json:"description,omitempty"`
+	References     []string `json:"references,omitempty"`
+	Solution       string   `json:"solution,omitempty"`
+	Synopsis       string   `json:"synopsis,omitempty"`
+	Topic          string   `json:"topic,omitempty"`
+	CPE            string   `json:"cpe,omitempty"`
+	ProductID      string   `json:"product_id,omitempty"`
+	ProductName    string   `json:"product_name,omitempty"`
+	ProductStream  string   `json:"product_stream,omitempty"`
+	ProductVersion string   `json:"product_version,omitempty"`
+	Type           string   `json:"type,omitempty"`
+}
+
+// SignData is the config map of the signing pipeline
+type SignData struct {
+	ConfigMapName string `json:"configMapName"`
+}
+
+// GitHubData is the secret with the token used to create GitHub releases
+type GitHubData struct {
+	GitHubSecret string `json:"githubSecret"`
+}
+
+// advisoryTypes are the types of advisories accepted in the release notes
+var advisoryTypes = []string{"RHSA", "RHBA", "RHEA"}
+
+// ToMap returns the data as it is passed to the release pipelines
+func (d *ReleaseData) ToMap() (map[string]interface{}, error) {
+	raw, err := json.Marshal(d)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal release data: %+v", err)
+	}
+	data := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal release data: %+v", err)
+	}
+	for section, value := range d.Extra {
+		if _, ok := data[section]; ok {
+			return nil, fmt.Errorf("extra data section %s is already set", section)
+		}
+		data[section] = value
+	}
+	return data, nil
+}
+
+// RawExtension returns the data to be set in a ReleasePlan or ReleasePlanAdmission spec
+func (d *ReleaseData) RawExtension() (*runtime.RawExtension, error) {
+	data, err := d.ToMap()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal release data: %+v", err)
+	}
+	return &runtime.RawExtension{Raw: raw}, nil
+}
+
+// ParseReleaseData returns the data of a Release, ReleasePlan or ReleasePlanAdmission spec, sections which are not typed are set in Extra
+func ParseReleaseData(raw *runtime.RawExtension) (*ReleaseData, error) {
+	data := &ReleaseData{}
+	if raw == nil || len(raw.Raw) == 0 {
+		return data, nil
+	}
+	if err := json.Unmarshal(raw.Raw, data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal release data: %+v", err)
+	}
+	sections := map[string]interface{}{}
+	if err := json.Unmarshal(raw.Raw, &sections); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal release data: %+v", err)
+	}
+	typed, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal release data: %+v", err)
+	}
+	typedSections := map[string]interface{}{}
+	if err := json.Unmarshal(typed, &typedSections); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal release data: %+v", err)
+	}
+	for section, value := range sections {
+		if _, ok := typedSections[section]; !ok {
+			if data.Extra == nil {
+				data.Extra = map[string]interface{}{}
+			}
+			data.Extra[section] = value
+		}
+	}
+	return data, nil
+}
+
+// Validate checks the typed sections of the data are complete
+func (d *ReleaseData) Validate() error {
+	var errs []error
+	if d.Mapping != nil {
+		components := map[string]bool{}
+		for i, c := range d.Mapping.Components {
+			if c.Name == "" {
+				errs = append(errs, fmt.Errorf("mapping.components[%d] has no name", i))
+			}
+			if c.Repository == "" {
+				errs = append(errs, fmt.Errorf("mapping.components[%d] has no repository", i))
+			}
+			if components[c.Name] {
+				errs = append(errs, fmt.Errorf("component %s is mapped more than once", c.Name))
+			}
+			components[c.Name] = true
+		}
+	}
+	if d.Pyxis != nil {
+		if d.Pyxis.Server == "" {
+			errs = append(errs, fmt.Errorf("pyxis.server is not set"))
+		}
+		if d.Pyxis.Secret == "" {
+			errs = append(errs, fmt.Errorf("pyxis.secret is not set"))
+		}
+	}
+	if d.ReleaseNotes != nil && d.ReleaseNotes.Type != "" && !contains(advisoryTypes, d.ReleaseNotes.Type) {
+		errs = append(errs, fmt.Errorf("releaseNotes.type %s is not one of %v", d.ReleaseNotes.Type, advisoryTypes))
+	}
+	if d.Sign != nil && d.Sign.ConfigMapName == "" {
+		errs = append(errs, fmt.Errorf("sign.configMapName is not set"))
+	}
+	if d.GitHub != nil && d.GitHub.GitHubSecret == "" {
+		errs = append(errs, fmt.Errorf("github.githubSecret is not set"))
+	}
+	return errors.Join(errs...)
+}
+
+// ReleasePipelineRequirements are the data keys (as dot separated paths) the release-service-catalog pipelines
+// expect in the merged data of the ReleasePlan and ReleasePlanAdmission, by the path of the pipeline in the catalog.
+// A catalog pipeline used by the tests has to be added here, ValidateReleasePipelineData fails for unknown pipelines.
+var ReleasePipelineRequirements = map[string][]string{
+	"pipelines/push-to-external-registry/push-to-external-registry.yaml": {
+		"mapping.components",
+	},
+	"pipelines/rh-push-to-external-registry/rh-push-to-external-registry.yaml": {
+		"mapping.components", "pyxis.server", "pyxis.secret",
+	},
+	"pipelines/rh-push-to-registry-redhat-io/rh-push-to-registry-redhat-io.yaml": {
+		"mapping.components", "pyxis.server", "pyxis.secret", "sign.configMapName",
+	},
+	"pipelines/rh-advisories/rh-advisories.yaml": {
+		"mapping.components", "pyxis.server", "pyxis.secret", "sign.configMapName",
+		"releaseNotes.cpe", "releaseNotes.product_id", "releaseNotes.product_name", "releaseNotes.product_version", "releaseNotes.type",
+	},
+	"pipelines/release-to-github/release-to-github.yaml": {
+		"github.githubSecret", "sign.configMapName",
+	},
+	"pipelines/fbc-release/fbc-release.yaml": {
+		"fbc.fromIndex", "fbc.targetIndex", "fbc.binaryImage", "sign.configMapName",
+	},
+}
+
+// missingDataKeys returns the required keys which are not set (or are empty) in the data
+func missingDataKeys(data map[string]interface{}, required []string) []string {
+	var missing []string
+	for _, key := range required {
+		var value interface{} = data
+		for _, part := range strings.Split(key, ".") {
+			section, ok := value.(map[string]interface{})
+			if !ok {
+				value = nil
+				break
+			}
+			value = section[part]
+		}
+		switch v := value.(type) {
+		case nil:
+			missing = append(missing, key)
+		case string:
+			if v == "" {
+				missing = append(missing, key)
+			}
+		case []interface{}:
+			if len(v) == 0 {
+				missing = append(missing, key)
+			}
+		}
+	}
+	return missing
+}
+
+// mergeData merges the ReleasePlanAdmission data into the ReleasePlan data the same way release-service does,
+// values of the ReleasePlanAdmission take precedence
+func mergeData(releasePlanData, releasePlanAdmissionData map[string]interface{}) map[string]interface{} {
+	merged := map[string]interface{}{}
+	for k, v := range releasePlanData {
+		merged[k] = v
+	}
+	for k, v := range releasePlanAdmissionData {
+		planSection, planOk := merged[k].(map[string]interface{})
+		admissionSection, admissionOk := v.(map[string]interface{})
+		if planOk && admissionOk {
+			merged[k] = mergeData(planSection, admissionSection)
+		} else {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// MergeReleaseData returns the data passed to a release pipeline, the data of the ReleasePlan, the ReleasePlanAdmission
+// and the Release (in this order) merged by mergeData
+func MergeReleaseData(raws ...*runtime.RawExtension) (*ReleaseData, error) {
+	merged := map[string]interface{}{}
+	for _, raw := range raws {
+		if raw == nil || len(raw.Raw) == 0 {
+			continue
+		}
+		data := map[string]interface{}{}
+		if err := json.Unmarshal(raw.Raw, &data); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal release data: %+v", err)
+		}
+		merged = mergeData(merged, data)
+	}
+	raw, err := json.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal release data: %+v", err)
+	}
+	return ParseReleaseData(&runtime.RawExtension{Raw: raw})
+}
+
+// ValidateReleasePipelineData checks the merged data of the ReleasePlan and ReleasePlanAdmission contains
+// everything the catalog pipeline at the path expects.
+func ValidateReleasePipelineData(pathInRepo string, releasePlanData, releasePlanAdmissionData *ReleaseData) error {
+	required, ok := ReleasePipelineRequirements[pathInRepo]
+	if !ok {
+		return fmt.Errorf("release pipeline %s has no known data requirements, add them to ReleasePipelineRequirements", pathInRepo)
+	}
+	merged := map[string]interface{}{}
+	for _, d := range []*ReleaseData{releasePlanData, releasePlanAdmissionData} {
+		if d == nil {
+			continue
+		}
+		data, err := d.ToMap()
+		if err != nil {
+			return err
+		}
+		merged = mergeData(merged, data)
+	}
+	if missing := missingDataKeys(merged, required); len(missing) > 0 {
+		return fmt.Errorf("release pipeline %s requires data %v", pathInRepo, missing)
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
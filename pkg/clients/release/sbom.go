@@ -12,10 +12,15 @@ package release
 
 import (
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"regexp"
+	"strings"
+
+	"github.com/konflux-ci/e2e-tests/pkg/utils/sbom"
 )
 
 // Defines a struct Links with fields for various types of links including artifacts, requests, RPM manifests,
@@ -94,37 +99,81 @@ type Image struct {
 	ParsedData        ParsedData       `json:"parsed_data"`
 }
 
-// GetPyxisImageByImageID makes a GET request to stage Pyxis to get an image
-// and returns it.
-func (r *ReleaseController) GetPyxisImageByImageID(pyxisStageImagesApiEndpoint, imageID string,
-	pyxisCertDecoded, pyxisKeyDecoded []byte) ([]byte, error) {
+// ContentManifestComponent is a component of the image SBOM stored in Pyxis
+type ContentManifestComponent struct {
+	BomRef  string `json:"bom_ref"`
+	Name    string `json:"name"`
+	Purl    string `json:"purl"`
+	Type    string `json:"type"`
+	Version string `json:"version"`
+}
 
-	url := fmt.Sprintf("%s%s", pyxisStageImagesApiEndpoint, imageID)
+// ContentManifestDetails is the content manifest (SBOM) of an image uploaded to Pyxis
+type ContentManifestDetails struct {
+	ID         string                     `json:"_id"`
+	ImageID    string                     `json:"image_id"`
+	Components []ContentManifestComponent `json:"components"`
+}
+
+// SBOM returns the components of the content manifest, so it can be compared with the build-time SBOM of the image
+func (c *ContentManifestDetails) SBOM() *sbom.SBOM {
+	s := &sbom.SBOM{}
+	for _, component := range c.Components {
+		s.Components = append(s.Components, sbom.Component{
+			Name:    component.Name,
+			Version: component.Version,
+			Purl:    component.Purl,
+			Type:    component.Type,
+		})
+	}
+	return s
+}
 
+// PyxisEndpoint is the Pyxis API together with the client certificate and key used to authenticate to it
+type PyxisEndpoint struct {
+	// URL of the Pyxis API, e.g. https://pyxis.preprod.api.redhat.com/v1
+	URL  string
+	Cert []byte
+	Key  []byte
+	// RootCAs verify the Pyxis server certificate, the system CAs are used when nil
+	RootCAs *x509.CertPool
+}
+
+// httpClient returns a client authenticating with the certificate and key of the endpoint
+func (p PyxisEndpoint) httpClient() (*http.Client, error) {
 	// Create a TLS configuration with the key and certificate
-	cert, err := tls.X509KeyPair(pyxisCertDecoded, pyxisKeyDecoded)
+	cert, err := tls.X509KeyPair(p.Cert, p.Key)
 	if err != nil {
 		return nil, fmt.Errorf("error creating TLS certificate and key: %s", err)
 	}
 
 	// Create a client with the custom TLS configuration
-	client := &http.Client{
+	return &http.Client{
 		Transport: &http.Transport{
 			TLSClientConfig: &tls.Config{
 				Certificates: []tls.Certificate{cert},
+				RootCAs:      p.RootCAs,
 			},
 		},
+	}, nil
+}
+
+// get sends GET request to the URL and returns the response status code and body
+func (p PyxisEndpoint) get(url string) (int, []byte, error) {
+	client, err := p.httpClient()
+	if err != nil {
+		return 0, nil, err
 	}
 
 	// Send GET request
 	request, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error creating GET request: %s", err)
+		return 0, nil, fmt.Errorf("error creating GET request: %s", err)
 	}
 
 	response, err := client.Do(request)
 	if err != nil {
-		return nil, fmt.Errorf("error sending GET request: %s", err)
+		return 0, nil, fmt.Errorf("error sending GET request: %s", err)
 	}
 
 	defer response.Body.Close()
@@ -132,9 +181,53 @@ func (r *ReleaseController) GetPyxisImageByImageID(pyxisStageImagesApiEndpoint,
 	// Read the response body
 	body, err := io.ReadAll(response.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %s", err)
+		return 0, nil, fmt.Errorf("error reading response body: %s", err)
+	}
+	return response.StatusCode, body, nil
+}
+
+// getJSON sends GET request to the path of the Pyxis API and unmarshals the response into the object
+func (p PyxisEndpoint) getJSON(path string, object interface{}) error {
+	url := strings.TrimSuffix(p.URL, "/") + path
+	statusCode, body, err := p.get(url)
+	if err != nil {
+		return err
+	}
+	if statusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned status code %d: %s", url, statusCode, string(body))
+	}
+	if err := json.Unmarshal(body, object); err != nil {
+		return fmt.Errorf("failed to unmarshal response of GET %s: %s", url, err)
+	}
+	return nil
+}
+
+// GetPyxisImageByImageID makes a GET request to stage Pyxis to get an image
+// and returns it.
+func (r *ReleaseController) GetPyxisImageByImageID(pyxisStageImagesApiEndpoint, imageID string,
+	pyxisCertDecoded, pyxisKeyDecoded []byte) ([]byte, error) {
+
+	url := fmt.Sprintf("%s%s", pyxisStageImagesApiEndpoint, imageID)
+	_, body, err := PyxisEndpoint{Cert: pyxisCertDecoded, Key: pyxisKeyDecoded}.get(url)
+	return body, err
+}
+
+// GetPyxisImage gets the image with the ID from the Pyxis endpoint
+func (r *ReleaseController) GetPyxisImage(pyxis PyxisEndpoint, imageID string) (*Image, error) {
+	image := &Image{}
+	if err := pyxis.getJSON("/images/id/"+imageID, image); err != nil {
+		return nil, fmt.Errorf("failed to get image %s from Pyxis: %s", imageID, err)
+	}
+	return image, nil
+}
+
+// GetPyxisContentManifest gets the content manifest with the ID from the Pyxis endpoint
+func (r *ReleaseController) GetPyxisContentManifest(pyxis PyxisEndpoint, contentManifestID string) (*ContentManifestDetails, error) {
+	contentManifest := &ContentManifestDetails{}
+	if err := pyxis.getJSON("/content-manifests/id/"+contentManifestID, contentManifest); err != nil {
+		return nil, fmt.Errorf("failed to get content manifest %s from Pyxis: %s", contentManifestID, err)
 	}
-	return body, nil
+	return contentManifest, nil
 }
 
 // GetPyxisImageIDsFromCreatePyxisImageTaskLogs takes a slice of task logs (as this is what
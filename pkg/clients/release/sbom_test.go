@@ -0,0 +1,86 @@
+package release
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/konflux-ci/e2e-tests/pkg/utils/sbom"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	fixtureImageID           = "6537ad8b9a2e6a3bd5e6b4c1"
+	fixtureContentManifestID = "6537ad8c8dc2a4e9a8f7f1e2"
+)
+
+func TestGetPyxisImage(t *testing.T) {
+	pyxis, err := NewPyxisStandIn("testdata/pyxis")
+	assert.NoError(t, err)
+	defer pyxis.Close()
+	r := &ReleaseController{}
+
+	image, err := r.GetPyxisImage(pyxis.Endpoint(), fixtureImageID)
+	assert.NoError(t, err)
+	assert.Equal(t, fixtureImageID, image.ID)
+	assert.Equal(t, "amd64", image.Architecture)
+	assert.Equal(t, fixtureContentManifestID, image.ContentManifest.ID)
+	assert.Equal(t, Links{
+		Artifacts:       ArtifactLinks{Href: "/v1/images/id/" + fixtureImageID + "/artifacts"},
+		Requests:        RequestLinks{Href: "/v1/images/id/" + fixtureImageID + "/requests"},
+		RpmManifest:     RpmManifestLinks{Href: "/v1/images/id/" + fixtureImageID + "/rpm-manifest"},
+		TestResults:     TestResultsLinks{Href: "/v1/images/id/" + fixtureImageID + "/test-results"},
+		Vulnerabilities: VulnerabilitiesLinks{Href: "/v1/images/id/" + fixtureImageID + "/vulnerabilities"},
+	}, image.Links)
+	assert.Equal(t, ParsedData{
+		Architecture: "amd64",
+		EnvVariables: []string{"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin", "container=oci"},
+	}, image.ParsedData)
+	assert.Equal(t, []FreshnessGrade{{CreationDate: "2024-06-20T11:35:08.141000+00:00", Grade: "A", StartDate: "2024-06-20T11:35:08.141000+00:00"}}, image.FreshnessGrades)
+	assert.Nil(t, image.CreatedOnBehalfOf)
+
+	_, err = r.GetPyxisImage(pyxis.Endpoint(), "unknown")
+	assert.ErrorContains(t, err, "status code 404")
+}
+
+func TestGetPyxisContentManifest(t *testing.T) {
+	pyxis, err := NewPyxisStandIn("testdata/pyxis")
+	assert.NoError(t, err)
+	defer pyxis.Close()
+
+	contentManifest, err := (&ReleaseController{}).GetPyxisContentManifest(pyxis.Endpoint(), fixtureContentManifestID)
+	assert.NoError(t, err)
+	assert.Equal(t, fixtureImageID, contentManifest.ImageID)
+	assert.Len(t, contentManifest.Components, 2)
+	assert.Equal(t, ContentManifestComponent{
+		BomRef:  "pkg:golang/github.com/sirupsen/logrus@v1.9.3",
+		Name:    "github.com/sirupsen/logrus",
+		Purl:    "pkg:golang/github.com/sirupsen/logrus@v1.9.3",
+		Type:    "library",
+		Version: "v1.9.3",
+	}, contentManifest.Components[1])
+
+	built := &sbom.SBOM{Components: []sbom.Component{
+		{Name: "bash", Purl: "pkg:rpm/rhel/bash@5.1.8-6.el9?arch=x86_64&distro=rhel-9.2"},
+		{Name: "github.com/sirupsen/logrus", Purl: "pkg:golang/github.com/sirupsen/logrus@v1.9.3"},
+		{Name: "/usr/bin/bash"},
+	}}
+	assert.Equal(t, "- /usr/bin/bash", sbom.DiffSBOMs(built, contentManifest.SBOM()).String())
+}
+
+func TestPyxisStandInRequiresClientCertificate(t *testing.T) {
+	pyxis, err := NewPyxisStandIn("testdata/pyxis")
+	assert.NoError(t, err)
+	defer pyxis.Close()
+
+	endpoint := pyxis.Endpoint()
+	client := pyxis.server.Client()
+	_, err = client.Get(endpoint.URL + "/images/id/" + fixtureImageID)
+	assert.Error(t, err, "request without the client certificate has to be rejected")
+
+	otherPyxis, err := NewPyxisStandIn("testdata/pyxis")
+	assert.NoError(t, err)
+	defer otherPyxis.Close()
+	statusCode, _, err := PyxisEndpoint{Cert: otherPyxis.clientCert, Key: otherPyxis.clientKey, RootCAs: endpoint.RootCAs}.get(endpoint.URL + "/images/id/" + fixtureImageID)
+	assert.Error(t, err, "certificate not issued by the stand-in CA has to be rejected")
+	assert.NotEqual(t, http.StatusOK, statusCode)
+}
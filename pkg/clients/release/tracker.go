@@ -0,0 +1,283 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/utils/tekton"
+	releaseApi "github.com/konflux-ci/release-service/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/yaml"
+)
+
+// Release conditions in the order release-service progresses through them.
+// Not every version of release-service reports all of them.
+const (
+	ValidatedCondition                = "Validated"
+	ManagedPipelineProcessedCondition = "ManagedPipelineProcessed"
+	TenantPipelineProcessedCondition  = "TenantPipelineProcessed"
+	ProcessedCondition                = "Processed"
+	PostActionsExecutedCondition      = "PostActionsExecuted"
+	ReleasedCondition                 = "Released"
+)
+
+// ReleasePhases lists the conditions followed by ReleaseTracker
+var ReleasePhases = []string{
+	ValidatedCondition,
+	ManagedPipelineProcessedCondition,
+	TenantPipelineProcessedCondition,
+	ProcessedCondition,
+	PostActionsExecutedCondition,
+	ReleasedCondition,
+}
+
+// PhaseTransition is a change of a Release condition observed by ReleaseTracker
+type PhaseTransition struct {
+	Condition string
+	Status    metav1.ConditionStatus
+	Reason    string
+	Message   string
+	// Time is the last transition time reported in the condition
+	Time time.Time
+}
+
+// PhaseTiming is the time a Release spent in a phase, Finished is zero while the phase is in progress
+type PhaseTiming struct {
+	Condition string
+	Started   time.Time
+	Finished  time.Time
+	Succeeded bool
+}
+
+// Duration returns how long the phase took, zero if it hasn't finished
+func (p PhaseTiming) Duration() time.Duration {
+	if p.Finished.IsZero() {
+		return 0
+	}
+	return p.Finished.Sub(p.Started)
+}
+
+// ReleaseTracker follows a Release through its conditions, records the transitions
+// and correlates the Release with its PipelineRun in the managed namespace
+type ReleaseTracker struct {
+	controller       *ReleaseController
+	managedNamespace string
+
+	Release     *releaseApi.Release
+	PipelineRun *pipeline.PipelineRun
+	Transitions []PhaseTransition
+	Interval    time.Duration
+}
+
+// NewReleaseTracker returns a tracker of the Release processed in the managed namespace
+func (r *ReleaseController) NewReleaseTracker(release *releaseApi.Release, managedNamespace string) *ReleaseTracker {
+	t := &ReleaseTracker{
+		controller:       r,
+		managedNamespace: managedNamespace,
+		Interval:         constants.PipelineRunPollingInterval,
+	}
+	t.Observe(release)
+	return t
+}
+
+// Observe records conditions of the Release which changed since the last observation
+func (t *ReleaseTracker) Observe(release *releaseApi.Release) {
+	t.Release = release
+	for _, phase := range ReleasePhases {
+		condition := meta.FindStatusCondition(release.Status.Conditions, phase)
+		if condition == nil {
+			continue
+		}
+		if last := t.lastTransition(phase); last != nil && last.Status == condition.Status && last.Reason == condition.Reason {
+			continue
+		}
+		t.Transitions = append(t.Transitions, PhaseTransition{
+			Condition: phase,
+			Status:    condition.Status,
+			Reason:    condition.Reason,
+			Message:   condition.Message,
+			Time:      condition.LastTransitionTime.Time,
+		})
+	}
+}
+
+func (t *ReleaseTracker) lastTransition(condition string) *PhaseTransition {
+	for i := len(t.Transitions) - 1; i >= 0; i-- {
+		if t.Transitions[i].Condition == condition {
+			return &t.Transitions[i]
+		}
+	}
+	return nil
+}
+
+func isPhaseFinished(transition PhaseTransition) bool {
+	return transition.Status == metav1.ConditionTrue ||
+		(transition.Status == metav1.ConditionFalse && transition.Reason == string(releaseApi.FailedReason))
+}
+
+// Phases returns timing of every observed phase. A phase starts when its condition is first reported
+// (or when the Release was created for phases reported only once they finish) and ends when it succeeds or fails.
+func (t *ReleaseTracker) Phases() []PhaseTiming {
+	var phases []PhaseTiming
+	for _, phase := range ReleasePhases {
+		var timing *PhaseTiming
+		for _, transition := range t.Transitions {
+			if transition.Condition != phase {
+				continue
+			}
+			if timing == nil {
+				timing = &PhaseTiming{Condition: phase, Started: transition.Time}
+				if isPhaseFinished(transition) && t.Release != nil {
+					timing.Started = t.Release.CreationTimestamp.Time
+				}
+			}
+			if isPhaseFinished(transition) {
+				timing.Finished = transition.Time
+				timing.Succeeded = transition.Status == metav1.ConditionTrue
+			}
+		}
+		if timing != nil {
+			phases = append(phases, *timing)
+		}
+	}
+	return phases
+}
+
+// FailedCondition returns the first phase condition of the Release which failed, nil if none failed
+func (t *ReleaseTracker) FailedCondition() *metav1.Condition {
+	if t.Release == nil {
+		return nil
+	}
+	for _, phase := range ReleasePhases {
+		condition := meta.FindStatusCondition(t.Release.Status.Conditions, phase)
+		if condition != nil && condition.Status == metav1.ConditionFalse && condition.Reason == string(releaseApi.FailedReason) {
+			return condition
+		}
+	}
+	return nil
+}
+
+// Timeline returns the observed transitions in a human-readable form, one per line
+func (t *ReleaseTracker) Timeline() string {
+	var lines []string
+	for _, transition := range t.Transitions {
+		line := fmt.Sprintf("%s %s=%s (%s)", transition.Time.UTC().Format(time.RFC3339), transition.Condition, transition.Status, transition.Reason)
+		if transition.Message != "" {
+			line += ": " + transition.Message
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Refresh gets the current state of the Release and of its managed PipelineRun and records the conditions of the Release
+func (t *ReleaseTracker) Refresh() error {
+	release := &releaseApi.Release{}
+	if err := t.controller.KubeRest().Get(context.Background(), types.NamespacedName{Name: t.Release.GetName(), Namespace: t.Release.GetNamespace()}, release); err != nil {
+		return fmt.Errorf("failed to get release %s/%s: %+v", t.Release.GetNamespace(), t.Release.GetName(), err)
+	}
+	t.Observe(release)
+
+	if pipelineRun, err := t.getPipelineRun(); err == nil {
+		t.PipelineRun = pipelineRun
+	}
+	return nil
+}
+
+// getPipelineRun returns the PipelineRun reported in the Release status, or found by its labels in the managed namespace
+func (t *ReleaseTracker) getPipelineRun() (*pipeline.PipelineRun, error) {
+	if namespace, name, ok := strings.Cut(t.Release.Status.Processing.PipelineRun, "/"); ok {
+		pipelineRun := &pipeline.PipelineRun{}
+		err := t.controller.KubeRest().Get(context.Background(), types.NamespacedName{Name: name, Namespace: namespace}, pipelineRun)
+		return pipelineRun, err
+	}
+	return t.controller.GetPipelineRunInNamespace(t.managedNamespace, t.Release.GetName(), t.Release.GetNamespace())
+}
+
+// WaitForCompletion follows the Release until it is released or fails.
+// When it fails (or the timeout expires) the returned error contains the diagnostic of the Release.
+func (t *ReleaseTracker) WaitForCompletion(timeout time.Duration) error {
+	err := wait.PollUntilContextTimeout(context.Background(), t.Interval, timeout, true, func(ctx context.Context) (done bool, err error) {
+		if err := t.Refresh(); err != nil {
+			GinkgoWriter.Println(err.Error())
+			return false, nil
+		}
+		return t.Release.HasReleaseFinished() || t.FailedCondition() != nil, nil
+	})
+	GinkgoWriter.Printf("release %s/%s timeline:\n%s\n", t.Release.GetNamespace(), t.Release.GetName(), t.Timeline())
+	if err != nil {
+		return fmt.Errorf("release %s/%s didn't finish in %s\n%s", t.Release.GetNamespace(), t.Release.GetName(), timeout, t.Diagnose())
+	}
+	if t.FailedCondition() != nil || !t.Release.IsReleased() {
+		return fmt.Errorf("release %s/%s failed\n%s", t.Release.GetNamespace(), t.Release.GetName(), t.Diagnose())
+	}
+	return nil
+}
+
+// Diagnose returns a single report about the Release containing the failing condition,
+// logs of the failed TaskRun of the managed PipelineRun and the ReleasePlanAdmission used
+func (t *ReleaseTracker) Diagnose() string {
+	var pipelineRunLogs string
+	if t.PipelineRun != nil && tekton.HasPipelineRunFailed(t.PipelineRun) {
+		logs, err := tekton.GetFailedPipelineRunLogs(t.controller.KubeRest(), t.controller.KubeInterface(), t.PipelineRun)
+		if err != nil {
+			logs = fmt.Sprintf("failed to get logs of PipelineRun %s/%s: %+v", t.PipelineRun.GetNamespace(), t.PipelineRun.GetName(), err)
+		}
+		pipelineRunLogs = logs
+	}
+
+	var rpa *releaseApi.ReleasePlanAdmission
+	releasePlan, err := t.controller.GetReleasePlan(t.Release.Spec.ReleasePlan, t.Release.GetNamespace())
+	if err == nil {
+		if namespace, name, ok := strings.Cut(releasePlan.Status.ReleasePlanAdmission.Name, "/"); ok {
+			if rpa, err = t.controller.GetReleasePlanAdmission(name, namespace); err != nil {
+				rpa = nil
+			}
+		}
+	}
+	return t.formatDiagnostic(pipelineRunLogs, rpa)
+}
+
+func (t *ReleaseTracker) formatDiagnostic(pipelineRunLogs string, rpa *releaseApi.ReleasePlanAdmission) string {
+	var sb strings.Builder
+	if failed := t.FailedCondition(); failed != nil {
+		fmt.Fprintf(&sb, "failed condition: %s (%s): %s\n", failed.Type, failed.Reason, failed.Message)
+	} else {
+		sb.WriteString("no condition failed\n")
+	}
+	if timeline := t.Timeline(); timeline != "" {
+		fmt.Fprintf(&sb, "timeline:\n%s\n", timeline)
+	}
+
+	switch {
+	case t.PipelineRun == nil:
+		sb.WriteString("managed PipelineRun: not found\n")
+	default:
+		fmt.Fprintf(&sb, "managed PipelineRun: %s/%s\n", t.PipelineRun.GetNamespace(), t.PipelineRun.GetName())
+	}
+	if pipelineRunLogs != "" {
+		sb.WriteString(pipelineRunLogs)
+		if !strings.HasSuffix(pipelineRunLogs, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+
+	if rpa == nil {
+		sb.WriteString("ReleasePlanAdmission: not found")
+		return sb.String()
+	}
+	spec, err := yaml.Marshal(rpa.Spec)
+	if err != nil {
+		spec = []byte(err.Error())
+	}
+	fmt.Fprintf(&sb, "ReleasePlanAdmission %s/%s:\n%s", rpa.GetNamespace(), rpa.GetName(), spec)
+	return sb.String()
+}
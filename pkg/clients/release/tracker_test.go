@@ -0,0 +1,67 @@
+package release
+
+import (
+	"testing"
+	"time"
+
+	releaseApi "github.com/konflux-ci/release-service/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var trackerStart = time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+
+func releaseWithConditions(conditions ...metav1.Condition) *releaseApi.Release {
+	r := &releaseApi.Release{ObjectMeta: metav1.ObjectMeta{Name: "release", Namespace: "tenant", CreationTimestamp: metav1.NewTime(trackerStart)}}
+	r.Status.Conditions = conditions
+	return r
+}
+
+func condition(conditionType string, status metav1.ConditionStatus, reason string, after time.Duration) metav1.Condition {
+	return metav1.Condition{Type: conditionType, Status: status, Reason: reason, LastTransitionTime: metav1.NewTime(trackerStart.Add(after))}
+}
+
+func TestReleaseTrackerPhases(t *testing.T) {
+	tracker := &ReleaseTracker{}
+	validated := condition(ValidatedCondition, metav1.ConditionTrue, "Succeeded", time.Second)
+	tracker.Observe(releaseWithConditions(validated,
+		condition(ProcessedCondition, metav1.ConditionFalse, "Progressing", 2*time.Second)))
+	// observing the same state again doesn't record any transition
+	tracker.Observe(releaseWithConditions(validated,
+		condition(ProcessedCondition, metav1.ConditionFalse, "Progressing", 2*time.Second)))
+	tracker.Observe(releaseWithConditions(validated,
+		condition(ProcessedCondition, metav1.ConditionTrue, "Succeeded", time.Minute),
+		condition(ReleasedCondition, metav1.ConditionTrue, "Succeeded", 2*time.Minute)))
+
+	assert.Len(t, tracker.Transitions, 4)
+	assert.Nil(t, tracker.FailedCondition())
+	assert.Equal(t, []PhaseTiming{
+		{Condition: ValidatedCondition, Started: trackerStart, Finished: trackerStart.Add(time.Second), Succeeded: true},
+		{Condition: ProcessedCondition, Started: trackerStart.Add(2 * time.Second), Finished: trackerStart.Add(time.Minute), Succeeded: true},
+		{Condition: ReleasedCondition, Started: trackerStart, Finished: trackerStart.Add(2 * time.Minute), Succeeded: true},
+	}, tracker.Phases())
+	assert.Equal(t, 58*time.Second, tracker.Phases()[1].Duration())
+}
+
+func TestReleaseTrackerDiagnostic(t *testing.T) {
+	failed := condition(ManagedPipelineProcessedCondition, metav1.ConditionFalse, "Failed", time.Minute)
+	failed.Message = "Release processing failed on managed pipelineRun"
+	tracker := &ReleaseTracker{}
+	tracker.Observe(releaseWithConditions(condition(ValidatedCondition, metav1.ConditionTrue, "Succeeded", time.Second), failed))
+
+	assert.Equal(t, ManagedPipelineProcessedCondition, tracker.FailedCondition().Type)
+
+	diagnostic := tracker.formatDiagnostic("", nil)
+	assert.Contains(t, diagnostic, "failed condition: ManagedPipelineProcessed (Failed): Release processing failed on managed pipelineRun")
+	assert.Contains(t, diagnostic, "managed PipelineRun: not found")
+	assert.Contains(t, diagnostic, "ReleasePlanAdmission: not found")
+
+	tracker.PipelineRun = &pipeline.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: "managed-abc", Namespace: "managed"}}
+	rpa := &releaseApi.ReleasePlanAdmission{ObjectMeta: metav1.ObjectMeta{Name: "rpa", Namespace: "managed"}}
+	rpa.Spec.Policy = "standard"
+	diagnostic = tracker.formatDiagnostic("task push-snapshot failed: exit code 1", rpa)
+	assert.Contains(t, diagnostic, "managed PipelineRun: managed/managed-abc\ntask push-snapshot failed: exit code 1\n")
+	assert.Contains(t, diagnostic, "ReleasePlanAdmission managed/rpa:\n")
+	assert.Contains(t, diagnostic, "policy: standard")
+}
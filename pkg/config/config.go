@@ -0,0 +1,428 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// FileEnv is the name of the env var pointing to an optional config file (in default.env or flat YAML format)
+	FileEnv = "E2E_CONFIG_FILE"
+
+	// CommonRequirement is the value of the "required" tag for fields required by all suites
+	CommonRequirement = "*"
+
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+
+	maskedValue = "********"
+)
+
+// Config is a typed view of the env vars used by the e2e framework.
+//
+// Supported field tags:
+//   - env: name of the env var (or the key in the config file)
+//   - default: value used when the env var is not set
+//   - secret: "true" if the value must not be printed
+//   - required: comma separated list of suite labels requiring the value, "*" for all suites
+//   - validate: name of the validator applied to non-empty values (see validators)
+//
+// Existing utils.GetEnv/os.Getenv call sites keep working, since values from the config file are exported
+// to the environment by Init. They can be migrated to the typed fields one by one.
+type Config struct {
+	GithubToken         string `env:"GITHUB_TOKEN" secret:"true" required:"*"`
+	QuayToken           string `env:"QUAY_TOKEN" secret:"true" required:"*" validate:"base64"`
+	DefaultQuayOrg      string `env:"DEFAULT_QUAY_ORG" required:"*"`
+	DefaultQuayOrgToken string `env:"DEFAULT_QUAY_ORG_TOKEN" secret:"true" required:"*"`
+	GithubOrganization  string `env:"MY_GITHUB_ORG" default:"redhat-appstudio-qe"`
+	QuayE2EOrganization string `env:"QUAY_E2E_ORGANIZATION" default:"redhat-appstudio-qe"`
+
+	ApplicationsNamespace string `env:"E2E_APPLICATIONS_NAMESPACE"`
+	PrivateDevfileSample  string `env:"PRIVATE_DEVFILE_SAMPLE" validate:"url"`
+	QuayOAuthUser         string `env:"QUAY_OAUTH_USER" required:"spi-suite"`
+	QuayOAuthToken        string `env:"QUAY_OAUTH_TOKEN" secret:"true" required:"spi-suite"`
+	DockerIOAuth          string `env:"DOCKER_IO_AUTH" secret:"true" validate:"userpass"`
+	ImageTagExpiration    string `env:"IMAGE_TAG_EXPIRATION" default:"6h" validate:"tagexpiration"`
+
+	InfraDeploymentsOrg           string `env:"INFRA_DEPLOYMENTS_ORG" default:"redhat-appstudio"`
+	InfraDeploymentsBranch        string `env:"INFRA_DEPLOYMENTS_BRANCH" default:"main"`
+	InfraDeploymentsLocalDir      string `env:"INFRA_DEPLOYMENTS_LOCAL_DIR"`
+	InfraDeploymentsOverlaysFile  string `env:"INFRA_DEPLOYMENTS_OVERLAYS_FILE"`
+	EnableSchedulingOnMasterNodes bool   `env:"ENABLE_SCHEDULING_ON_MASTER_NODES" default:"true"`
+	ArgoCDReadinessTimeout        string `env:"ARGOCD_READINESS_TIMEOUT" validate:"duration"`
+
+	PacGithubAppID         string `env:"E2E_PAC_GITHUB_APP_ID" validate:"int"`
+	PacGithubAppPrivateKey string `env:"E2E_PAC_GITHUB_APP_PRIVATE_KEY" secret:"true" validate:"base64"`
+	SkipPacTests           bool   `env:"SKIP_PAC_TESTS"`
+	GitlabToken            string `env:"PAC_GITLAB_TOKEN" secret:"true" required:"gitlab-status-reporting"`
+	GitlabURL              string `env:"PAC_GITLAB_URL" default:"https://gitlab.com/api/v4" validate:"url"`
+	GitlabProjectID        string `env:"PAC_PROJECT_ID" required:"gitlab-status-reporting"`
+
+	MultiPlatformAWSAccessKey       string `env:"MULTI_PLATFORM_AWS_ACCESS_KEY" secret:"true" required:"multi-platform"`
+	MultiPlatformAWSSecretAccessKey string `env:"MULTI_PLATFORM_AWS_SECRET_ACCESS_KEY" secret:"true" required:"multi-platform"`
+	MultiPlatformAWSSSHKey          string `env:"MULTI_PLATFORM_AWS_SSH_KEY" secret:"true" required:"multi-platform"`
+	MultiPlatformIBMAPIKey          string `env:"MULTI_PLATFORM_IBM_API_KEY" secret:"true" required:"multi-platform"`
+
+	PyxisStageKey  string `env:"PYXIS_STAGE_KEY" secret:"true" required:"pushPyxis"`
+	PyxisStageCert string `env:"PYXIS_STAGE_CERT" secret:"true" required:"pushPyxis"`
+
+	UpgradeBranch           string `env:"UPGRADE_BRANCH"`
+	UpgradeForkOrganization string `env:"UPGRADE_FORK_ORGANIZATION" default:"redhat-appstudio"`
+	UpgradeMatrixFile       string `env:"UPGRADE_MATRIX_FILE"`
+	UpgradeMatrixEntries    string `env:"UPGRADE_MATRIX_ENTRIES"`
+
+	UpgradeOperatorStallBudget  string `env:"UPGRADE_OPERATOR_STALL_BUDGET" default:"30m" validate:"duration"`
+	UpgradeOperatorStallBudgets string `env:"UPGRADE_OPERATOR_STALL_BUDGETS"`
+
+	SuiteLabel      string `env:"E2E_TEST_SUITE_LABEL"`
+	ShardCount      int    `env:"SHARD_COUNT" default:"1"`
+	ShardIndex      int    `env:"SHARD_INDEX" default:"0"`
+	ShardTimingsDir string `env:"SHARD_TIMINGS_DIR"`
+	SkipCleanup     bool   `env:"E2E_SKIP_CLEANUP"`
+	KlogVerbosity   int    `env:"KLOG_VERBOSITY" default:"1"`
+	ArtifactDir     string `env:"ARTIFACT_DIR" default:"."`
+
+	SlackBotToken string `env:"SLACK_BOT_TOKEN" secret:"true"`
+
+	// sources maps env var names to the source of their value (env, file or default)
+	sources map[string]string
+}
+
+// Field describes a single configuration value
+type Field struct {
+	Env      string
+	Default  string
+	Secret   bool
+	Required []string
+	Validate string
+	index    int
+}
+
+var validators = map[string]func(string) error{
+	"url": func(v string) error {
+		u, err := url.Parse(v)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("not a valid URL")
+		}
+		return nil
+	},
+	"base64": func(v string) error {
+		if _, err := base64.StdEncoding.DecodeString(v); err != nil {
+			return fmt.Errorf("not a valid base64 encoded value")
+		}
+		return nil
+	},
+	"int": func(v string) error {
+		if _, err := strconv.Atoi(v); err != nil {
+			return fmt.Errorf("not a valid number")
+		}
+		return nil
+	},
+	"userpass": func(v string) error {
+		if user, pass, found := strings.Cut(v, ":"); !found || user == "" || pass == "" {
+			return fmt.Errorf("expected format is username:token")
+		}
+		return nil
+	},
+	"duration": func(v string) error {
+		if _, err := time.ParseDuration(v); err != nil {
+			return fmt.Errorf("not a valid duration, e.g. 30m")
+		}
+		return nil
+	},
+	"tagexpiration": func(v string) error {
+		if !regexp.MustCompile(`^[0-9]+[hdw]$`).MatchString(v) {
+			return fmt.Errorf("expected format is digits followed by h (hours), d (days) or w (weeks)")
+		}
+		return nil
+	},
+}
+
+// Fields returns descriptions of all configuration values in the order of the Config struct
+func Fields() []Field {
+	var fields []Field
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		env, ok := f.Tag.Lookup("env")
+		if !ok {
+			continue
+		}
+		field := Field{Env: env, Default: f.Tag.Get("default"), Secret: f.Tag.Get("secret") == "true", Validate: f.Tag.Get("validate"), index: i}
+		if required := f.Tag.Get("required"); required != "" {
+			field.Required = strings.Split(required, ",")
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// RequiredFor returns names of env vars required by the suite with the given label.
+// Use CommonRequirement to get the values required by all suites.
+func RequiredFor(suite string) []string {
+	var names []string
+	for _, f := range Fields() {
+		for _, r := range f.Required {
+			if r == suite {
+				names = append(names, f.Env)
+			}
+		}
+	}
+	return names
+}
+
+// Init exports values from the config file referenced by E2E_CONFIG_FILE (if any) to the environment
+// of the current process (and processes started by it), without overriding already defined env vars
+func Init() error {
+	path := os.Getenv(FileEnv)
+	if path == "" {
+		return nil
+	}
+	values, err := LoadFile(path)
+	if err != nil {
+		return err
+	}
+	for k, v := range values {
+		if os.Getenv(k) == "" {
+			if err := os.Setenv(k, v); err != nil {
+				return fmt.Errorf("failed to set env var %s: %+v", k, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Load returns the configuration loaded from env vars, the optional config file (path can be empty) and the defaults,
+// in this order of precedence. Values which cannot be converted to the type of the field cause an error.
+func Load(path string) (*Config, error) {
+	fileValues := map[string]string{}
+	if path != "" {
+		var err error
+		if fileValues, err = LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	c := &Config{sources: map[string]string{}}
+	v := reflect.ValueOf(c).Elem()
+	var errs []error
+	for _, f := range Fields() {
+		value, source := f.Default, SourceDefault
+		if fv, ok := fileValues[f.Env]; ok && fv != "" {
+			value, source = fv, SourceFile
+		}
+		if ev := os.Getenv(f.Env); ev != "" {
+			value, source = ev, SourceEnv
+		}
+		if value == "" {
+			continue
+		}
+		if err := setField(v.Field(f.index), value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %+v", f.Env, err))
+			continue
+		}
+		c.sources[f.Env] = source
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return c, nil
+}
+
+// LoadFile parses the config file. Files with .yaml/.yml extension are expected to contain a flat map
+// of env var names to values, other files are parsed as shell env files (like default.env).
+func LoadFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %+v", path, err)
+	}
+	var values map[string]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		values, err = parseYAML(data)
+	default:
+		values, err = parseEnvFile(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %+v", path, err)
+	}
+	return values, nil
+}
+
+func parseYAML(data []byte) (map[string]string, error) {
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	for k, v := range raw {
+		switch v := v.(type) {
+		case nil:
+			values[k] = ""
+		case map[string]interface{}, []interface{}:
+			return nil, fmt.Errorf("value of %s has to be a scalar", k)
+		case float64:
+			// numbers are decoded as float64 by sigs.k8s.io/yaml
+			values[k] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			values[k] = fmt.Sprint(v)
+		}
+	}
+	return values, nil
+}
+
+func parseEnvFile(data []byte) (map[string]string, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		l := strings.TrimSpace(scanner.Text())
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		l = strings.TrimPrefix(l, "export ")
+		key, value, found := strings.Cut(l, "=")
+		if !found {
+			return nil, fmt.Errorf("line %d: expected format is KEY=VALUE", line)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	return values, scanner.Err()
+}
+
+func setField(v reflect.Value, value string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a valid boolean", value)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a valid number", value)
+		}
+		v.SetInt(int64(i))
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Kind())
+	}
+	return nil
+}
+
+// Value returns the string representation of the value of the env var, secrets are returned as well
+func (c *Config) Value(env string) string {
+	v := reflect.ValueOf(c).Elem()
+	for _, f := range Fields() {
+		if f.Env == env {
+			fv := v.Field(f.index)
+			if fv.IsZero() && c.sources[env] == "" {
+				return ""
+			}
+			return fmt.Sprint(fv.Interface())
+		}
+	}
+	return ""
+}
+
+// Source returns where the value of the env var comes from (env, file or default), empty string if it is not set
+func (c *Config) Source(env string) string {
+	return c.sources[env]
+}
+
+// Validate checks that the values required by all suites and by the given suites are set
+// and that all set values pass their validators
+func (c *Config) Validate(suites ...string) error {
+	var errs []error
+	required := map[string][]string{}
+	for _, f := range Fields() {
+		for _, r := range f.Required {
+			if r == CommonRequirement || contains(suites, r) {
+				required[f.Env] = append(required[f.Env], r)
+			}
+		}
+	}
+	for _, f := range Fields() {
+		value := c.Value(f.Env)
+		if value == "" {
+			if by, ok := required[f.Env]; ok {
+				errs = append(errs, fmt.Errorf("%s is required (by %s) but not set", f.Env, suitesDescription(by)))
+			}
+			continue
+		}
+		if f.Validate == "" {
+			continue
+		}
+		validator, ok := validators[f.Validate]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown validator %q", f.Env, f.Validate))
+			continue
+		}
+		if err := validator(value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %+v", f.Env, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Print writes the effective configuration to w, values of secrets are masked
+func (c *Config) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tVALUE\tSOURCE\tREQUIRED BY")
+	for _, f := range Fields() {
+		value := c.Value(f.Env)
+		if f.Secret && value != "" {
+			value = maskedValue
+		}
+		source := c.Source(f.Env)
+		if source == "" {
+			source = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.Env, value, source, suitesDescription(f.Required))
+	}
+	tw.Flush()
+}
+
+func suitesDescription(suites []string) string {
+	if len(suites) == 0 {
+		return "-"
+	}
+	if contains(suites, CommonRequirement) {
+		return "all suites"
+	}
+	sorted := append([]string{}, suites...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
+
+func contains(s []string, v string) bool {
+	for _, item := range s {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
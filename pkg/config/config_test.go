@@ -0,0 +1,102 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, "user.env", `# comment
+export GITHUB_TOKEN='file-token'
+export MY_GITHUB_ORG="file-org"
+export SHARD_COUNT=3
+export QUAY_TOKEN=''
+`)
+	t.Setenv("GITHUB_TOKEN", "env-token")
+	t.Setenv("QUAY_TOKEN", "")
+
+	c, err := Load(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "env-token", c.GithubToken)
+	assert.Equal(t, SourceEnv, c.Source("GITHUB_TOKEN"))
+	assert.Equal(t, "file-org", c.GithubOrganization)
+	assert.Equal(t, SourceFile, c.Source("MY_GITHUB_ORG"))
+	assert.Equal(t, 3, c.ShardCount)
+	assert.Equal(t, "redhat-appstudio", c.InfraDeploymentsOrg)
+	assert.Equal(t, SourceDefault, c.Source("INFRA_DEPLOYMENTS_ORG"))
+	assert.True(t, c.EnableSchedulingOnMasterNodes)
+	assert.Equal(t, "", c.Source("QUAY_TOKEN"))
+}
+
+func TestLoadYAMLFile(t *testing.T) {
+	path := writeFile(t, "config.yaml", "SHARD_INDEX: 2\nE2E_SKIP_CLEANUP: true\nDEFAULT_QUAY_ORG: my-org\n")
+
+	c, err := Load(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, c.ShardIndex)
+	assert.True(t, c.SkipCleanup)
+	assert.Equal(t, "my-org", c.DefaultQuayOrg)
+}
+
+func TestLoadInvalidType(t *testing.T) {
+	t.Setenv("SHARD_COUNT", "three")
+
+	_, err := Load("")
+
+	assert.ErrorContains(t, err, "SHARD_COUNT")
+}
+
+func TestValidate(t *testing.T) {
+	for _, env := range RequiredFor(CommonRequirement) {
+		t.Setenv(env, "dmFsdWU=")
+	}
+	t.Setenv("PAC_GITLAB_URL", "gitlab.com")
+	t.Setenv("IMAGE_TAG_EXPIRATION", "6 hours")
+
+	c, err := Load("")
+	assert.NoError(t, err)
+
+	err = c.Validate("gitlab-status-reporting")
+	assert.ErrorContains(t, err, "PAC_GITLAB_URL: not a valid URL")
+	assert.ErrorContains(t, err, "IMAGE_TAG_EXPIRATION: expected format")
+	assert.ErrorContains(t, err, "PAC_GITLAB_TOKEN is required (by gitlab-status-reporting) but not set")
+	assert.NotContains(t, c.Validate().Error(), "PAC_GITLAB_TOKEN")
+}
+
+func TestPrintMasksSecrets(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "ghp_secret")
+	t.Setenv("MY_GITHUB_ORG", "my-org")
+
+	c, err := Load("")
+	assert.NoError(t, err)
+	out := &bytes.Buffer{}
+	c.Print(out)
+
+	assert.NotContains(t, out.String(), "ghp_secret")
+	assert.Regexp(t, `GITHUB_TOKEN\s+\*+\s+env\s+all suites`, out.String())
+	assert.Regexp(t, `MY_GITHUB_ORG\s+my-org\s+env`, out.String())
+}
+
+func TestInitExportsFileValues(t *testing.T) {
+	path := writeFile(t, "user.env", "export UPGRADE_BRANCH=from-file\nexport DEFAULT_QUAY_ORG=from-file\n")
+	t.Setenv(FileEnv, path)
+	t.Setenv("UPGRADE_BRANCH", "")
+	t.Setenv("DEFAULT_QUAY_ORG", "from-env")
+
+	assert.NoError(t, Init())
+
+	assert.Equal(t, "from-file", os.Getenv("UPGRADE_BRANCH"))
+	assert.Equal(t, "from-env", os.Getenv("DEFAULT_QUAY_ORG"))
+}
@@ -85,6 +85,9 @@ const (
 	// Managed workspace for release pipelines tests
 	RELEASE_MANAGED_WORKSPACE_ENV = "RELEASE_MANAGED_WORKSPACE"
 
+	// Public key (PGP or PEM encoded) verifying the signatures of the assets of GitHub releases created by the release-to-github pipeline
+	RELEASE_GITHUB_SIGNING_PUBLIC_KEY_ENV = "RELEASE_GITHUB_SIGNING_PUBLIC_KEY"
+
 	// Bundle ref for overriding the default Java build bundle specified in BuildPipelineConfigConfigMapYamlURL
 	CUSTOM_JAVA_PIPELINE_BUILD_BUNDLE_ENV string = "CUSTOM_JAVA_PIPELINE_BUILD_BUNDLE"
 
@@ -4,55 +4,58 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 )
 
+// The describe wrappers use Offset(1) so the code location of the container points to the suite file
+// instead of this file (required e.g. for selecting the suites with Ginkgo --focus-file)
+
 // CommonSuiteDescribe annotates the common tests with the application label.
 func CommonSuiteDescribe(text string, args ...interface{}) bool {
-	return Describe("[common-suite "+text+"]", args, Ordered)
+	return Describe("[common-suite "+text+"]", Offset(1), args, Ordered)
 }
 
 func BuildSuiteDescribe(text string, args ...interface{}) bool {
-	return Describe("[build-service-suite "+text+"]", args)
+	return Describe("[build-service-suite "+text+"]", Offset(1), args)
 }
 
 func JVMBuildSuiteDescribe(text string, args ...interface{}) bool {
-	return Describe("[jvm-build-service-suite "+text+"]", args, Ordered)
+	return Describe("[jvm-build-service-suite "+text+"]", Offset(1), args, Ordered)
 }
 
 func MultiPlatformBuildSuiteDescribe(text string, args ...interface{}) bool {
-	return Describe("[multi-platform-build-service-suite "+text+"]", args, Ordered)
+	return Describe("[multi-platform-build-service-suite "+text+"]", Offset(1), args, Ordered)
 }
 
 func IntegrationServiceSuiteDescribe(text string, args ...interface{}) bool {
-	return Describe("[integration-service-suite "+text+"]", args, Ordered)
+	return Describe("[integration-service-suite "+text+"]", Offset(1), args, Ordered)
 }
 
 func RhtapDemoSuiteDescribe(args ...interface{}) bool {
-	return Describe("[rhtap-demo-suite]", args)
+	return Describe("[rhtap-demo-suite]", Offset(1), args)
 }
 
 func SPISuiteDescribe(args ...interface{}) bool {
-	return Describe("[spi-suite]", args, Ordered)
+	return Describe("[spi-suite]", Offset(1), args, Ordered)
 }
 
 func RemoteSecretSuiteDescribe(args ...interface{}) bool {
-	return Describe("[remotesecret-suite]", args, Ordered)
+	return Describe("[remotesecret-suite]", Offset(1), args, Ordered)
 }
 
 func EnterpriseContractSuiteDescribe(text string, args ...interface{}) bool {
-	return Describe("[enterprise-contract-suite "+text+"]", args, Ordered)
+	return Describe("[enterprise-contract-suite "+text+"]", Offset(1), args, Ordered)
 }
 
 func UpgradeSuiteDescribe(text string, args ...interface{}) bool {
-	return Describe("[upgrade-suite "+text+"]", args, Ordered)
+	return Describe("[upgrade-suite "+text+"]", Offset(1), args, Ordered)
 }
 
 func ReleasePipelinesSuiteDescribe(text string, args ...interface{}) bool {
-	return Describe("[release-pipelines-suite "+text+"]", args, Ordered)
+	return Describe("[release-pipelines-suite "+text+"]", Offset(1), args, Ordered)
 }
 
 func ReleaseServiceSuiteDescribe(text string, args ...interface{}) bool {
-	return Describe("[release-service-suite "+text+"]", args, Ordered)
+	return Describe("[release-service-suite "+text+"]", Offset(1), args, Ordered)
 }
 
 func TknBundleSuiteDescribe(text string, args ...interface{}) bool {
-	return Describe("[task-suite "+text+"]", args, Ordered)
+	return Describe("[task-suite "+text+"]", Offset(1), args, Ordered)
 }
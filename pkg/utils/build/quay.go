@@ -116,13 +116,19 @@ func IsImageRepoPublic(quayImageRepoName string) (bool, error) {
 }
 
 func DoesQuayOrgSupportPrivateRepo() (bool, error) {
+	return DoesQuayOrgSupportPrivateRepoWithClient(quayClient, quayOrg)
+}
+
+// DoesQuayOrgSupportPrivateRepoWithClient checks whether the plan of the quay organization allows private repositories
+// by creating (and deleting) a sample private repository
+func DoesQuayOrgSupportPrivateRepoWithClient(client quay.QuayService, org string) (bool, error) {
 	repositoryRequest := quay.RepositoryRequest{
-		Namespace:   quayOrg,
+		Namespace:   org,
 		Visibility:  "private",
 		Description: "Test private repository",
 		Repository:  constants.SamplePrivateRepoName,
 	}
-	repo, err := quayClient.CreateRepository(repositoryRequest)
+	repo, err := client.CreateRepository(repositoryRequest)
 	if err != nil {
 		if err.Error() == "payment required" {
 			return false, nil
@@ -134,7 +140,7 @@ func DoesQuayOrgSupportPrivateRepo() (bool, error) {
 		return false, fmt.Errorf("%v repository created is nil", repo)
 	}
 	// Delete the created image repo
-	_, err = DeleteImageRepo(constants.SamplePrivateRepoName)
+	_, err = client.DeleteRepository(org, constants.SamplePrivateRepoName)
 	if err != nil {
 		return true, fmt.Errorf("error while deleting private image repo: %v", err)
 	}
@@ -0,0 +1,219 @@
+package fingerprint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+type ChangeType string
+
+const (
+	ResourceAdded      ChangeType = "ResourceAdded"
+	ResourceRemoved    ChangeType = "ResourceRemoved"
+	FieldAdded         ChangeType = "FieldAdded"
+	FieldChanged       ChangeType = "FieldChanged"
+	FieldRemoved       ChangeType = "FieldRemoved"
+	ConditionChanged   ChangeType = "ConditionChanged"
+	ConditionRegressed ChangeType = "ConditionRegressed"
+)
+
+// Change is a single difference between two fingerprints
+type Change struct {
+	Resource string      `json:"resource"`
+	Type     ChangeType  `json:"type"`
+	Path     string      `json:"path,omitempty"`
+	Before   interface{} `json:"before,omitempty"`
+	After    interface{} `json:"after,omitempty"`
+	// Regression is true for changes that are not expected after an upgrade
+	Regression bool `json:"regression"`
+}
+
+// DiffOptions configure the comparison of fingerprints
+type DiffOptions struct {
+	// IgnoredPaths maps a resource kind to path prefixes (e.g. "spec.containerImage") which are not compared
+	IgnoredPaths map[string][]string
+}
+
+// Diff is the semantic difference between fingerprints captured before and after an upgrade
+type Diff struct {
+	Changes []Change `json:"changes"`
+}
+
+// Compare computes the difference between the fingerprints. The following changes are considered to be regressions:
+// removed resources, changed or removed labels and spec fields, and conditions which were True before and are not anymore.
+// Added resources, labels and spec fields (e.g. defaults of new API fields) are reported, but are not regressions.
+func Compare(before, after *Fingerprint, opts DiffOptions) *Diff {
+	d := &Diff{}
+	beforeByKey, afterByKey := before.byKey(), after.byKey()
+	for _, b := range before.Resources {
+		a, ok := afterByKey[b.Key()]
+		if !ok {
+			d.Changes = append(d.Changes, Change{Resource: b.Key(), Type: ResourceRemoved, Regression: true})
+			continue
+		}
+		d.compareResource(b, a, opts.IgnoredPaths[b.Kind])
+	}
+	for _, a := range after.Resources {
+		if _, ok := beforeByKey[a.Key()]; !ok {
+			d.Changes = append(d.Changes, Change{Resource: a.Key(), Type: ResourceAdded})
+		}
+	}
+	sort.SliceStable(d.Changes, func(i, j int) bool { return d.Changes[i].Resource < d.Changes[j].Resource })
+	return d
+}
+
+func (d *Diff) compareResource(before, after Resource, ignoredPaths []string) {
+	beforeFields, afterFields := map[string]interface{}{}, map[string]interface{}{}
+	flatten("metadata.labels", toInterfaceMap(before.Labels), beforeFields)
+	flatten("metadata.labels", toInterfaceMap(after.Labels), afterFields)
+	flatten("spec", before.Spec, beforeFields)
+	flatten("spec", after.Spec, afterFields)
+
+	for _, path := range sortedKeys(beforeFields) {
+		if isIgnored(path, ignoredPaths) {
+			continue
+		}
+		b := beforeFields[path]
+		a, ok := afterFields[path]
+		switch {
+		case !ok:
+			d.Changes = append(d.Changes, Change{Resource: before.Key(), Type: FieldRemoved, Path: path, Before: b, Regression: true})
+		case !reflect.DeepEqual(a, b):
+			d.Changes = append(d.Changes, Change{Resource: before.Key(), Type: FieldChanged, Path: path, Before: b, After: a, Regression: true})
+		}
+	}
+	for _, path := range sortedKeys(afterFields) {
+		if _, ok := beforeFields[path]; !ok && !isIgnored(path, ignoredPaths) {
+			d.Changes = append(d.Changes, Change{Resource: before.Key(), Type: FieldAdded, Path: path, After: afterFields[path]})
+		}
+	}
+
+	afterConditions := map[string]Condition{}
+	for _, c := range after.Conditions {
+		afterConditions[c.Type] = c
+	}
+	for _, b := range before.Conditions {
+		path := "status.conditions[" + b.Type + "]"
+		if isIgnored(path, ignoredPaths) {
+			continue
+		}
+		a, ok := afterConditions[b.Type]
+		if ok && a == b {
+			continue
+		}
+		change := Change{Resource: before.Key(), Type: ConditionChanged, Path: path, Before: b}
+		if ok {
+			change.After = a
+		}
+		if b.Status == "True" && (!ok || a.Status != "True") {
+			change.Type, change.Regression = ConditionRegressed, true
+		}
+		d.Changes = append(d.Changes, change)
+	}
+}
+
+// Regressions returns changes which are not expected after an upgrade
+func (d *Diff) Regressions() []Change {
+	var regressions []Change
+	for _, c := range d.Changes {
+		if c.Regression {
+			regressions = append(regressions, c)
+		}
+	}
+	return regressions
+}
+
+// PrintTable prints all changes, regressions are marked with "!"
+func (d *Diff) PrintTable(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tRESOURCE\tCHANGE\tPATH\tBEFORE\tAFTER")
+	for _, c := range d.Changes {
+		mark := ""
+		if c.Regression {
+			mark = "!"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", mark, c.Resource, c.Type, c.Path, formatValue(c.Before), formatValue(c.After))
+	}
+	return w.Flush()
+}
+
+// Save writes the diff to dir as <name>.json and <name>.txt
+func (d *Diff) Save(dir, name string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory %s: %+v", dir, err)
+	}
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal diff: %+v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".json"), data, 0600); err != nil {
+		return fmt.Errorf("failed to write diff: %+v", err)
+	}
+	f, err := os.Create(filepath.Join(dir, name+".txt"))
+	if err != nil {
+		return fmt.Errorf("failed to write diff: %+v", err)
+	}
+	defer f.Close()
+	return d.PrintTable(f)
+}
+
+// flatten stores leaf values of v to fields under dot separated paths, list items are addressed by their index
+func flatten(prefix string, v interface{}, fields map[string]interface{}) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, item := range t {
+			flatten(prefix+"."+k, item, fields)
+		}
+	case []interface{}:
+		for i, item := range t {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), item, fields)
+		}
+	case nil:
+	default:
+		fields[prefix] = t
+	}
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	res := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		res[k] = v
+	}
+	return res
+}
+
+func isIgnored(path string, ignoredPaths []string) bool {
+	for _, p := range ignoredPaths {
+		if path == p || strings.HasPrefix(path, p+".") || strings.HasPrefix(path, p+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case Condition:
+		return strings.TrimSuffix(fmt.Sprintf("%s (%s)", t.Status, t.Reason), " ()")
+	default:
+		return fmt.Sprint(t)
+	}
+}
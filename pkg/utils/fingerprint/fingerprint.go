@@ -0,0 +1,188 @@
+package fingerprint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// WorkloadKinds are the Konflux resources captured in tenant namespaces
+	WorkloadKinds = []schema.GroupVersionKind{
+		{Group: "appstudio.redhat.com", Version: "v1alpha1", Kind: "Application"},
+		{Group: "appstudio.redhat.com", Version: "v1alpha1", Kind: "Component"},
+		{Group: "appstudio.redhat.com", Version: "v1alpha1", Kind: "Snapshot"},
+		{Group: "appstudio.redhat.com", Version: "v1alpha1", Kind: "ReleasePlan"},
+		{Group: "appstudio.redhat.com", Version: "v1beta1", Kind: "IntegrationTestScenario"},
+		{Group: "appstudio.redhat.com", Version: "v1alpha1", Kind: "EnterpriseContractPolicy"},
+	}
+	SpaceKind = schema.GroupVersionKind{Group: "toolchain.dev.openshift.com", Version: "v1alpha1", Kind: "Space"}
+)
+
+// Condition is a status condition without timestamps and messages
+type Condition struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Resource is a normalised view of a Kubernetes object: only labels, spec and status conditions are kept,
+// so fields changing on every update (resourceVersion, managedFields, timestamps, ...) do not show up in diffs
+type Resource struct {
+	APIVersion string                 `json:"apiVersion"`
+	Kind       string                 `json:"kind"`
+	Namespace  string                 `json:"namespace,omitempty"`
+	Name       string                 `json:"name"`
+	Labels     map[string]string      `json:"labels,omitempty"`
+	Spec       map[string]interface{} `json:"spec,omitempty"`
+	Conditions []Condition            `json:"conditions,omitempty"`
+}
+
+// Key identifies the resource within a fingerprint
+func (r Resource) Key() string {
+	if r.Namespace == "" {
+		return r.Kind + "/" + r.Name
+	}
+	return r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+// Fingerprint is a normalised snapshot of resources, sorted by their keys
+type Fingerprint struct {
+	Resources []Resource `json:"resources"`
+}
+
+// NewResource normalises the object
+func NewResource(obj *unstructured.Unstructured) Resource {
+	r := Resource{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+		Labels:     obj.GetLabels(),
+	}
+	if spec, found, _ := unstructured.NestedMap(obj.Object, "spec"); found {
+		// JSON round trip makes the values comparable with fingerprints loaded from a file (e.g. int64 -> float64)
+		if data, err := json.Marshal(spec); err == nil {
+			_ = json.Unmarshal(data, &r.Spec)
+		}
+	}
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		if m, ok := c.(map[string]interface{}); ok {
+			r.Conditions = append(r.Conditions, Condition{Type: fmt.Sprint(m["type"]), Status: fmt.Sprint(m["status"]), Reason: stringValue(m["reason"])})
+		}
+	}
+	sort.Slice(r.Conditions, func(i, j int) bool { return r.Conditions[i].Type < r.Conditions[j].Type })
+	return r
+}
+
+// FromObjects creates a fingerprint of the objects
+func FromObjects(objs ...unstructured.Unstructured) *Fingerprint {
+	f := &Fingerprint{}
+	for i := range objs {
+		f.Resources = append(f.Resources, NewResource(&objs[i]))
+	}
+	sort.Slice(f.Resources, func(i, j int) bool { return f.Resources[i].Key() < f.Resources[j].Key() })
+	return f
+}
+
+// Capture lists resources of the given kinds in the namespaces. Kinds not served by the cluster are skipped.
+func Capture(ctx context.Context, c client.Reader, namespaces []string, kinds []schema.GroupVersionKind) (*Fingerprint, error) {
+	var objs []unstructured.Unstructured
+	for _, ns := range namespaces {
+		for _, gvk := range kinds {
+			list := &unstructured.UnstructuredList{}
+			list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+			if err := c.List(ctx, list, client.InNamespace(ns)); err != nil {
+				if meta.IsNoMatchError(err) {
+					continue
+				}
+				return nil, fmt.Errorf("failed to list %s in %s namespace: %+v", gvk.Kind, ns, err)
+			}
+			objs = append(objs, list.Items...)
+		}
+	}
+	return FromObjects(objs...), nil
+}
+
+// CaptureSpaces captures the toolchain Spaces with the given names (Spaces not found are skipped)
+// and resources of the given kinds in the namespaces provisioned for them
+func CaptureSpaces(ctx context.Context, c client.Reader, spaceNamespace string, spaces []string, kinds []schema.GroupVersionKind) (*Fingerprint, error) {
+	var spaceObjs []unstructured.Unstructured
+	var namespaces []string
+	for _, name := range spaces {
+		space := &unstructured.Unstructured{}
+		space.SetGroupVersionKind(SpaceKind)
+		if err := c.Get(ctx, types.NamespacedName{Namespace: spaceNamespace, Name: name}, space); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get Space %s: %+v", name, err)
+		}
+		spaceObjs = append(spaceObjs, *space)
+		provisioned, _, _ := unstructured.NestedSlice(space.Object, "status", "provisionedNamespaces")
+		for _, pns := range provisioned {
+			if m, ok := pns.(map[string]interface{}); ok {
+				namespaces = append(namespaces, stringValue(m["name"]))
+			}
+		}
+	}
+	f, err := Capture(ctx, c, namespaces, kinds)
+	if err != nil {
+		return nil, err
+	}
+	for i := range spaceObjs {
+		f.Resources = append(f.Resources, NewResource(&spaceObjs[i]))
+	}
+	sort.Slice(f.Resources, func(i, j int) bool { return f.Resources[i].Key() < f.Resources[j].Key() })
+	return f, nil
+}
+
+// Save writes the fingerprint in JSON format to the file
+func (f *Fingerprint) Save(file string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal fingerprint: %+v", err)
+	}
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		return fmt.Errorf("failed to write fingerprint %s: %+v", file, err)
+	}
+	return nil
+}
+
+// Load reads a fingerprint saved by Save
+func Load(file string) (*Fingerprint, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fingerprint %s: %+v", file, err)
+	}
+	f := &Fingerprint{}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("failed to parse fingerprint %s: %+v", file, err)
+	}
+	return f, nil
+}
+
+func (f *Fingerprint) byKey() map[string]Resource {
+	m := make(map[string]Resource, len(f.Resources))
+	for _, r := range f.Resources {
+		m[r.Key()] = r
+	}
+	return m
+}
+
+func stringValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+package fingerprint
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func component(spec map[string]interface{}, conditions ...interface{}) unstructured.Unstructured {
+	obj := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "appstudio.redhat.com/v1alpha1",
+		"kind":       "Component",
+		"metadata": map[string]interface{}{
+			"name":            "comp",
+			"namespace":       "tenant",
+			"resourceVersion": "1",
+			"labels":          map[string]interface{}{"app": "demo"},
+		},
+		"spec":   spec,
+		"status": map[string]interface{}{"conditions": conditions},
+	}}
+	return obj
+}
+
+func condition(conditionType, status, transitionTime string) map[string]interface{} {
+	return map[string]interface{}{"type": conditionType, "status": status, "reason": "OK", "lastTransitionTime": transitionTime}
+}
+
+func TestNewResource(t *testing.T) {
+	obj := component(map[string]interface{}{"application": "demo"}, condition("Created", "True", "2024-01-01T00:00:00Z"))
+	obj.SetManagedFields(nil)
+
+	assert.Equal(t, Resource{
+		APIVersion: "appstudio.redhat.com/v1alpha1",
+		Kind:       "Component",
+		Namespace:  "tenant",
+		Name:       "comp",
+		Labels:     map[string]string{"app": "demo"},
+		Spec:       map[string]interface{}{"application": "demo"},
+		Conditions: []Condition{{Type: "Created", Status: "True", Reason: "OK"}},
+	}, NewResource(&obj))
+}
+
+func TestCompare(t *testing.T) {
+	before := FromObjects(
+		component(map[string]interface{}{"application": "demo", "source": map[string]interface{}{"url": "https://a"}},
+			condition("Created", "True", "2024-01-01T00:00:00Z"), condition("Updated", "False", "2024-01-01T00:00:00Z")),
+	)
+
+	t.Run("only volatile fields changed", func(t *testing.T) {
+		after := FromObjects(component(map[string]interface{}{"application": "demo", "source": map[string]interface{}{"url": "https://a"}},
+			condition("Created", "True", "2024-02-01T00:00:00Z"), condition("Updated", "False", "2024-02-01T00:00:00Z")))
+		assert.Empty(t, Compare(before, after, DiffOptions{}).Changes)
+	})
+
+	t.Run("regressions", func(t *testing.T) {
+		after := FromObjects(component(map[string]interface{}{"application": "demo", "source": map[string]interface{}{"url": "https://b"}, "replicas": int64(1)},
+			condition("Created", "False", "2024-02-01T00:00:00Z"), condition("Updated", "True", "2024-02-01T00:00:00Z")))
+		diff := Compare(before, after, DiffOptions{})
+
+		assert.Equal(t, []Change{
+			{Resource: "Component/tenant/comp", Type: FieldChanged, Path: "spec.source.url", Before: "https://a", After: "https://b", Regression: true},
+			{Resource: "Component/tenant/comp", Type: ConditionRegressed, Path: "status.conditions[Created]",
+				Before: Condition{Type: "Created", Status: "True", Reason: "OK"}, After: Condition{Type: "Created", Status: "False", Reason: "OK"}, Regression: true},
+		}, diff.Regressions())
+		assert.Len(t, diff.Changes, 4, "added field and improved condition are reported as well")
+
+		assert.Empty(t, Compare(before, after, DiffOptions{IgnoredPaths: map[string][]string{"Component": {"spec.source", "status.conditions[Created]"}}}).Regressions())
+	})
+
+	t.Run("saved fingerprint", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "fingerprint.json")
+		assert.NoError(t, before.Save(file))
+		loaded, err := Load(file)
+		assert.NoError(t, err)
+		assert.Empty(t, Compare(loaded, before, DiffOptions{}).Changes)
+	})
+
+	t.Run("removed and added resources", func(t *testing.T) {
+		other := component(nil)
+		other.SetName("other")
+		diff := Compare(before, FromObjects(other), DiffOptions{})
+		assert.Equal(t, []Change{
+			{Resource: "Component/tenant/comp", Type: ResourceRemoved, Regression: true},
+			{Resource: "Component/tenant/other", Type: ResourceAdded},
+		}, diff.Changes)
+	})
+}
+
+func TestCaptureSpaces(t *testing.T) {
+	space := unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "toolchain.dev.openshift.com/v1alpha1",
+		"kind":       "Space",
+		"metadata":   map[string]interface{}{"name": "user", "namespace": "toolchain-host-operator"},
+		"spec":       map[string]interface{}{"tierName": "appstudio"},
+		"status":     map[string]interface{}{"provisionedNamespaces": []interface{}{map[string]interface{}{"name": "tenant", "type": "default"}}},
+	}}
+	comp := component(map[string]interface{}{"application": "demo"})
+	ignored := component(nil)
+	ignored.SetNamespace("other-tenant")
+	c := fake.NewClientBuilder().WithObjects(&space, &comp, &ignored).Build()
+
+	f, err := CaptureSpaces(context.Background(), c, "toolchain-host-operator", []string{"user", "missing-user"}, WorkloadKinds[1:2])
+	assert.NoError(t, err)
+	keys := []string{}
+	for _, r := range f.Resources {
+		keys = append(keys, r.Key())
+	}
+	assert.Equal(t, []string{"Component/tenant/comp", "Space/toolchain-host-operator/user"}, keys)
+}
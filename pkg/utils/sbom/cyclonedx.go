@@ -0,0 +1,87 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Properties Konflux build pipelines set on components of the images the content was built from
+const (
+	baseImageProperty    = "konflux:container:is_base_image"
+	builderImageProperty = "konflux:container:is_builder_image:for_stage"
+)
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cycloneDXComponent struct {
+	BomRef     string               `json:"bom-ref"`
+	Type       string               `json:"type"`
+	Name       string               `json:"name"`
+	Version    string               `json:"version"`
+	Purl       string               `json:"purl"`
+	Properties []cycloneDXProperty  `json:"properties"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXFormula struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXDocument struct {
+	BomFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Components  []cycloneDXComponent `json:"components"`
+	// Formulation is available since CycloneDX 1.5, Konflux records the base images in it
+	Formulation []cycloneDXFormula `json:"formulation"`
+}
+
+func (c cycloneDXComponent) isBaseImage() bool {
+	for _, p := range c.Properties {
+		if p.Name == baseImageProperty || p.Name == builderImageProperty {
+			return true
+		}
+	}
+	return false
+}
+
+func (c cycloneDXComponent) component() Component {
+	return Component{Name: c.Name, Version: c.Version, Purl: c.Purl, Type: c.Type}
+}
+
+// flatten returns the components together with all their nested components
+func flatten(components []cycloneDXComponent) []cycloneDXComponent {
+	var result []cycloneDXComponent
+	for _, c := range components {
+		result = append(result, c)
+		result = append(result, flatten(c.Components)...)
+	}
+	return result
+}
+
+func parseCycloneDX(data []byte) (*SBOM, error) {
+	doc := &cycloneDXDocument{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("failed to parse CycloneDX sbom: %+v", err)
+	}
+
+	sbom := &SBOM{Format: CycloneDX, SpecVersion: doc.SpecVersion}
+	for _, c := range flatten(doc.Components) {
+		if c.isBaseImage() {
+			sbom.BaseImages = append(sbom.BaseImages, c.component())
+		} else {
+			sbom.Components = append(sbom.Components, c.component())
+		}
+	}
+	for _, f := range doc.Formulation {
+		for _, c := range flatten(f.Components) {
+			if c.isBaseImage() {
+				sbom.BaseImages = append(sbom.BaseImages, c.component())
+			}
+		}
+	}
+	return sbom, nil
+}
@@ -0,0 +1,129 @@
+package sbom
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/konflux-ci/e2e-tests/pkg/utils/build"
+)
+
+// imageSbomPaths are the paths where Konflux build pipelines store the SBOM in the image filesystem
+var imageSbomPaths = []string{
+	"root/buildinfo/content_manifests/sbom-cyclonedx.json",
+	"root/buildinfo/content_manifests/sbom-spdx.json",
+}
+
+// sbomPredicateTypes are in-toto predicate types of attestations containing an SBOM
+var sbomPredicateTypes = []string{
+	"https://cyclonedx.org/bom",
+	"https://spdx.dev/Document",
+}
+
+// LoadFromFile loads the SBOM from a file
+func LoadFromFile(path string) (*SBOM, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error when reading sbom file %s: %+v", path, err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("sbom file %s is empty", path)
+	}
+	return Parse(data)
+}
+
+// LoadFromImage loads the SBOM stored in the filesystem of the image by the build pipeline
+func LoadFromImage(image string) (*SBOM, error) {
+	tmpDir, err := build.ExtractImage(image)
+	defer os.RemoveAll(tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range imageSbomPaths {
+		if _, err := os.Stat(filepath.Join(tmpDir, path)); err == nil {
+			return LoadFromFile(filepath.Join(tmpDir, path))
+		}
+	}
+	return nil, fmt.Errorf("sbom not found in image %s, looked for %v", image, imageSbomPaths)
+}
+
+// LoadFromAttestation loads the SBOM from the attestation of the image pushed to the registry by Tekton Chains or cosign
+// (the <repository>:sha256-<digest>.att image). It uses the credentials stored in ~/.docker/config.json.
+func LoadFromAttestation(image string) (*SBOM, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse image reference %s: %+v", image, err)
+	}
+	descriptor, err := remote.Get(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image %s: %+v", image, err)
+	}
+	attestationTag := ref.Context().Tag(fmt.Sprintf("%s-%s.att", descriptor.Digest.Algorithm, descriptor.Digest.Hex))
+	attestation, err := remote.Image(attestationTag, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attestation %s: %+v", attestationTag, err)
+	}
+	layers, err := attestation.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get layers of attestation %s: %+v", attestationTag, err)
+	}
+
+	for _, layer := range layers {
+		rc, err := layer.Uncompressed()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read layer of attestation %s: %+v", attestationTag, err)
+		}
+		envelope, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read layer of attestation %s: %+v", attestationTag, err)
+		}
+		predicate, err := sbomPredicateFromEnvelope(envelope)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse attestation %s: %+v", attestationTag, err)
+		}
+		if predicate != nil {
+			return Parse(predicate)
+		}
+	}
+	return nil, fmt.Errorf("no sbom attestation found in %s", attestationTag)
+}
+
+type dsseEnvelope struct {
+	PayloadType string `json:"payloadType"`
+	Payload     string `json:"payload"`
+}
+
+type inTotoStatement struct {
+	PredicateType string          `json:"predicateType"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// sbomPredicateFromEnvelope returns the SBOM the DSSE envelope attests, nil if the envelope attests something else (e.g. SLSA provenance)
+func sbomPredicateFromEnvelope(envelope []byte) ([]byte, error) {
+	e := &dsseEnvelope{}
+	if err := json.Unmarshal(envelope, e); err != nil {
+		return nil, fmt.Errorf("failed to parse DSSE envelope: %+v", err)
+	}
+	payload, err := base64.StdEncoding.DecodeString(e.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode payload of DSSE envelope: %+v", err)
+	}
+	statement := &inTotoStatement{}
+	if err := json.Unmarshal(payload, statement); err != nil {
+		return nil, fmt.Errorf("failed to parse in-toto statement: %+v", err)
+	}
+	for _, predicateType := range sbomPredicateTypes {
+		if strings.HasPrefix(statement.PredicateType, predicateType) {
+			return statement.Predicate, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,87 @@
+package sbom
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	purlTypeRegexp         = regexp.MustCompile(`^[a-zA-Z.+-][a-zA-Z0-9.+-]*$`)
+	purlQualifierKeyRegexp = regexp.MustCompile(`^[a-zA-Z.\-_][a-zA-Z0-9.\-_]*$`)
+)
+
+// Purl is a package URL, see https://github.com/package-url/purl-spec
+type Purl struct {
+	Type       string
+	Namespace  string
+	Name       string
+	Version    string
+	Qualifiers map[string]string
+	Subpath    string
+}
+
+// ParsePurl parses the package URL pkg:type/namespace/name@version?qualifiers#subpath and returns an error if it is malformed
+func ParsePurl(purl string) (*Purl, error) {
+	remainder, found := strings.CutPrefix(purl, "pkg:")
+	if !found {
+		return nil, fmt.Errorf("purl %q doesn't start with the pkg scheme", purl)
+	}
+	p := &Purl{Qualifiers: map[string]string{}}
+
+	remainder, subpath, _ := strings.Cut(remainder, "#")
+	p.Subpath = strings.Trim(subpath, "/")
+
+	remainder, qualifiers, _ := strings.Cut(remainder, "?")
+	if qualifiers != "" {
+		for _, qualifier := range strings.Split(qualifiers, "&") {
+			key, value, _ := strings.Cut(qualifier, "=")
+			if !purlQualifierKeyRegexp.MatchString(key) {
+				return nil, fmt.Errorf("purl %q has invalid qualifier %q", purl, qualifier)
+			}
+			// a qualifier with an empty value is the same as no qualifier
+			if value == "" {
+				continue
+			}
+			unescaped, err := url.PathUnescape(value)
+			if err != nil {
+				return nil, fmt.Errorf("purl %q has invalid qualifier %q: %+v", purl, qualifier, err)
+			}
+			p.Qualifiers[strings.ToLower(key)] = unescaped
+		}
+	}
+
+	remainder = strings.TrimLeft(remainder, "/")
+	if at := strings.LastIndex(remainder, "@"); at >= 0 {
+		version, err := url.PathUnescape(remainder[at+1:])
+		if err != nil || version == "" {
+			return nil, fmt.Errorf("purl %q has invalid version", purl)
+		}
+		p.Version, remainder = version, remainder[:at]
+	}
+
+	purlType, path, _ := strings.Cut(remainder, "/")
+	if !purlTypeRegexp.MatchString(purlType) {
+		return nil, fmt.Errorf("purl %q has invalid type %q", purl, purlType)
+	}
+	p.Type = strings.ToLower(purlType)
+
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil, fmt.Errorf("purl %q has no name", purl)
+	}
+	var namespace string
+	if slash := strings.LastIndex(path, "/"); slash >= 0 {
+		namespace, path = path[:slash], path[slash+1:]
+	}
+	name, err := url.PathUnescape(path)
+	if err != nil || name == "" {
+		return nil, fmt.Errorf("purl %q has invalid name", purl)
+	}
+	if p.Namespace, err = url.PathUnescape(namespace); err != nil {
+		return nil, fmt.Errorf("purl %q has invalid namespace: %+v", purl, err)
+	}
+	p.Name = name
+	return p, nil
+}
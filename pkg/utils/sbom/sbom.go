@@ -0,0 +1,95 @@
+// Package sbom loads CycloneDX and SPDX SBOMs produced by Konflux builds and releases,
+// validates them against a subset of their schema and verifies their content.
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Format of the SBOM document
+type Format string
+
+const (
+	CycloneDX Format = "CycloneDX"
+	SPDX      Format = "SPDX"
+)
+
+// SupportedCycloneDXVersions are the CycloneDX specification versions which can be loaded
+var SupportedCycloneDXVersions = []string{"1.4", "1.5", "1.6"}
+
+// SupportedSPDXVersions are the SPDX specification versions which can be loaded
+var SupportedSPDXVersions = []string{"SPDX-2.3"}
+
+// Component is a package (CycloneDX component or SPDX package) described by the SBOM
+type Component struct {
+	Name    string
+	Version string
+	Purl    string
+	Type    string
+}
+
+// SBOM is the format independent content of an SBOM document
+type SBOM struct {
+	Format      Format
+	SpecVersion string
+	// Components are all packages of the SBOM (including nested ones), without the base images
+	Components []Component
+	// BaseImages are the images the content was built from, as recorded by the Konflux build pipelines
+	BaseImages []Component
+	// Raw is the original document
+	Raw []byte
+}
+
+// document holds the fields used to detect the format of an SBOM document
+type document struct {
+	BomFormat   string `json:"bomFormat"`
+	SpecVersion string `json:"specVersion"`
+	SPDXVersion string `json:"spdxVersion"`
+}
+
+// Parse detects the format of the SBOM document, validates it against the (subset of the) schema of its version and parses it
+func Parse(data []byte) (*SBOM, error) {
+	doc := &document{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("failed to parse sbom: %+v", err)
+	}
+
+	var sbom *SBOM
+	var err error
+	switch {
+	case doc.BomFormat == string(CycloneDX):
+		if err := Validate(data, CycloneDX, doc.SpecVersion); err != nil {
+			return nil, err
+		}
+		sbom, err = parseCycloneDX(data)
+	case doc.SPDXVersion != "":
+		if err := Validate(data, SPDX, doc.SPDXVersion); err != nil {
+			return nil, err
+		}
+		sbom, err = parseSPDX(data)
+	default:
+		return nil, fmt.Errorf("unknown sbom format, the document is neither CycloneDX nor SPDX")
+	}
+	if err != nil {
+		return nil, err
+	}
+	sbom.Raw = data
+	sortComponents(sbom.Components)
+	sortComponents(sbom.BaseImages)
+	return sbom, nil
+}
+
+func sortComponents(components []Component) {
+	sort.SliceStable(components, func(i, j int) bool { return components[i].identity() < components[j].identity() })
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package sbom
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const ubiMinimal = "registry.access.redhat.com/ubi9/ubi-minimal@sha256:06d06f15f7b641a78f2512c8817cbecaa1bf549488e273f5ac27ff1654ed33f0"
+
+func TestLoadFromFile(t *testing.T) {
+	for _, file := range []string{"cyclonedx-1.4.json", "cyclonedx-1.5.json", "cyclonedx-1.6.json", "spdx-2.3.json"} {
+		t.Run(file, func(t *testing.T) {
+			sbom, err := LoadFromFile("testdata/" + file)
+			assert.NoError(t, err)
+			assert.NoError(t, VerifyComponentsPresent(sbom, "bash", "pkg:golang/github.com/sirupsen/logrus@v1.9.3", "pkg:rpm/rhel/bash"))
+			assert.NoError(t, VerifyPurls(sbom))
+			assert.NoError(t, VerifyBaseImages(sbom, ubiMinimal))
+			assert.NotEmpty(t, sbom.Raw)
+		})
+	}
+
+	sbom, err := LoadFromFile("testdata/cyclonedx-1.5.json")
+	assert.NoError(t, err)
+	assert.Equal(t, CycloneDX, sbom.Format)
+	assert.Equal(t, "1.5", sbom.SpecVersion)
+	assert.Equal(t, []Component{
+		{Name: "/usr/bin/bash", Type: "file"},
+		{Name: "github.com/sirupsen/logrus", Version: "v1.9.3", Purl: "pkg:golang/github.com/sirupsen/logrus@v1.9.3", Type: "library"},
+		{Name: "bash", Version: "5.1.8-6.el9", Purl: "pkg:rpm/rhel/bash@5.1.8-6.el9?arch=x86_64&distro=rhel-9.2", Type: "library"},
+	}, sbom.Components)
+	assert.Len(t, sbom.BaseImages, 2, "builder image of the formulation is recorded as a base image")
+}
+
+func TestParseInvalid(t *testing.T) {
+	data, err := os.ReadFile("testdata/cyclonedx-1.4.json")
+	assert.NoError(t, err)
+	doc := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(data, &doc))
+
+	doc["specVersion"] = "1.3"
+	data, _ = json.Marshal(doc)
+	_, err = Parse(data)
+	assert.ErrorContains(t, err, `unsupported CycloneDX version "1.3"`)
+
+	doc["specVersion"] = "1.4"
+	doc["components"] = []map[string]string{{"name": "no-type"}}
+	data, _ = json.Marshal(doc)
+	_, err = Parse(data)
+	assert.ErrorContains(t, err, "sbom is not valid CycloneDX 1.4")
+
+	_, err = Parse([]byte(`{"spdxVersion": "SPDX-2.3", "name": "missing-required-fields"}`))
+	assert.ErrorContains(t, err, "sbom is not valid SPDX SPDX-2.3")
+
+	_, err = Parse([]byte(`{"name": "unknown"}`))
+	assert.ErrorContains(t, err, "unknown sbom format")
+}
+
+func TestParsePurl(t *testing.T) {
+	p, err := ParsePurl("pkg:oci/ubi-minimal@sha256%3A06d0?repository_url=registry.access.redhat.com/ubi9/ubi-minimal&arch=amd64")
+	assert.NoError(t, err)
+	assert.Equal(t, &Purl{
+		Type:       "oci",
+		Name:       "ubi-minimal",
+		Version:    "sha256:06d0",
+		Qualifiers: map[string]string{"repository_url": "registry.access.redhat.com/ubi9/ubi-minimal", "arch": "amd64"},
+	}, p)
+
+	p, err = ParsePurl("pkg:golang/github.com/sirupsen/logrus@v1.9.3#hooks/syslog")
+	assert.NoError(t, err)
+	assert.Equal(t, "github.com/sirupsen", p.Namespace)
+	assert.Equal(t, "logrus", p.Name)
+	assert.Equal(t, "hooks/syslog", p.Subpath)
+
+	p, err = ParsePurl("pkg:rpm/bash?arch=&distro=rhel-9")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"distro": "rhel-9"}, p.Qualifiers)
+
+	for _, invalid := range []string{"golang/logrus", "pkg:golang", "pkg:1golang/logrus", "pkg:golang/logrus@", "pkg:rpm/bash?=rhel-9"} {
+		_, err := ParsePurl(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestVerifyFailures(t *testing.T) {
+	sbom := &SBOM{
+		Format:     CycloneDX,
+		Components: []Component{{Name: "logrus", Type: "library"}, {Name: "bash", Purl: "pkg:rpm", Type: "library"}},
+	}
+	assert.ErrorContains(t, VerifyComponentsPresent(sbom, "bash", "curl"), "components [curl] not found")
+	err := VerifyPurls(sbom)
+	assert.ErrorContains(t, err, "library logrus has no purl")
+	assert.ErrorContains(t, err, `purl "pkg:rpm" has no name`)
+	assert.ErrorContains(t, VerifyBaseImages(sbom), "no base image recorded")
+}
+
+func TestDiffSBOMs(t *testing.T) {
+	build, err := LoadFromFile("testdata/cyclonedx-1.5.json")
+	assert.NoError(t, err)
+	released := &SBOM{Components: []Component{
+		// the released SBOM has different qualifiers, which isn't a difference
+		{Name: "bash", Purl: "pkg:rpm/rhel/bash@5.1.8-6.el9?arch=x86_64"},
+		{Name: "github.com/sirupsen/logrus", Purl: "pkg:golang/github.com/sirupsen/logrus@v1.9.4"},
+		{Name: "/usr/bin/bash"},
+	}}
+
+	assert.True(t, DiffSBOMs(build, build).IsEmpty())
+	diff := DiffSBOMs(build, released)
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, "+ pkg:golang/github.com/sirupsen/logrus@v1.9.4\n- pkg:golang/github.com/sirupsen/logrus@v1.9.3", diff.String())
+}
+
+func TestSbomPredicateFromEnvelope(t *testing.T) {
+	envelope := func(predicateType string) []byte {
+		statement, _ := json.Marshal(map[string]interface{}{
+			"_type":         "https://in-toto.io/Statement/v0.1",
+			"predicateType": predicateType,
+			"predicate":     map[string]string{"bomFormat": "CycloneDX", "specVersion": "1.5"},
+		})
+		e, _ := json.Marshal(dsseEnvelope{PayloadType: "application/vnd.in-toto+json", Payload: base64.StdEncoding.EncodeToString(statement)})
+		return e
+	}
+
+	predicate, err := sbomPredicateFromEnvelope(envelope("https://cyclonedx.org/bom"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"bomFormat": "CycloneDX", "specVersion": "1.5"}`, string(predicate))
+
+	predicate, err = sbomPredicateFromEnvelope(envelope("https://slsa.dev/provenance/v0.2"))
+	assert.NoError(t, err)
+	assert.Nil(t, predicate)
+
+	_, err = sbomPredicateFromEnvelope([]byte(`{"payload": "not base64"}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+package sbom
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// The schemas are vendored by `make sbom/schemas` from the official specifications:
+//   - CycloneDX: https://github.com/CycloneDX/specification/tree/1.6/schema (bom-1.4/1.5/1.6 and the
+//     spdx.schema.json and jsf-0.82.schema.json sub-schemas they $ref)
+//   - SPDX: https://github.com/spdx/spdx-spec/blob/v2.3/schemas/spdx-schema.json
+//
+// Schemas whose "$comment" marks them as a subset haven't been vendored yet and only cover the fields used by the e2e tests.
+//
+//go:embed schemas/*.json
+var schemas embed.FS
+
+// cycloneDXSchemaURL is the URL the CycloneDX schemas resolve their $ref'd sub-schemas against
+const cycloneDXSchemaURL = "http://cyclonedx.org/schema/"
+
+// schemaFile returns the path of the embedded schema of the format version
+func schemaFile(format Format, version string) string {
+	if format == SPDX {
+		return fmt.Sprintf("schemas/spdx-%s.schema.json", version[len("SPDX-"):])
+	}
+	return fmt.Sprintf("schemas/cyclonedx-%s.schema.json", version)
+}
+
+// newSchemaCompiler returns a compiler with all the embedded schemas, the sub-schemas are added
+// under the URL the CycloneDX schemas refer to them by so no $ref is fetched over the network
+func newSchemaCompiler() (*jsonschema.Compiler, error) {
+	entries, err := schemas.ReadDir("schemas")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schemas: %+v", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	for _, entry := range entries {
+		file := path.Join("schemas", entry.Name())
+		content, err := schemas.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema %s: %+v", file, err)
+		}
+		url := file
+		if !strings.HasPrefix(entry.Name(), "cyclonedx-") && !strings.HasPrefix(entry.Name(), "spdx-") {
+			url = cycloneDXSchemaURL + entry.Name()
+		}
+		if err := compiler.AddResource(url, bytes.NewReader(content)); err != nil {
+			return nil, fmt.Errorf("failed to load schema %s: %+v", file, err)
+		}
+	}
+	return compiler, nil
+}
+
+// Validate validates the SBOM document against the embedded JSON schema of the format version,
+// e.g. Validate(data, CycloneDX, "1.5") or Validate(data, SPDX, "SPDX-2.3").
+func Validate(data []byte, format Format, version string) error {
+	supported := SupportedCycloneDXVersions
+	if format == SPDX {
+		supported = SupportedSPDXVersions
+	}
+	if !contains(supported, version) {
+		return fmt.Errorf("unsupported %s version %q, supported versions are %v", format, version, supported)
+	}
+
+	compiler, err := newSchemaCompiler()
+	if err != nil {
+		return err
+	}
+	file := schemaFile(format, version)
+	schema, err := compiler.Compile(file)
+	if err != nil {
+		return fmt.Errorf("failed to compile schema %s: %+v", file, err)
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse sbom: %+v", err)
+	}
+	if err := schema.Validate(doc); err != nil {
+		return fmt.Errorf("sbom is not valid %s %s: %+v", format, version, err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// spdxJSONAnnotator is the annotator of SPDX annotations holding a JSON encoded CycloneDX-like property
+const spdxJSONAnnotator = "Tool: konflux:jsonencoded"
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxAnnotation struct {
+	Annotator string `json:"annotator"`
+	Comment   string `json:"comment"`
+}
+
+type spdxPackage struct {
+	SPDXID       string            `json:"SPDXID"`
+	Name         string            `json:"name"`
+	VersionInfo  string            `json:"versionInfo"`
+	ExternalRefs []spdxExternalRef `json:"externalRefs"`
+	Annotations  []spdxAnnotation  `json:"annotations"`
+}
+
+type spdxDocument struct {
+	SPDXVersion string        `json:"spdxVersion"`
+	Packages    []spdxPackage `json:"packages"`
+}
+
+// purl returns the package URL of the package, SPDX 2.3 allows both PACKAGE-MANAGER and PACKAGE_MANAGER categories
+func (p spdxPackage) purl() string {
+	for _, ref := range p.ExternalRefs {
+		if ref.ReferenceType == "purl" && (ref.ReferenceCategory == "PACKAGE-MANAGER" || ref.ReferenceCategory == "PACKAGE_MANAGER") {
+			return ref.ReferenceLocator
+		}
+	}
+	return ""
+}
+
+func (p spdxPackage) isBaseImage() bool {
+	for _, a := range p.Annotations {
+		if a.Annotator != spdxJSONAnnotator {
+			continue
+		}
+		property := cycloneDXProperty{}
+		if err := json.Unmarshal([]byte(a.Comment), &property); err != nil {
+			continue
+		}
+		if property.Name == baseImageProperty || property.Name == builderImageProperty {
+			return true
+		}
+	}
+	return false
+}
+
+// packageType returns the type of the package derived from its purl, as SPDX packages have no type
+func packageType(purl string) string {
+	if parsed, err := ParsePurl(purl); err == nil {
+		if parsed.Type == "oci" || parsed.Type == "docker" {
+			return "container"
+		}
+		return "library"
+	}
+	return ""
+}
+
+func parseSPDX(data []byte) (*SBOM, error) {
+	doc := &spdxDocument{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("failed to parse SPDX sbom: %+v", err)
+	}
+
+	sbom := &SBOM{Format: SPDX, SpecVersion: doc.SPDXVersion}
+	for _, p := range doc.Packages {
+		purl := p.purl()
+		component := Component{Name: p.Name, Version: p.VersionInfo, Purl: purl, Type: packageType(purl)}
+		if p.isBaseImage() {
+			sbom.BaseImages = append(sbom.BaseImages, component)
+		} else {
+			sbom.Components = append(sbom.Components, component)
+		}
+	}
+	return sbom, nil
+}
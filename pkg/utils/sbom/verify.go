@@ -0,0 +1,167 @@
+package sbom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// identity returns the purl without the qualifiers and the subpath, which may differ between a build-time
+// and a released SBOM (e.g. repository_url of a released image), or the name and the version if the purl is malformed
+func (c Component) identity() string {
+	if p, err := ParsePurl(c.Purl); err == nil {
+		identity := fmt.Sprintf("pkg:%s/", p.Type)
+		if p.Namespace != "" {
+			identity += p.Namespace + "/"
+		}
+		identity += p.Name
+		if p.Version != "" {
+			identity += "@" + p.Version
+		}
+		return identity
+	}
+	if c.Version != "" {
+		return c.Name + "@" + c.Version
+	}
+	return c.Name
+}
+
+// matches tells whether the component is the one referenced by the name, the purl,
+// or the purl without the version and qualifiers (e.g. pkg:golang/github.com/sirupsen/logrus)
+func (c Component) matches(reference string) bool {
+	if c.Name == reference || c.Purl == reference {
+		return true
+	}
+	identity := c.identity()
+	return identity == reference || strings.HasPrefix(identity, reference+"@")
+}
+
+// VerifyComponentsPresent checks that the SBOM describes all the required components,
+// each one referenced by its name, purl or purl without the version
+func VerifyComponentsPresent(sbom *SBOM, required ...string) error {
+	var missing []string
+	for _, reference := range required {
+		found := false
+		for _, c := range sbom.Components {
+			if c.matches(reference) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, reference)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("components %v not found in the %s sbom", missing, sbom.Format)
+	}
+	return nil
+}
+
+// VerifyPurls checks that purls of all components are well-formed and that every library and application has one
+func VerifyPurls(sbom *SBOM) error {
+	var problems []string
+	for _, c := range append(append([]Component{}, sbom.Components...), sbom.BaseImages...) {
+		if c.Purl == "" {
+			if c.Type == "library" || c.Type == "application" {
+				problems = append(problems, fmt.Sprintf("%s %s has no purl", c.Type, c.Name))
+			}
+			continue
+		}
+		if _, err := ParsePurl(c.Purl); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("sbom has invalid purls:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// VerifyBaseImages checks that the SBOM records the base images, each one given as repository or repository@digest.
+// Without any image given it checks that at least one base image is recorded.
+func VerifyBaseImages(sbom *SBOM, images ...string) error {
+	if len(images) == 0 {
+		if len(sbom.BaseImages) == 0 {
+			return fmt.Errorf("no base image recorded in the %s sbom", sbom.Format)
+		}
+		return nil
+	}
+
+	var missing []string
+	for _, image := range images {
+		repository, digest, _ := strings.Cut(image, "@")
+		found := false
+		for _, baseImage := range sbom.BaseImages {
+			if baseImage.Name != repository && baseImage.Name != image {
+				continue
+			}
+			version := baseImage.Version
+			if p, err := ParsePurl(baseImage.Purl); err == nil && p.Version != "" {
+				version = p.Version
+			}
+			if digest == "" || version == digest || baseImage.Name == image {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, image)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("base images %v not recorded in the %s sbom, recorded base images are %v", missing, sbom.Format, sbom.BaseImages)
+	}
+	return nil
+}
+
+// Diff is the difference between components of two SBOMs, each list is sorted by the purl (or name)
+type Diff struct {
+	Added   []Component
+	Removed []Component
+}
+
+// DiffSBOMs compares components of the build-time SBOM with the ones of a later SBOM, e.g. uploaded to Pyxis by the release pipeline.
+// Components are identified by their purl without the qualifiers, so the different location of a released image isn't reported.
+func DiffSBOMs(from, to *SBOM) Diff {
+	oldComponents := map[string]Component{}
+	for _, c := range from.Components {
+		oldComponents[c.identity()] = c
+	}
+	newComponents := map[string]Component{}
+	for _, c := range to.Components {
+		newComponents[c.identity()] = c
+	}
+
+	diff := Diff{}
+	for identity, c := range newComponents {
+		if _, ok := oldComponents[identity]; !ok {
+			diff.Added = append(diff.Added, c)
+		}
+	}
+	for identity, c := range oldComponents {
+		if _, ok := newComponents[identity]; !ok {
+			diff.Removed = append(diff.Removed, c)
+		}
+	}
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].identity() < diff.Added[j].identity() })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].identity() < diff.Removed[j].identity() })
+	return diff
+}
+
+// IsEmpty tells whether both SBOMs describe the same components
+func (d Diff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// String returns the diff in a human-readable form, one component per line
+func (d Diff) String() string {
+	var lines []string
+	for _, c := range d.Added {
+		lines = append(lines, "+ "+c.identity())
+	}
+	for _, c := range d.Removed {
+		lines = append(lines, "- "+c.identity())
+	}
+	return strings.Join(lines, "\n")
+}
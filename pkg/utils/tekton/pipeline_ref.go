@@ -4,6 +4,24 @@ import (
 	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 )
 
+// resolverRequiredParams are the params the Tekton resolvers need to locate a pipeline
+var resolverRequiredParams = map[string][]string{
+	"git":     {"url", "revision", "pathInRepo"},
+	"bundles": {"bundle", "name", "kind"},
+	"cluster": {"name", "namespace", "kind"},
+}
+
+// MissingResolverParams returns the params the resolver needs to locate a pipeline which are not set (or are empty) in the params
+func MissingResolverParams(resolver string, params map[string]string) []string {
+	var missing []string
+	for _, name := range resolverRequiredParams[resolver] {
+		if params[name] == "" {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
 // GetPipelineNameAndBundleRef returns the pipeline name and bundle reference from a pipelineRef
 // https://tekton.dev/docs/pipelines/pipelineruns/#tekton-bundles
 func GetPipelineNameAndBundleRef(pipelineRef *pipeline.PipelineRef) (string, string) {
@@ -0,0 +1,14 @@
+package tekton
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingResolverParams(t *testing.T) {
+	assert.Empty(t, MissingResolverParams("git", map[string]string{"url": "https://github.com/org/repo", "revision": "main", "pathInRepo": "pipeline.yaml"}))
+	assert.Equal(t, []string{"revision", "pathInRepo"}, MissingResolverParams("git", map[string]string{"url": "https://github.com/org/repo", "revision": ""}))
+	assert.Equal(t, []string{"kind"}, MissingResolverParams("bundles", map[string]string{"bundle": "quay.io/org/bundle:tag", "name": "pipeline"}))
+	assert.Empty(t, MissingResolverParams("http", nil))
+}
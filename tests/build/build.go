@@ -558,7 +558,10 @@ var _ = framework.BuildSuiteDescribe("Build service E2E tests", Label("build", "
 
 		When("the component is removed and recreated (with the same name in the same namespace)", Label("build-custom-branch"), func() {
 			BeforeAll(func() {
-				Expect(f.AsKubeAdmin.HasController.DeleteComponent(componentName, testNamespace, true)).To(Succeed())
+				component, err = f.AsKubeAdmin.HasController.GetComponent(componentName, testNamespace)
+				Expect(err).ShouldNot(HaveOccurred())
+				// verify the PipelineRuns, the ImageRepository with its secrets and the Quay repository of the component are garbage-collected
+				Expect(f.AsKubeAdmin.HasController.DeleteComponentAndVerify(component, time.Minute*5)).To(Succeed())
 
 				timeout = 1 * time.Minute
 				interval = 1 * time.Second
@@ -1412,7 +1415,8 @@ var _ = framework.BuildSuiteDescribe("Build service E2E tests", Label("build", "
 		components := []*multiComponent{&ChildComponentDef, &ParentComponentDef}
 		var applicationName, testNamespace, mergeResultSha string
 		var prNumber int
-		var mergeResult *github.PullRequestMergeResult
+		var childRepository has.NudgeRepository
+		var parentPaC *has.PaCLifecycle
 		var timeout time.Duration
 		var parentFirstDigest string
 		var parentPostPacMergeDigest string
@@ -1525,6 +1529,9 @@ var _ = framework.BuildSuiteDescribe("Build service E2E tests", Label("build", "
 					Expect(err).ShouldNot(HaveOccurred())
 				}
 			})
+			It(fmt.Sprintf("records that child component %s is nudged by parent component %s", ChildComponentDef.componentName, ParentComponentDef.componentName), func() {
+				Expect(f.AsKubeAdmin.HasController.WaitForComponentNudgedBy(ChildComponentDef.componentName, ParentComponentDef.componentName, testNamespace, time.Minute*2)).To(Succeed())
+			})
 			// Initial pipeline run, we need this so we have an initial image that we can then update
 			It(fmt.Sprintf("triggers a PipelineRun for parent component %s", ParentComponentDef.componentName), func() {
 				timeout = time.Minute * 5
@@ -1589,47 +1596,18 @@ var _ = framework.BuildSuiteDescribe("Build service E2E tests", Label("build", "
 			})
 			// This actually happens immediately, but we only need the PR number now
 			It(fmt.Sprintf("should lead to a PaC PR creation for parent component %s", ParentComponentDef.componentName), func() {
-				timeout = time.Second * 300
-				interval := time.Second * 1
-
-				Eventually(func() bool {
-					prs, err := f.AsKubeAdmin.CommonController.Github.ListPullRequests(ParentComponentDef.repoName)
-					Expect(err).ShouldNot(HaveOccurred())
-
-					for _, pr := range prs {
-						if pr.Head.GetRef() == ParentComponentDef.pacBranchName {
-							prNumber = pr.GetNumber()
-							return true
-						}
-					}
-					return false
-				}, timeout, interval).Should(BeTrue(), fmt.Sprintf("timed out when waiting for PaC PR (branch name '%s') to be created in %s repository", ParentComponentDef.pacBranchName, ParentComponentDef.repoName))
+				parentPaC = f.AsKubeAdmin.HasController.NewPaCLifecycle(ParentComponentDef.component, &has.GitHubPaCProvider{Client: f.AsKubeAdmin.CommonController.Github, Repository: ParentComponentDef.repoName})
+				_, err = parentPaC.WaitForPullRequest(time.Second * 300)
+				Expect(err).ShouldNot(HaveOccurred())
 			})
 			It(fmt.Sprintf("Merging the PaC PR should be successful for parent component %s", ParentComponentDef.componentName), func() {
-				Eventually(func() error {
-					mergeResult, err = f.AsKubeAdmin.CommonController.Github.MergePullRequest(ParentComponentDef.repoName, prNumber)
-					return err
-				}, time.Minute).Should(BeNil(), fmt.Sprintf("error when merging PaC pull request #%d in repo %s", prNumber, ParentComponentDef.repoName))
-
-				mergeResultSha = mergeResult.GetSHA()
-				GinkgoWriter.Printf("merged result sha: %s for PR #%d\n", mergeResultSha, prNumber)
-
+				mergeResultSha, err = parentPaC.Merge(time.Minute)
+				Expect(err).ShouldNot(HaveOccurred())
 			})
 			// Now the PR is merged this will kick off another build. The result of this build is what we want to update in dockerfile we created
 			It(fmt.Sprintf("PR merge triggers PAC PipelineRun for parent component %s", ParentComponentDef.componentName), func() {
-				timeout = time.Minute * 5
-
-				Eventually(func() error {
-					pipelineRun, err := f.AsKubeAdmin.HasController.GetComponentPipelineRun(ParentComponentDef.componentName, applicationName, testNamespace, mergeResultSha)
-					if err != nil {
-						GinkgoWriter.Printf("Push PipelineRun has not been created yet for the component %s/%s\n", testNamespace, ParentComponentDef.componentName)
-						return err
-					}
-					if !pipelineRun.HasStarted() {
-						return fmt.Errorf("push pipelinerun %s/%s hasn't started yet", pipelineRun.GetNamespace(), pipelineRun.GetName())
-					}
-					return nil
-				}, timeout, constants.PipelineRunPollingInterval).Should(Succeed(), fmt.Sprintf("timed out when waiting for the PipelineRun to start for the component %s/%s", testNamespace, ParentComponentDef.componentName))
+				_, err = parentPaC.WaitForPushPipelineRun(time.Minute * 5)
+				Expect(err).ShouldNot(HaveOccurred())
 			})
 			// Wait for this PR to be done and store the digest, we will need it to verify that the nudge was correct
 			It(fmt.Sprintf("PAC PipelineRun for parent component %s is successful", ParentComponentDef.componentName), func() {
@@ -1644,31 +1622,14 @@ var _ = framework.BuildSuiteDescribe("Build service E2E tests", Label("build", "
 				Expect(parentPostPacMergeDigest).ShouldNot(BeEmpty())
 			})
 			It(fmt.Sprintf("should lead to a nudge PR creation for child component %s", ChildComponentDef.componentName), func() {
-				timeout = time.Minute * 20
-				interval := time.Second * 1
-
-				Eventually(func() bool {
-					prs, err := f.AsKubeAdmin.CommonController.Github.ListPullRequests(componentDependenciesChildRepoName)
-					Expect(err).ShouldNot(HaveOccurred())
-
-					for _, pr := range prs {
-						if strings.Contains(pr.Head.GetRef(), ParentComponentDef.componentName) {
-							prNumber = pr.GetNumber()
-							return true
-						}
-					}
-					return false
-				}, timeout, interval).Should(BeTrue(), fmt.Sprintf("timed out when waiting for component nudge PR to be created in %s repository", componentDependenciesChildRepoName))
+				childRepository = &has.GitHubNudgeRepository{Client: f.AsKubeAdmin.CommonController.Github, Repository: componentDependenciesChildRepoName}
+				prNumber, err = f.AsKubeAdmin.HasController.WaitForNudgePullRequest(childRepository, ParentComponentDef.componentName, time.Minute*20)
+				Expect(err).ShouldNot(HaveOccurred())
 			})
 			It(fmt.Sprintf("merging the PR should be successful for child component %s", ChildComponentDef.componentName), func() {
-				Eventually(func() error {
-					mergeResult, err = f.AsKubeAdmin.CommonController.Github.MergePullRequest(componentDependenciesChildRepoName, prNumber)
-					return err
-				}, time.Minute).Should(BeNil(), fmt.Sprintf("error when merging nudge pull request #%d in repo %s", prNumber, componentDependenciesChildRepoName))
-
-				mergeResultSha = mergeResult.GetSHA()
+				mergeResultSha, err = f.AsKubeAdmin.HasController.MergeNudgePullRequest(childRepository, prNumber, time.Minute)
+				Expect(err).ShouldNot(HaveOccurred())
 				GinkgoWriter.Printf("merged result sha: %s for PR #%d\n", mergeResultSha, prNumber)
-
 			})
 			// Now the nudge has been merged we verify the dockerfile is what we expected
 			It("Verify the nudge updated the contents", func() {
@@ -1680,18 +1641,10 @@ var _ = framework.BuildSuiteDescribe("Build service E2E tests", Label("build", "
 				annotations := component.GetAnnotations()
 				imageRepoName, err := build.GetQuayImageName(annotations)
 				Expect(err).ShouldNot(HaveOccurred())
-				contents, err := f.AsKubeAdmin.CommonController.Github.GetFile(ChildComponentDef.repoName, "Dockerfile.tmp", ChildComponentDef.componentBranch)
-				Expect(err).ShouldNot(HaveOccurred())
-				content, err := contents.GetContent()
-				Expect(err).ShouldNot(HaveOccurred())
-				Expect(content).Should(Equal("FROM quay.io/" + quayOrg + "/" + imageRepoName + "@" + parentPostPacMergeDigest + "\nRUN echo hello\n"))
-
-				contents, err = f.AsKubeAdmin.CommonController.Github.GetFile(ChildComponentDef.repoName, "manifest.yaml", ChildComponentDef.componentBranch)
-				Expect(err).ShouldNot(HaveOccurred())
-				content, err = contents.GetContent()
-				Expect(err).ShouldNot(HaveOccurred())
-				Expect(content).Should(Equal("image: " + distributionRepository + "@" + parentPostPacMergeDigest))
-
+				Expect(f.AsKubeAdmin.HasController.VerifyNudgedImageReferences(childRepository, ChildComponentDef.componentBranch, parentPostPacMergeDigest, map[string][]string{
+					"Dockerfile.tmp": {"quay.io/" + quayOrg + "/" + imageRepoName},
+					"manifest.yaml":  {distributionRepository},
+				})).To(Succeed())
 			})
 		})
 
@@ -11,7 +11,6 @@ import (
 	ecp "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
 
 	"github.com/konflux-ci/application-api/api/v1alpha1"
-	appservice "github.com/konflux-ci/application-api/api/v1alpha1"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/has"
 	kubeapi "github.com/konflux-ci/e2e-tests/pkg/clients/kubernetes"
 	"github.com/konflux-ci/e2e-tests/pkg/constants"
@@ -20,6 +19,7 @@ import (
 	"github.com/konflux-ci/e2e-tests/pkg/utils/build"
 	"github.com/konflux-ci/e2e-tests/pkg/utils/contract"
 	"github.com/konflux-ci/e2e-tests/pkg/utils/pipeline"
+	"github.com/konflux-ci/e2e-tests/pkg/utils/sbom"
 	"github.com/konflux-ci/e2e-tests/pkg/utils/tekton"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -45,7 +45,7 @@ const pipelineCompletionRetries = 2
 // CreateComponent creates a component from a test repository URL and returns the component's name
 func CreateComponent(ctrl *has.HasController, gitUrl, revision, applicationName, componentName, namespace string) string {
 	var err error
-	var buildPipelineAnnotation map[string]string
+	var pipelineName, pipelineBundle string
 	contextDir, dockerfilePath, pipelineBundleName, enableHermetic, prefetchInput := GetComponentScenarioDetailsFromGitUrl(gitUrl)
 	Expect(pipelineBundleName).ShouldNot(BeEmpty())
 	if pipelineBundleName == "docker-build" {
@@ -61,41 +61,30 @@ func CreateComponent(ctrl *has.HasController, gitUrl, revision, applicationName,
 		if customDockerBuildBundle == "" {
 			customDockerBuildBundle = "latest"
 		}
-		buildPipelineAnnotation = map[string]string{
-			"build.appstudio.openshift.io/pipeline": fmt.Sprintf(`{"name":"docker-build", "bundle": "%s"}`, customDockerBuildBundle),
-		}
+		pipelineName, pipelineBundle = "docker-build", customDockerBuildBundle
 	} else if pipelineBundleName == "fbc-builder" {
 		customFbcBuilderBundle := os.Getenv(constants.CUSTOM_FBC_BUILDER_PIPELINE_BUNDLE_ENV)
 		if customFbcBuilderBundle == "" {
 			customFbcBuilderBundle = "latest"
 		}
-		buildPipelineAnnotation = map[string]string{
-			"build.appstudio.openshift.io/pipeline": fmt.Sprintf(`{"name":"fbc-builder", "bundle": "%s"}`, customFbcBuilderBundle),
-		}
-	}
-
-	componentObj := appservice.ComponentSpec{
-		ComponentName: componentName,
-		Source: appservice.ComponentSource{
-			ComponentSourceUnion: appservice.ComponentSourceUnion{
-				GitSource: &appservice.GitSource{
-					URL:           gitUrl,
-					Revision:      revision,
-					Context:       contextDir,
-					DockerfileURL: dockerfilePath,
-				},
-			},
-		},
+		pipelineName, pipelineBundle = "fbc-builder", customFbcBuilderBundle
 	}
 
 	if os.Getenv(constants.CUSTOM_SOURCE_BUILD_PIPELINE_BUNDLE_ENV) != "" {
 		customSourceBuildBundle := os.Getenv(constants.CUSTOM_SOURCE_BUILD_PIPELINE_BUNDLE_ENV)
 		Expect(customSourceBuildBundle).ShouldNot(BeEmpty())
-		buildPipelineAnnotation = map[string]string{
-			"build.appstudio.openshift.io/pipeline": fmt.Sprintf(`{"name":"docker-build", "bundle": "%s"}`, customSourceBuildBundle),
-		}
+		pipelineName, pipelineBundle = "docker-build", customSourceBuildBundle
+	}
+	builder := ctrl.NewComponentBuilder(componentName, namespace, applicationName).
+		WithGitSource(gitUrl).
+		WithRevision(revision).
+		WithContext(contextDir).
+		WithDockerfile(dockerfilePath).
+		WithSkipInitialChecks(false)
+	if pipelineName != "" {
+		builder = builder.WithPipelineBundle(pipelineName, pipelineBundle)
 	}
-	c, err := ctrl.CreateComponent(componentObj, namespace, "", "", applicationName, false, buildPipelineAnnotation)
+	c, err := builder.Create()
 	Expect(err).ShouldNot(HaveOccurred())
 	return c.Name
 }
@@ -261,14 +250,12 @@ var _ = framework.BuildSuiteDescribe("Build templates E2E test", Label("build",
 					sbomTaskLog = log
 				}
 
-				sbom := &build.SbomCyclonedx{}
 				GinkgoWriter.Printf("sbom task log: %s\n", sbomTaskLog)
 
-				err = json.Unmarshal([]byte(sbomTaskLog), sbom)
+				parsedSbom, err := sbom.Parse([]byte(sbomTaskLog))
 				Expect(err).NotTo(HaveOccurred(), fmt.Sprintf("failed to parse SBOM from show-sbom task output from %s/%s PipelineRun", pr.GetNamespace(), pr.GetName()))
-				Expect(sbom.BomFormat).ToNot(BeEmpty())
-				Expect(sbom.SpecVersion).ToNot(BeEmpty())
-				Expect(sbom.Components).ToNot(BeEmpty())
+				Expect(parsedSbom.Components).ToNot(BeEmpty())
+				Expect(sbom.VerifyPurls(parsedSbom)).To(Succeed())
 			})
 
 			It(fmt.Sprintf("should ensure show-summary task ran for component with Git source URL %s", gitUrl), Label(buildTemplatesTestLabel), func() {
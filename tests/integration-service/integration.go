@@ -8,6 +8,7 @@ import (
 
 	"github.com/devfile/library/v2/pkg/util"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/has"
+	"github.com/konflux-ci/e2e-tests/pkg/clients/integration"
 	"github.com/konflux-ci/e2e-tests/pkg/constants"
 	"github.com/konflux-ci/e2e-tests/pkg/framework"
 	"github.com/konflux-ci/e2e-tests/pkg/utils"
@@ -15,7 +16,6 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	integrationv1beta1 "github.com/konflux-ci/integration-service/api/v1beta1"
-	intgteststat "github.com/konflux-ci/integration-service/pkg/integrationteststatus"
 
 	appstudioApi "github.com/konflux-ci/application-api/api/v1alpha1"
 
@@ -94,6 +94,10 @@ var _ = framework.IntegrationServiceSuiteDescribe("Integration Service E2E tests
 				Expect(err).ShouldNot(HaveOccurred())
 			})
 
+			It("checks if integration-service selected the scenarios applicable to the Snapshot", func() {
+				Expect(f.AsKubeDeveloper.IntegrationController.VerifyScenarioSelectionForApplication(snapshot, time.Minute*5)).To(Succeed())
+			})
+
 			It("checks if the Build PipelineRun got annotated with Snapshot name", func() {
 				Expect(f.AsKubeDeveloper.IntegrationController.WaitForBuildPipelineRunToGetAnnotated(testNamespace, applicationName, componentName, snapshotAnnotation)).To(Succeed())
 			})
@@ -116,20 +120,13 @@ var _ = framework.IntegrationServiceSuiteDescribe("Integration Service E2E tests
 			})
 
 			It("checks if the passed status of integration test is reported in the Snapshot", func() {
-				timeout = time.Second * 240
-				interval = time.Second * 5
-				Eventually(func() error {
-					snapshot, err = f.AsKubeAdmin.IntegrationController.GetSnapshot(snapshot.Name, "", "", testNamespace)
-					Expect(err).ShouldNot(HaveOccurred())
-
-					statusDetail, err := f.AsKubeDeveloper.IntegrationController.GetIntegrationTestStatusDetailFromSnapshot(snapshot, integrationTestScenario.Name)
-					Expect(err).ToNot(HaveOccurred())
+				snapshot, err = f.AsKubeDeveloper.IntegrationController.WaitForScenarioStatus(snapshot, integrationTestScenario.Name, integration.TestPassed, time.Second*240)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(snapshot).To(integration.AllScenariosFinished())
 
-					if statusDetail.Status != intgteststat.IntegrationTestStatusTestPassed {
-						return fmt.Errorf("test status for scenario: %s, doesn't have expected value %s, within the snapshot: %s", integrationTestScenario.Name, intgteststat.IntegrationTestStatusTestPassed, snapshot.Name)
-					}
-					return nil
-				}, timeout, interval).Should(Succeed())
+				timeline, err := integration.GetIntegrationTestStatusTimeline(snapshot)
+				Expect(err).ShouldNot(HaveOccurred())
+				GinkgoWriter.Printf("integration test status timeline of snapshot %s:\n%s\n", snapshot.GetName(), timeline)
 			})
 
 			It("checks if the finalizer was removed from all of the related Integration pipelineRuns", func() {
@@ -154,19 +151,16 @@ var _ = framework.IntegrationServiceSuiteDescribe("Integration Service E2E tests
 		})
 
 		When("An snapshot of push event is created", func() {
-			It("checks if the global candidate is updated after push event", func() {
-				timeout = time.Second * 600
-				interval = time.Second * 10
-				Eventually(func() error {
-					snapshotPush, err = f.AsKubeAdmin.IntegrationController.GetSnapshot(snapshotPush.Name, "", "", testNamespace)
-					Expect(err).ShouldNot(HaveOccurred())
+			It("checks if integration-service selected the scenario for the push Snapshot", func() {
+				Expect(f.AsKubeAdmin.IntegrationController.VerifyScenarioSelection(snapshotPush, []string{integrationTestScenario.Name}, time.Minute*5)).To(Succeed())
+			})
 
-					component, err := f.AsKubeAdmin.HasController.GetComponentByApplicationName(applicationName, testNamespace)
-					Expect(err).ShouldNot(HaveOccurred())
-					Expect(component.Spec.ContainerImage).ToNot(Equal(originalComponent.Spec.ContainerImage))
-					return nil
+			It("checks if the global candidate is updated after push event", func() {
+				Expect(f.AsKubeAdmin.IntegrationController.WaitForGlobalCandidatesToMatchSnapshot(snapshotPush, time.Second*600)).To(Succeed(), fmt.Sprintf("time out when waiting for updating the global candidate in %s namespace", testNamespace))
 
-				}, timeout, interval).Should(Succeed(), fmt.Sprintf("time out when waiting for updating the global candidate in %s namespace", testNamespace))
+				component, err := f.AsKubeAdmin.HasController.GetComponentByApplicationName(applicationName, testNamespace)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(component.Spec.ContainerImage).ToNot(Equal(originalComponent.Spec.ContainerImage))
 			})
 
 			It("checks if all of the integrationPipelineRuns created by push event passed", Label("slow"), func() {
@@ -231,18 +225,9 @@ var _ = framework.IntegrationServiceSuiteDescribe("Integration Service E2E tests
 		})
 
 		It("checks if the failed status of integration test is reported in the Snapshot", func() {
-			Eventually(func() error {
-				snapshot, err = f.AsKubeAdmin.IntegrationController.GetSnapshot(snapshot.Name, "", "", testNamespace)
-				Expect(err).ShouldNot(HaveOccurred())
-
-				statusDetail, err := f.AsKubeDeveloper.IntegrationController.GetIntegrationTestStatusDetailFromSnapshot(snapshot, integrationTestScenario.Name)
-				Expect(err).ToNot(HaveOccurred())
-
-				if statusDetail.Status != intgteststat.IntegrationTestStatusTestFail {
-					return fmt.Errorf("test status doesn't have expected value %s", intgteststat.IntegrationTestStatusTestFail)
-				}
-				return nil
-			}, timeout, interval).Should(Succeed())
+			Eventually(func() (*appstudioApi.Snapshot, error) {
+				return f.AsKubeAdmin.IntegrationController.GetSnapshot(snapshot.Name, "", "", testNamespace)
+			}, time.Minute*4, time.Second*5).Should(integration.HaveScenarioStatus(integrationTestScenario.Name, integration.TestFail))
 		})
 
 		It("checks if snapshot is marked as failed", FlakeAttempts(3), func() {
@@ -298,15 +283,11 @@ var _ = framework.IntegrationServiceSuiteDescribe("Integration Service E2E tests
 					snapshot, err = f.AsKubeAdmin.IntegrationController.GetSnapshot(snapshot.Name, "", "", testNamespace)
 					g.Expect(err).ShouldNot(HaveOccurred())
 
-					statusDetail, err := f.AsKubeDeveloper.IntegrationController.GetIntegrationTestStatusDetailFromSnapshot(snapshot, newIntegrationTestScenario.Name)
-					g.Expect(err).ToNot(HaveOccurred())
-					g.Expect(statusDetail).NotTo(BeNil())
-
 					integrationPipelineRun, err := f.AsKubeDeveloper.IntegrationController.GetIntegrationPipelineRun(newIntegrationTestScenario.Name, snapshot.Name, testNamespace)
 					g.Expect(err).ToNot(HaveOccurred())
 					g.Expect(integrationPipelineRun).NotTo(BeNil())
 
-					g.Expect(statusDetail.TestPipelineRunName).To(Equal(integrationPipelineRun.Name))
+					g.Expect(snapshot).To(integration.HaveScenarioTestPipelineRun(newIntegrationTestScenario.Name, integrationPipelineRun.Name))
 				}, timeout, interval).Should(Succeed())
 			})
 
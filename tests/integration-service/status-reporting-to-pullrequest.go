@@ -7,6 +7,7 @@ import (
 
 	"github.com/devfile/library/v2/pkg/util"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/has"
+	"github.com/konflux-ci/e2e-tests/pkg/clients/integration"
 	"github.com/konflux-ci/e2e-tests/pkg/constants"
 	"github.com/konflux-ci/e2e-tests/pkg/framework"
 	"github.com/konflux-ci/e2e-tests/pkg/utils"
@@ -194,6 +195,18 @@ var _ = framework.IntegrationServiceSuiteDescribe("Status Reporting of Integrati
 					return err == nil && !f.AsKubeAdmin.CommonController.HaveTestsSucceeded(snapshot)
 				}, time.Minute*3, time.Second*5).Should(BeTrue(), fmt.Sprintf("Timed out waiting for Snapshot to be marked as failed %s/%s", snapshot.GetNamespace(), snapshot.GetName()))
 			})
+			It("should report the status of both integration tests in the Snapshot", func() {
+				snapshot, err = f.AsKubeAdmin.IntegrationController.WaitForSnapshotToMatch(snapshot, integration.AllScenariosFinished(), time.Minute*3)
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(snapshot).To(And(
+					integration.HaveScenarioStatus(integrationTestScenarioPass.Name, integration.TestPassed),
+					integration.HaveScenarioStatus(integrationTestScenarioFail.Name, integration.TestFail),
+				))
+
+				timeline, err := integration.GetIntegrationTestStatusTimeline(snapshot)
+				Expect(err).ShouldNot(HaveOccurred())
+				GinkgoWriter.Printf("integration test status timeline of snapshot %s:\n%s\n", snapshot.GetName(), timeline)
+			})
 			It("eventually leads to the status reported at Checks tab for the successful Integration PipelineRun", func() {
 				Expect(f.AsKubeAdmin.CommonController.Github.GetCheckRunConclusion(integrationTestScenarioPass.Name, componentRepoNameForStatusReporting, prHeadSha, prNumber)).To(Equal(constants.CheckrunConclusionSuccess))
 			})
@@ -22,7 +22,7 @@ const (
 	SecondReleasePlanName          string = "the-second-releaseplan"
 	TargetReleasePlanAdmissionName string = "demo"
 	ReleasePvcName                 string = "release-pvc"
-	ReleaseEnvironment             string = "production"
+	ReleaseEnvironmentName         string = "production"
 
 	ReleaseCreationTimeout              = 5 * time.Minute
 	ReleasePipelineRunCreationTimeout   = 10 * time.Minute
@@ -39,7 +39,8 @@ const (
 	AdditionalGitSourceComponentUrl string = "https://github.com/devfile-samples/devfile-sample-python-basic"
 	ReleasedImagePushRepo           string = "quay.io/redhat-appstudio-qe/dcmetromap"
 	AdditionalReleasedImagePushRepo string = "quay.io/redhat-appstudio-qe/simplepython"
-	PyxisStageImagesApiEndpoint     string = "https://pyxis.preprod.api.redhat.com/v1/images/id/"
+	PyxisStageApiEndpoint           string = "https://pyxis.preprod.api.redhat.com/v1"
+	PyxisStageImagesApiEndpoint     string = PyxisStageApiEndpoint + "/images/id/"
 	GitLabRunFileUpdatesTestRepo    string = "https://gitlab.cee.redhat.com/hacbs-release-tests/app-interface"
 
 	// EC constants
@@ -10,6 +10,7 @@ import (
 	ecp "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
 	appservice "github.com/konflux-ci/application-api/api/v1alpha1"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/github"
+	"github.com/konflux-ci/e2e-tests/pkg/clients/release"
 	"github.com/konflux-ci/e2e-tests/pkg/constants"
 	"github.com/konflux-ci/e2e-tests/pkg/framework"
 	"github.com/konflux-ci/e2e-tests/pkg/utils"
@@ -33,6 +34,7 @@ const (
 	sampRepoOwner          = "redhat-appstudio-qe"
 	sampRepo               = "devfile-sample-go-basic"
 	sampCatalogPathInRepo  = "pipelines/release-to-github/release-to-github.yaml"
+	sampReleaseVersion     = "1.0.0"
 )
 
 var _ = framework.ReleasePipelinesSuiteDescribe("e2e tests for release-to-github pipeline", Label("release-pipelines", "release-to-github"), func() {
@@ -104,7 +106,7 @@ var _ = framework.ReleasePipelinesSuiteDescribe("e2e tests for release-to-github
 			_, err = devFw.AsKubeDeveloper.ReleaseController.CreateReleasePlan(sampReleasePlanName, devNamespace, sampApplicationName, managedNamespace, "true", nil, nil)
 			Expect(err).NotTo(HaveOccurred())
 
-			createGHReleasePlanAdmission(sampReleasePlanAdmissionName, *managedFw, devNamespace, managedNamespace, sampApplicationName, sampEnterpriseContractPolicyName, sampCatalogPathInRepo, "false", "", "", "", "")
+			createGHReleasePlanAdmission(sampReleasePlanAdmissionName, *managedFw, devNamespace, managedNamespace, sampApplicationName, sampEnterpriseContractPolicyName, sampCatalogPathInRepo, "false", "", "", "", sampReleaseVersion)
 
 			component = releasecommon.CreateComponent(*devFw, devNamespace, sampApplicationName, sampComponentName, sampSourceGitURL, "", ".", "Dockerfile", constants.DefaultDockerBuildPipelineBundle)
 
@@ -198,6 +200,33 @@ var _ = framework.ReleasePipelinesSuiteDescribe("e2e tests for release-to-github
 				Expect(gh.CheckIfReleaseExist(sampRepoOwner, sampRepo, releaseURL)).To(BeTrue(), fmt.Sprintf("release %s doesn't exist", releaseURL))
 				sampReleaseURL = releaseURL
 			})
+
+			It("verifies the assets, checksums, signatures and release notes of the Release in github repo", func() {
+				devFw = releasecommon.NewFramework(devWorkspace)
+				managedFw = releasecommon.NewFramework(managedWorkspace)
+				ghRelease, err := gh.GetReleaseWithAssets(sampRepoOwner, sampRepo, github.TagFromReleaseURL(sampReleaseURL))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ghRelease.Assets).NotTo(BeEmpty(), fmt.Sprintf("release %s has no assets", sampReleaseURL))
+
+				releasePlan, err := devFw.AsKubeDeveloper.ReleaseController.GetReleasePlan(sampReleasePlanName, devNamespace)
+				Expect(err).NotTo(HaveOccurred())
+				releasePlanAdmission, err := managedFw.AsKubeAdmin.ReleaseController.GetReleasePlanAdmission(sampReleasePlanAdmissionName, managedNamespace)
+				Expect(err).NotTo(HaveOccurred())
+				// the tag is derived from the release data the pipeline got, not from the URL the release was fetched by
+				expected, err := release.ExpectedGitHubRelease(releasePlan, releasePlanAdmission, releaseCR)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(expected.TagName).NotTo(BeEmpty(), "release data contains no product version")
+				Expect(expected.Notes).NotTo(BeEmpty(), "release data contains no release notes")
+				Expect(github.VerifyRelease(ghRelease, expected)).To(Succeed())
+				Expect(github.VerifyAssetChecksums(ghRelease)).To(Succeed())
+
+				publicKey := utils.GetEnv(constants.RELEASE_GITHUB_SIGNING_PUBLIC_KEY_ENV, "")
+				if publicKey == "" {
+					GinkgoWriter.Printf("%s is not set, skipping the verification of the signatures of release %s\n", constants.RELEASE_GITHUB_SIGNING_PUBLIC_KEY_ENV, sampReleaseURL)
+					return
+				}
+				Expect(github.VerifyAssetSignatures(ghRelease, []byte(publicKey))).To(Succeed())
+			})
 		})
 	})
 })
@@ -232,6 +261,11 @@ func createGHReleasePlanAdmission(sampRPAName string, managedFw framework.Framew
 		"sign": map[string]interface{}{
 			"configMapName": "hacbs-signing-pipeline-config-redhatbeta2",
 		},
+		"releaseNotes": map[string]interface{}{
+			"synopsis":        "Release of the go sample application",
+			"description":     "Binaries of the go sample application released by the release-to-github pipeline",
+			"product_version": productVersion,
+		},
 	})
 	Expect(err).NotTo(HaveOccurred())
 
@@ -2,7 +2,6 @@ package pipelines
 
 import (
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"os"
 	"time"
@@ -17,15 +16,11 @@ import (
 	"github.com/konflux-ci/e2e-tests/pkg/utils/contract"
 	releasecommon "github.com/konflux-ci/e2e-tests/tests/release"
 	releaseApi "github.com/konflux-ci/release-service/api/v1alpha1"
-	tektonutils "github.com/konflux-ci/release-service/tekton/utils"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	pipeline "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 
 	ecp "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
-	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var _ = framework.ReleasePipelinesSuiteDescribe("[HACBS-1571]test-release-e2e-push-image-to-pyxis", Label("release-pipelines", "pushPyxis", "HACBS"), func() {
@@ -39,6 +34,7 @@ var _ = framework.ReleasePipelinesSuiteDescribe("[HACBS-1571]test-release-e2e-pu
 
 	var imageIDs []string
 	var pyxisKeyDecoded, pyxisCertDecoded []byte
+	var managedEnvironment *releasecommon.ProvisionedReleaseEnvironment
 	var releasePR1, releasePR2 *pipeline.PipelineRun
 	scGitRevision := fmt.Sprintf("test-pyxis-%s", util.GenerateRandomString(4))
 
@@ -54,9 +50,6 @@ var _ = framework.ReleasePipelinesSuiteDescribe("[HACBS-1571]test-release-e2e-pu
 		devNamespace = fw.UserNamespace
 		managedNamespace = utils.GetGeneratedNamespace("push-pyxis-managed")
 
-		_, err = fw.AsKubeAdmin.CommonController.CreateTestNamespace(managedNamespace)
-		Expect(err).NotTo(HaveOccurred(), "Error when creating managedNamespace")
-
 		sourceAuthJson := utils.GetEnv("QUAY_TOKEN", "")
 		Expect(sourceAuthJson).ToNot(BeEmpty())
 
@@ -66,58 +59,48 @@ var _ = framework.ReleasePipelinesSuiteDescribe("[HACBS-1571]test-release-e2e-pu
 		certPyxisStage := os.Getenv(constants.PYXIS_STAGE_CERT_ENV)
 		Expect(certPyxisStage).ToNot(BeEmpty())
 
-		// Create secret for the release registry repo "hacbs-release-tests".
-		_, err = fw.AsKubeAdmin.CommonController.CreateRegistryAuthSecret(releasecommon.RedhatAppstudioUserSecret, managedNamespace, sourceAuthJson)
-		Expect(err).ToNot(HaveOccurred())
-
 		// Linking the build secret to the pipeline service account in dev namespace.
 		err = fw.AsKubeAdmin.CommonController.LinkSecretToServiceAccount(devNamespace, releasecommon.HacbsReleaseTestsTokenSecret, constants.DefaultPipelineServiceAccount, true)
 		Expect(err).ToNot(HaveOccurred())
 
-		publicKey, err := fw.AsKubeAdmin.TektonController.GetTektonChainsPublicKey()
-		Expect(err).ToNot(HaveOccurred())
-
-		// Creating k8s secret to access Pyxis stage based on base64 decoded of key and cert
+		// Decoding the key and cert to access Pyxis stage
 		pyxisKeyDecoded, err = base64.StdEncoding.DecodeString(string(keyPyxisStage))
 		Expect(err).ToNot(HaveOccurred())
 
 		pyxisCertDecoded, err = base64.StdEncoding.DecodeString(string(certPyxisStage))
 		Expect(err).ToNot(HaveOccurred())
 
-		secret := &corev1.Secret{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      "pyxis",
-				Namespace: managedNamespace,
-			},
-			Type: corev1.SecretTypeOpaque,
-			Data: map[string][]byte{
-				"cert": pyxisCertDecoded,
-				"key":  pyxisKeyDecoded,
-			},
-		}
-
-		_, err = fw.AsKubeAdmin.CommonController.CreateSecret(managedNamespace, secret)
-		Expect(err).ToNot(HaveOccurred())
-
-		Expect(fw.AsKubeAdmin.TektonController.CreateOrUpdateSigningSecret(
-			publicKey, releasecommon.PublicSecretNameAuth, managedNamespace)).To(Succeed())
-
 		defaultECP, err := fw.AsKubeAdmin.TektonController.GetEnterpriseContractPolicy("default", "enterprise-contract-service")
 		Expect(err).NotTo(HaveOccurred())
 		policy := contract.PolicySpecWithSourceConfig(defaultECP.Spec, ecp.SourceConfig{Include: []string{"@slsa3"}, Exclude: []string{"step_image_registries", "tasks.required_tasks_found:prefetch-dependencies"}})
 
-		managedServiceAccount, err := fw.AsKubeAdmin.CommonController.CreateServiceAccount(releasecommon.ReleasePipelineServiceAccountDefault, managedNamespace, releasecommon.ManagednamespaceSecret, nil)
-		Expect(err).NotTo(HaveOccurred())
-
-		_, err = fw.AsKubeAdmin.ReleaseController.CreateReleasePipelineRoleBindingForServiceAccount(managedNamespace, managedServiceAccount)
-		Expect(err).NotTo(HaveOccurred())
-
-		err = fw.AsKubeAdmin.CommonController.LinkSecretToServiceAccount(managedNamespace, releasecommon.RedhatAppstudioUserSecret, releasecommon.ReleasePipelineServiceAccountDefault, true)
-		Expect(err).ToNot(HaveOccurred())
-
 		compName = releasecommon.ComponentName
 		additionalCompName = releasecommon.AdditionalComponentName
 
+		managedEnvironment, err = releasecommon.ReleaseEnvironment{
+			ManagedNamespace: managedNamespace,
+			CreateNamespace:  true,
+			// Secret for the release registry repo "hacbs-release-tests".
+			RegistrySecrets: []releasecommon.RegistrySecret{{Name: releasecommon.RedhatAppstudioUserSecret, DockerConfigJSON: sourceAuthJson}},
+			Pyxis:           &releasecommon.PyxisSecret{Name: "pyxis", Cert: pyxisCertDecoded, Key: pyxisKeyDecoded},
+			SigningSecret:   releasecommon.PublicSecretNameAuth,
+			Roles: []releasecommon.ReleaseRole{{Name: "role-release-service-account", Rules: map[string][]string{
+				"apiGroupsList": {""},
+				"roleResources": {"secrets"},
+				"roleVerbs":     {"get", "list", "watch"},
+			}}},
+			PolicyName: releasecommon.ReleaseStrategyPolicyDefault,
+			Policy:     &policy,
+			PVC:        releasecommon.ReleasePvcName,
+			ReleasePlanAdmission: fw.AsKubeAdmin.ReleaseController.NewReleasePlanAdmissionBuilder(releasecommon.TargetReleasePlanAdmissionName, managedNamespace, devNamespace, releasecommon.ReleaseStrategyPolicyDefault, releasecommon.ReleasePipelineServiceAccountDefault, releasecommon.ApplicationNameDefault).
+				WithCatalogPipeline(releasecommon.RelSvcCatalogURL, releasecommon.RelSvcCatalogRevision, "pipelines/rh-push-to-external-registry/rh-push-to-external-registry.yaml").
+				WithMappingComponent(compName, "quay.io/"+utils.GetQuayIOOrganization()+"/dcmetromap").
+				WithMappingComponent(additionalCompName, "quay.io/"+utils.GetQuayIOOrganization()+"/simplepython").
+				WithDefaultTags("latest").
+				WithPyxis("stage", "pyxis"),
+		}.Provision(fw.AsKubeAdmin)
+		Expect(err).NotTo(HaveOccurred(), "Error when provisioning the managed release environment")
+
 		componentObj1 = appservice.ComponentSpec{
 			ComponentName: releasecommon.ComponentName,
 			Application:   releasecommon.ApplicationNameDefault,
@@ -144,59 +127,6 @@ var _ = framework.ReleasePipelinesSuiteDescribe("[HACBS-1571]test-release-e2e-pu
 		_, err = fw.AsKubeAdmin.ReleaseController.CreateReleasePlan(releasecommon.SourceReleasePlanName, devNamespace, releasecommon.ApplicationNameDefault, managedNamespace, "true", nil, nil)
 		Expect(err).NotTo(HaveOccurred())
 
-		data, err := json.Marshal(map[string]interface{}{
-			"mapping": map[string]interface{}{
-				"components": []map[string]interface{}{
-					{
-						"name":       compName,
-						"repository": "quay.io/" + utils.GetQuayIOOrganization() + "/dcmetromap",
-					},
-					{
-						"name":       additionalCompName,
-						"repository": "quay.io/" + utils.GetQuayIOOrganization() + "/simplepython",
-					},
-				},
-				"defaults": map[string]interface{}{
-					"tags": []string{
-						"latest",
-					},
-				},
-			},
-			"pyxis": map[string]interface{}{
-				"server": "stage",
-				"secret": "pyxis",
-			},
-		})
-		Expect(err).NotTo(HaveOccurred())
-
-		_, err = fw.AsKubeAdmin.ReleaseController.CreateReleasePlanAdmission(releasecommon.TargetReleasePlanAdmissionName, managedNamespace, "", devNamespace, releasecommon.ReleaseStrategyPolicyDefault, releasecommon.ReleasePipelineServiceAccountDefault, []string{releasecommon.ApplicationNameDefault}, true, &tektonutils.PipelineRef{
-			Resolver: "git",
-			Params: []tektonutils.Param{
-				{Name: "url", Value: releasecommon.RelSvcCatalogURL},
-				{Name: "revision", Value: releasecommon.RelSvcCatalogRevision},
-				{Name: "pathInRepo", Value: "pipelines/rh-push-to-external-registry/rh-push-to-external-registry.yaml"},
-			},
-		}, &runtime.RawExtension{
-			Raw: data,
-		})
-		Expect(err).NotTo(HaveOccurred())
-
-		_, err = fw.AsKubeAdmin.TektonController.CreateEnterpriseContractPolicy(releasecommon.ReleaseStrategyPolicyDefault, managedNamespace, policy)
-		Expect(err).NotTo(HaveOccurred())
-
-		_, err = fw.AsKubeAdmin.TektonController.CreatePVCInAccessMode(releasecommon.ReleasePvcName, managedNamespace, corev1.ReadWriteOnce)
-		Expect(err).NotTo(HaveOccurred())
-
-		_, err = fw.AsKubeAdmin.CommonController.CreateRole("role-release-service-account", managedNamespace, map[string][]string{
-			"apiGroupsList": {""},
-			"roleResources": {"secrets"},
-			"roleVerbs":     {"get", "list", "watch"},
-		})
-		Expect(err).NotTo(HaveOccurred())
-
-		_, err = fw.AsKubeAdmin.CommonController.CreateRoleBinding("role-release-service-account-binding", managedNamespace, "ServiceAccount", releasecommon.ReleasePipelineServiceAccountDefault, managedNamespace, "Role", "role-release-service-account", "rbac.authorization.k8s.io")
-		Expect(err).NotTo(HaveOccurred())
-
 		_, err = fw.AsKubeAdmin.HasController.CreateApplication(releasecommon.ApplicationNameDefault, devNamespace)
 		Expect(err).NotTo(HaveOccurred())
 	})
@@ -207,7 +137,7 @@ var _ = framework.ReleasePipelinesSuiteDescribe("[HACBS-1571]test-release-e2e-pu
 			Expect(err.Error()).To(ContainSubstring("Reference does not exist"))
 		}
 		if !CurrentSpecReport().Failed() {
-			Expect(fw.AsKubeAdmin.CommonController.DeleteNamespace(managedNamespace)).NotTo(HaveOccurred())
+			Expect(managedEnvironment.Teardown()).To(Succeed())
 			Expect(fw.SandboxController.DeleteUserSignup(fw.UserName)).To(BeTrue())
 		}
 	})
@@ -320,14 +250,12 @@ var _ = framework.ReleasePipelinesSuiteDescribe("[HACBS-1571]test-release-e2e-pu
 		It("validates that imageIds from task create-pyxis-image exist in Pyxis.", func() {
 			for _, imageID := range imageIDs {
 				Eventually(func() error {
-					body, err := fw.AsKubeAdmin.ReleaseController.GetPyxisImageByImageID(releasecommon.PyxisStageImagesApiEndpoint, imageID,
-						[]byte(pyxisCertDecoded), []byte(pyxisKeyDecoded))
-					Expect(err).NotTo(HaveOccurred(), "failed to get response body")
-
-					sbomImage := release.Image{}
-					Expect(json.Unmarshal(body, &sbomImage)).To(Succeed(), "failed to unmarshal body content: %s", string(body))
-
-					return nil
+					_, err := fw.AsKubeAdmin.ReleaseController.GetPyxisImage(release.PyxisEndpoint{
+						URL:  releasecommon.PyxisStageApiEndpoint,
+						Cert: pyxisCertDecoded,
+						Key:  pyxisKeyDecoded,
+					}, imageID)
+					return err
 				}, releasecommon.ReleaseCreationTimeout, releasecommon.DefaultInterval).Should(Succeed())
 			}
 		})
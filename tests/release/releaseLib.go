@@ -27,21 +27,14 @@ func NewFramework(workspace string) *framework.Framework {
 }
 
 func CreateComponent(devFw framework.Framework, devNamespace, appName, compName, gitURL, gitRevision, contextDir, dockerFilePath string, buildPipelineBundle map[string]string) *appservice.Component {
-	componentObj := appservice.ComponentSpec{
-		ComponentName: compName,
-		Application:   appName,
-		Source: appservice.ComponentSource{
-			ComponentSourceUnion: appservice.ComponentSourceUnion{
-				GitSource: &appservice.GitSource{
-					URL:           gitURL,
-					Revision:      gitRevision,
-					Context:       contextDir,
-					DockerfileURL: dockerFilePath,
-				},
-			},
-		},
-	}
-	component, err := devFw.AsKubeAdmin.HasController.CreateComponent(componentObj, devNamespace, "", "", appName, false, buildPipelineBundle)
+	component, err := devFw.AsKubeAdmin.HasController.NewComponentBuilder(compName, devNamespace, appName).
+		WithGitSource(gitURL).
+		WithRevision(gitRevision).
+		WithContext(contextDir).
+		WithDockerfile(dockerFilePath).
+		WithSkipInitialChecks(false).
+		WithAnnotations(buildPipelineBundle).
+		Create()
 	Expect(err).NotTo(HaveOccurred())
 	return component
 }
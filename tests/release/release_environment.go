@@ -0,0 +1,278 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	ecp "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
+	"github.com/konflux-ci/e2e-tests/pkg/clients/release"
+	"github.com/konflux-ci/e2e-tests/pkg/framework"
+	releaseApi "github.com/konflux-ci/release-service/api/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RegistrySecret is a docker config secret for pushing the released images, linked to the release service account
+type RegistrySecret struct {
+	Name string
+	// DockerConfigJSON is the content of the .dockerconfigjson, e.g. the QUAY_TOKEN env var
+	DockerConfigJSON string
+}
+
+// PyxisSecret is the secret with the client certificate and key (already base64 decoded) for Pyxis
+type PyxisSecret struct {
+	Name string
+	Cert []byte
+	Key  []byte
+}
+
+// ReleaseRole is a role in the managed namespace bound to the release service account,
+// the rules are in the format expected by CommonController.CreateRole
+type ReleaseRole struct {
+	Name  string
+	Rules map[string][]string
+}
+
+// ReleaseEnvironment declares the objects a release pipeline test needs in the managed namespace, e.g.
+//
+//	env, err := releasecommon.ReleaseEnvironment{
+//		ManagedNamespace: managedNamespace,
+//		CreateNamespace:  true,
+//		RegistrySecrets:  []releasecommon.RegistrySecret{{Name: releasecommon.RedhatAppstudioUserSecret, DockerConfigJSON: sourceAuthJson}},
+//		SigningSecret:    releasecommon.PublicSecretNameAuth,
+//		PolicyName:       releasecommon.ReleaseStrategyPolicyDefault,
+//		Policy:           &policy,
+//		ReleasePlanAdmission: fw.AsKubeAdmin.ReleaseController.NewReleasePlanAdmissionBuilder(...),
+//	}.Provision(fw.AsKubeAdmin)
+//	DeferCleanup(env.Teardown)
+//
+// Only the objects which are declared are created. So far only the rh-push-to-external-registry suite provisions
+// its managed namespace this way, the other release suites still create the objects themselves.
+type ReleaseEnvironment struct {
+	ManagedNamespace string
+	// CreateNamespace creates the managed namespace, otherwise it has to exist already (e.g. a workspace of a stage user)
+	CreateNamespace bool
+	// ServiceAccount runs the release pipeline, it is bound to the release pipeline cluster role.
+	// ReleasePipelineServiceAccountDefault is used when empty.
+	ServiceAccount string
+	// RegistrySecrets are created and linked to the service account
+	RegistrySecrets []RegistrySecret
+	// LinkedSecrets are secrets which already exist in the managed namespace and are linked to the service account
+	LinkedSecrets []string
+	// Secrets are any other secrets the release pipeline reads, an existing secret is replaced and kept by Teardown
+	Secrets []*corev1.Secret
+	Pyxis   *PyxisSecret
+	// SigningSecret is the name of the secret with the public key of Tekton Chains to verify the signed images,
+	// an existing secret is updated and kept by Teardown
+	SigningSecret string
+	// Roles are created and bound to the service account
+	Roles []ReleaseRole
+	// PolicyName is the name of the EnterpriseContractPolicy created with the Policy spec
+	PolicyName string
+	Policy     *ecp.EnterpriseContractPolicySpec
+	// PVC is the name of a ReadWriteOnce PersistentVolumeClaim used as the release pipeline workspace
+	PVC                  string
+	ReleasePlanAdmission *release.ReleasePlanAdmissionBuilder
+}
+
+// ProvisionedReleaseEnvironment holds the objects created for the ReleaseEnvironment
+type ProvisionedReleaseEnvironment struct {
+	Namespace            *corev1.Namespace
+	ServiceAccount       *corev1.ServiceAccount
+	RoleBinding          *rbacv1.RoleBinding
+	Secrets              map[string]*corev1.Secret
+	Roles                []*rbacv1.Role
+	RoleBindings         []*rbacv1.RoleBinding
+	Policy               *ecp.EnterpriseContractPolicy
+	PVC                  *corev1.PersistentVolumeClaim
+	ReleasePlanAdmission *releaseApi.ReleasePlanAdmission
+
+	controllers *framework.ControllerHub
+	// created are the objects to delete by Teardown, in the order they were created
+	created []client.Object
+}
+
+func (e ReleaseEnvironment) validate() error {
+	var errs []error
+	if e.ManagedNamespace == "" {
+		errs = append(errs, fmt.Errorf("managed namespace is not set"))
+	}
+	for _, s := range e.RegistrySecrets {
+		if s.Name == "" || s.DockerConfigJSON == "" {
+			errs = append(errs, fmt.Errorf("registry secret %q has no name or docker config", s.Name))
+		}
+	}
+	if e.Pyxis != nil && (e.Pyxis.Name == "" || len(e.Pyxis.Cert) == 0 || len(e.Pyxis.Key) == 0) {
+		errs = append(errs, fmt.Errorf("pyxis secret %q has no name, cert or key", e.Pyxis.Name))
+	}
+	if (e.PolicyName == "") != (e.Policy == nil) {
+		errs = append(errs, fmt.Errorf("both the policy name and the policy spec have to be set"))
+	}
+	return errors.Join(errs...)
+}
+
+// Provision creates the declared objects in the managed namespace. When it fails, the returned environment
+// holds the objects created so far, so Teardown should be called in any case.
+func (e ReleaseEnvironment) Provision(controllers *framework.ControllerHub) (*ProvisionedReleaseEnvironment, error) {
+	env := &ProvisionedReleaseEnvironment{controllers: controllers, Secrets: map[string]*corev1.Secret{}}
+	if err := e.validate(); err != nil {
+		return env, fmt.Errorf("invalid release environment: %+v", err)
+	}
+	namespace := e.ManagedNamespace
+	serviceAccountName := e.ServiceAccount
+	if serviceAccountName == "" {
+		serviceAccountName = ReleasePipelineServiceAccountDefault
+	}
+
+	if e.CreateNamespace {
+		ns, err := controllers.CommonController.CreateTestNamespace(namespace)
+		if err != nil {
+			return env, fmt.Errorf("failed to create managed namespace %s: %+v", namespace, err)
+		}
+		env.Namespace = ns
+	}
+
+	for _, s := range e.RegistrySecrets {
+		secret, err := controllers.CommonController.CreateRegistryAuthSecret(s.Name, namespace, s.DockerConfigJSON)
+		if err != nil {
+			return env, fmt.Errorf("failed to create registry secret %s: %+v", s.Name, err)
+		}
+		env.addSecret(secret, true)
+	}
+
+	secrets := append([]*corev1.Secret{}, e.Secrets...)
+	if e.Pyxis != nil {
+		secrets = append(secrets, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: e.Pyxis.Name, Namespace: namespace},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{"cert": e.Pyxis.Cert, "key": e.Pyxis.Key},
+		})
+	}
+	for _, s := range secrets {
+		_, err := controllers.CommonController.GetSecret(namespace, s.Name)
+		existed := err == nil
+		// Delete the secret if it exists in case it is not correct
+		_ = controllers.CommonController.DeleteSecret(namespace, s.Name)
+		secret, err := controllers.CommonController.CreateSecret(namespace, s)
+		if err != nil {
+			return env, fmt.Errorf("failed to create secret %s: %+v", s.Name, err)
+		}
+		env.addSecret(secret, !existed)
+	}
+
+	if e.SigningSecret != "" {
+		publicKey, err := controllers.TektonController.GetTektonChainsPublicKey()
+		if err != nil {
+			return env, fmt.Errorf("failed to get public key of Tekton Chains: %+v", err)
+		}
+		_, err = controllers.CommonController.GetSecret(namespace, e.SigningSecret)
+		existed := err == nil
+		if err := controllers.TektonController.CreateOrUpdateSigningSecret(publicKey, e.SigningSecret, namespace); err != nil {
+			return env, fmt.Errorf("failed to create signing secret %s: %+v", e.SigningSecret, err)
+		}
+		env.addSecret(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: e.SigningSecret, Namespace: namespace}}, !existed)
+	}
+
+	serviceAccount, err := controllers.CommonController.CreateServiceAccount(serviceAccountName, namespace, ManagednamespaceSecret, nil)
+	if err != nil {
+		return env, fmt.Errorf("failed to create service account %s: %+v", serviceAccountName, err)
+	}
+	env.ServiceAccount = serviceAccount
+	env.created = append(env.created, serviceAccount)
+
+	roleBinding, err := controllers.ReleaseController.CreateReleasePipelineRoleBindingForServiceAccount(namespace, serviceAccount)
+	if err != nil {
+		return env, fmt.Errorf("failed to bind release pipeline role to service account %s: %+v", serviceAccountName, err)
+	}
+	env.RoleBinding = roleBinding
+	env.created = append(env.created, roleBinding)
+
+	linkedSecrets := append([]string{}, e.LinkedSecrets...)
+	for _, s := range e.RegistrySecrets {
+		linkedSecrets = append(linkedSecrets, s.Name)
+	}
+	for _, secret := range linkedSecrets {
+		if err := controllers.CommonController.LinkSecretToServiceAccount(namespace, secret, serviceAccountName, true); err != nil {
+			return env, fmt.Errorf("failed to link secret %s to service account %s: %+v", secret, serviceAccountName, err)
+		}
+	}
+
+	for _, r := range e.Roles {
+		role, err := controllers.CommonController.CreateRole(r.Name, namespace, r.Rules)
+		if err != nil {
+			return env, fmt.Errorf("failed to create role %s: %+v", r.Name, err)
+		}
+		env.Roles = append(env.Roles, role)
+		env.created = append(env.created, role)
+
+		binding, err := controllers.CommonController.CreateRoleBinding(r.Name+"-binding", namespace, "ServiceAccount", serviceAccountName, namespace, "Role", r.Name, "rbac.authorization.k8s.io")
+		if err != nil {
+			return env, fmt.Errorf("failed to bind role %s to service account %s: %+v", r.Name, serviceAccountName, err)
+		}
+		env.RoleBindings = append(env.RoleBindings, binding)
+		env.created = append(env.created, binding)
+	}
+
+	if e.Policy != nil {
+		policy, err := controllers.TektonController.CreateEnterpriseContractPolicy(e.PolicyName, namespace, *e.Policy)
+		if err != nil {
+			return env, fmt.Errorf("failed to create enterprise contract policy %s: %+v", e.PolicyName, err)
+		}
+		env.Policy = policy
+		env.created = append(env.created, policy)
+	}
+
+	if e.PVC != "" {
+		pvc, err := controllers.TektonController.CreatePVCInAccessMode(e.PVC, namespace, corev1.ReadWriteOnce)
+		if err != nil {
+			return env, fmt.Errorf("failed to create persistent volume claim %s: %+v", e.PVC, err)
+		}
+		env.PVC = pvc
+		env.created = append(env.created, pvc)
+	}
+
+	if e.ReleasePlanAdmission != nil {
+		rpa, err := e.ReleasePlanAdmission.Create()
+		if err != nil {
+			return env, err
+		}
+		env.ReleasePlanAdmission = rpa
+		env.created = append(env.created, rpa)
+	}
+	return env, nil
+}
+
+// addSecret records the secret, it is deleted by Teardown only when it was created by Provision
+func (env *ProvisionedReleaseEnvironment) addSecret(secret *corev1.Secret, created bool) {
+	env.Secrets[secret.Name] = secret
+	if created {
+		env.created = append(env.created, secret)
+	}
+}
+
+// Teardown deletes the created managed namespace, or the created objects (in the reverse order) when the namespace already existed
+func (env *ProvisionedReleaseEnvironment) Teardown() error {
+	if env.Namespace != nil {
+		if err := env.controllers.CommonController.DeleteNamespace(env.Namespace.Name); err != nil {
+			return fmt.Errorf("failed to delete managed namespace %s: %+v", env.Namespace.Name, err)
+		}
+		return nil
+	}
+
+	var errs []error
+	for i := len(env.created) - 1; i >= 0; i-- {
+		object := env.created[i]
+		if err := env.controllers.CommonController.KubeRest().Delete(context.Background(), object); err != nil && !k8sErrors.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("failed to delete %T %s/%s: %+v", object, object.GetNamespace(), object.GetName(), err))
+		}
+	}
+	if len(errs) > 0 {
+		GinkgoWriter.Printf("failed to tear down release environment: %+v\n", errors.Join(errs...))
+	}
+	return errors.Join(errs...)
+}
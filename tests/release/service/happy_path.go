@@ -10,6 +10,7 @@ import (
 	ecp "github.com/enterprise-contract/enterprise-contract-controller/api/v1alpha1"
 	appservice "github.com/konflux-ci/application-api/api/v1alpha1"
 	"github.com/konflux-ci/e2e-tests/pkg/clients/has"
+	"github.com/konflux-ci/e2e-tests/pkg/clients/release"
 	"github.com/konflux-ci/e2e-tests/pkg/constants"
 	"github.com/konflux-ci/e2e-tests/pkg/framework"
 	"github.com/konflux-ci/e2e-tests/pkg/utils"
@@ -35,6 +36,7 @@ var _ = framework.ReleaseServiceSuiteDescribe("Release service happy path", Labe
 
 	var component *appservice.Component
 	var releaseCR *releaseApi.Release
+	var releaseTracker *release.ReleaseTracker
 
 	BeforeAll(func() {
 		// Initialize the tests controllers
@@ -139,14 +141,18 @@ var _ = framework.ReleaseServiceSuiteDescribe("Release service happy path", Labe
 				releaseCR, err = fw.AsKubeAdmin.ReleaseController.GetFirstReleaseInNamespace(devNamespace)
 				return err
 			}, releasecommon.ReleaseCreationTimeout, releasecommon.DefaultInterval).Should(Succeed())
+			// follow the Release from its creation, so the timing of all its phases is recorded
+			releaseTracker = fw.AsKubeAdmin.ReleaseController.NewReleaseTracker(releaseCR, managedNamespace)
 		})
 
 		It("verifies that Release PipelineRun is triggered", func() {
 			Expect(fw.AsKubeAdmin.ReleaseController.WaitForReleasePipelineToBeFinished(releaseCR, managedNamespace)).To(Succeed(), fmt.Sprintf("Error when waiting for a release pipelinerun for release %s/%s to finish", releaseCR.GetNamespace(), releaseCR.GetName()))
+			Expect(releaseTracker.Refresh()).To(Succeed())
 		})
 
 		It("verifies that Enterprise Contract Task has succeeded in the Release PipelineRun", func() {
 			Eventually(func() error {
+				Expect(releaseTracker.Refresh()).To(Succeed())
 				pr, err := fw.AsKubeAdmin.ReleaseController.GetPipelineRunInNamespace(managedNamespace, releaseCR.GetName(), releaseCR.GetNamespace())
 				Expect(err).ShouldNot(HaveOccurred())
 				ecTaskRunStatus, err := fw.AsKubeAdmin.TektonController.GetTaskRunStatus(fw.AsKubeAdmin.CommonController.KubeRest(), pr, verifyEnterpriseContractTaskName)
@@ -158,16 +164,10 @@ var _ = framework.ReleaseServiceSuiteDescribe("Release service happy path", Labe
 		})
 
 		It("verifies that a Release is marked as succeeded.", func() {
-			Eventually(func() error {
-				releaseCR, err = fw.AsKubeAdmin.ReleaseController.GetFirstReleaseInNamespace(devNamespace)
-				if err != nil {
-					return err
-				}
-				if !releaseCR.IsReleased() {
-					return fmt.Errorf("release %s/%s is not marked as finished yet", releaseCR.GetNamespace(), releaseCR.GetName())
-				}
-				return nil
-			}, releasecommon.ReleaseCreationTimeout, releasecommon.DefaultInterval).Should(Succeed())
+			Expect(releaseTracker.WaitForCompletion(releasecommon.ReleaseCreationTimeout)).To(Succeed())
+			for _, phase := range releaseTracker.Phases() {
+				GinkgoWriter.Printf("release phase %s took %s\n", phase.Condition, phase.Duration())
+			}
 		})
 	})
 })
@@ -32,3 +32,32 @@ var _ = framework.UpgradeSuiteDescribe("Create users and check their state", Lab
 	})
 
 })
+
+var _ = framework.UpgradeSuiteDescribe("Delete Konflux workload", Label("upgrade-matrix-cleanup"), func() {
+	defer GinkgoRecover()
+
+	var fw *framework.Framework
+	var namespace string
+
+	BeforeAll(func() {
+		fw, namespace = utils.PrepareForUpgradeTests()
+	})
+
+	It("deletes ReleasePlan", func() {
+		Expect(fw.AsKubeAdmin.ReleaseController.DeleteReleasePlan(utils.WorkloadReleasePlan, namespace, false)).To(Succeed())
+	})
+
+	It("deletes IntegrationTestScenarios", func() {
+		scenarios, err := fw.AsKubeAdmin.IntegrationController.GetIntegrationTestScenarios(utils.WorkloadApplication, namespace)
+		Expect(err).NotTo(HaveOccurred())
+		for _, s := range *scenarios {
+			Expect(fw.AsKubeAdmin.IntegrationController.DeleteIntegrationTestScenario(&s, namespace)).To(Succeed())
+		}
+	})
+
+	It("deletes Component and Application", func() {
+		Expect(fw.AsKubeAdmin.HasController.DeleteComponent(utils.WorkloadComponent, namespace, false)).To(Succeed())
+		Expect(fw.AsKubeAdmin.HasController.DeleteApplication(utils.WorkloadApplication, namespace, false)).To(Succeed())
+	})
+
+})
@@ -0,0 +1,45 @@
+package create
+
+import (
+	appstudioApi "github.com/konflux-ci/application-api/api/v1alpha1"
+	"github.com/konflux-ci/e2e-tests/pkg/clients/has"
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/framework"
+	utils "github.com/konflux-ci/e2e-tests/tests/upgrade/utils"
+	. "github.com/onsi/gomega"
+)
+
+func CreateApplication(fw *framework.Framework, namespace string) {
+	_, err := fw.AsKubeAdmin.HasController.CreateApplication(utils.WorkloadApplication, namespace)
+	Expect(err).NotTo(HaveOccurred())
+}
+
+// CreateComponentWithCompletedBuild creates the workload component and waits for its build to succeed
+func CreateComponentWithCompletedBuild(fw *framework.Framework, namespace string) {
+	componentSpec := appstudioApi.ComponentSpec{
+		ComponentName: utils.WorkloadComponent,
+		Application:   utils.WorkloadApplication,
+		Source: appstudioApi.ComponentSource{
+			ComponentSourceUnion: appstudioApi.ComponentSourceUnion{
+				GitSource: &appstudioApi.GitSource{
+					URL: utils.WorkloadComponentRepoURL,
+				},
+			},
+		},
+	}
+	component, err := fw.AsKubeAdmin.HasController.CreateComponent(componentSpec, namespace, "", "", utils.WorkloadApplication, true, constants.DefaultDockerBuildPipelineBundle)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(fw.AsKubeAdmin.HasController.WaitForComponentPipelineToBeFinished(component, "", fw.AsKubeAdmin.TektonController,
+		&has.RetryOptions{Retries: 2, Always: true}, nil)).To(Succeed())
+}
+
+func CreateIntegrationTestScenario(fw *framework.Framework, namespace string) {
+	_, err := fw.AsKubeAdmin.IntegrationController.CreateIntegrationTestScenario(utils.WorkloadIntegrationTestScenario, utils.WorkloadApplication,
+		namespace, utils.WorkloadITSGitURL, utils.WorkloadITSRevision, utils.WorkloadITSPathInRepo)
+	Expect(err).NotTo(HaveOccurred())
+}
+
+func CreateReleasePlan(fw *framework.Framework, namespace string) {
+	_, err := fw.AsKubeAdmin.ReleaseController.CreateReleasePlan(utils.WorkloadReleasePlan, namespace, utils.WorkloadApplication, utils.WorkloadReleaseTarget, "false", nil, nil)
+	Expect(err).NotTo(HaveOccurred())
+}
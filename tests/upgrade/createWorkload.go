@@ -30,3 +30,31 @@ var _ = framework.UpgradeSuiteDescribe("Create users and check their state", Lab
 	})
 
 })
+
+var _ = framework.UpgradeSuiteDescribe("Create Konflux workload", Label("upgrade-matrix-create"), func() {
+	defer GinkgoRecover()
+
+	var fw *framework.Framework
+	var namespace string
+
+	BeforeAll(func() {
+		fw, namespace = utils.PrepareForUpgradeTests()
+	})
+
+	It("creates Application", func() {
+		create.CreateApplication(fw, namespace)
+	})
+
+	It("creates IntegrationTestScenario", func() {
+		create.CreateIntegrationTestScenario(fw, namespace)
+	})
+
+	It("creates ReleasePlan", func() {
+		create.CreateReleasePlan(fw, namespace)
+	})
+
+	It("creates Component and waits for its build to finish", func() {
+		create.CreateComponentWithCompletedBuild(fw, namespace)
+	})
+
+})
@@ -0,0 +1,45 @@
+package upgrade
+
+import (
+	"github.com/konflux-ci/e2e-tests/pkg/framework"
+	utilsFramework "github.com/konflux-ci/e2e-tests/pkg/utils"
+	"github.com/konflux-ci/e2e-tests/pkg/utils/fingerprint"
+	"github.com/konflux-ci/e2e-tests/tests/upgrade/utils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = framework.UpgradeSuiteDescribe("Capture Konflux workload fingerprint", Label("upgrade-fingerprint"), func() {
+	defer GinkgoRecover()
+
+	var fw *framework.Framework
+
+	BeforeAll(func() {
+		fw, _ = utils.PrepareForUpgradeTests()
+	})
+
+	It("captures fingerprint of the workload before upgrade", func() {
+		Expect(utils.CaptureWorkloadFingerprint(fw).Save(utils.FingerprintFile())).To(Succeed())
+	})
+
+})
+
+var _ = framework.UpgradeSuiteDescribe("Compare Konflux workload fingerprint", Label("upgrade-fingerprint-diff"), func() {
+	defer GinkgoRecover()
+
+	var fw *framework.Framework
+
+	BeforeAll(func() {
+		fw, _ = utils.PrepareForUpgradeTests()
+	})
+
+	It("has no unexpected changes after upgrade", func() {
+		before, err := fingerprint.Load(utils.FingerprintFile())
+		Expect(err).NotTo(HaveOccurred())
+
+		diff := fingerprint.Compare(before, utils.CaptureWorkloadFingerprint(fw), fingerprint.DiffOptions{})
+		Expect(diff.Save(utilsFramework.GetEnv("ARTIFACT_DIR", GinkgoT().TempDir()), "upgrade-workload-diff")).To(Succeed())
+		Expect(diff.Regressions()).To(BeEmpty(), "workload changed after upgrade, see upgrade-workload-diff.txt in artifacts")
+	})
+
+})
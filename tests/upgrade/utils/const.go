@@ -10,3 +10,17 @@ const (
 
 	UpgradeNamespace = "upgrade-namespace"
 )
+
+// Konflux workload created before and verified after the upgrade
+const (
+	WorkloadApplication             = "mig-app"
+	WorkloadComponent               = "mig-component"
+	WorkloadIntegrationTestScenario = "mig-its"
+	WorkloadReleasePlan             = "mig-releaseplan"
+
+	WorkloadComponentRepoURL = "https://github.com/redhat-appstudio-qe/hacbs-test-project"
+	WorkloadITSGitURL        = "https://github.com/konflux-ci/integration-examples.git"
+	WorkloadITSRevision      = "843f455fe87a6d7f68c238f95a8f3eb304e65ac5"
+	WorkloadITSPathInRepo    = "pipelines/integration_resolver_pipeline_pass.yaml"
+	WorkloadReleaseTarget    = "default"
+)
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/konflux-ci/e2e-tests/pkg/constants"
+	"github.com/konflux-ci/e2e-tests/pkg/framework"
+	utilsFramework "github.com/konflux-ci/e2e-tests/pkg/utils"
+	"github.com/konflux-ci/e2e-tests/pkg/utils/fingerprint"
+	. "github.com/onsi/gomega"
+)
+
+// WorkloadSpaces are the Spaces of the users created by the upgrade tests
+var WorkloadSpaces = []string{UpgradeNamespace, AppStudioProvisionedUser, DeactivatedUser, BannedUser}
+
+// FingerprintFile is where the fingerprint of the workload captured before the upgrade is stored
+func FingerprintFile() string {
+	return filepath.Join(utilsFramework.GetEnv("ARTIFACT_DIR", os.TempDir()), "upgrade-workload-fingerprint.json")
+}
+
+// CaptureWorkloadFingerprint captures the Spaces of the upgrade test users and Konflux resources in their namespaces
+func CaptureWorkloadFingerprint(fw *framework.Framework) *fingerprint.Fingerprint {
+	f, err := fingerprint.CaptureSpaces(context.Background(), fw.AsKubeAdmin.CommonController.KubeRest(), constants.HostOperatorNamespace,
+		WorkloadSpaces, fingerprint.WorkloadKinds)
+	Expect(err).NotTo(HaveOccurred())
+	return f
+}
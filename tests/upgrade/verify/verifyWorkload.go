@@ -0,0 +1,50 @@
+package verify
+
+import (
+	"github.com/konflux-ci/e2e-tests/pkg/framework"
+	utils "github.com/konflux-ci/e2e-tests/tests/upgrade/utils"
+	releaseApi "github.com/konflux-ci/release-service/api/v1alpha1"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+func VerifyApplication(fw *framework.Framework, namespace string) {
+	_, err := fw.AsKubeAdmin.HasController.GetApplication(utils.WorkloadApplication, namespace)
+	Expect(err).NotTo(HaveOccurred())
+}
+
+// VerifyComponent checks that the component is not in an error state and its build PipelineRun and Snapshot are still present
+func VerifyComponent(fw *framework.Framework, namespace string) {
+	component, err := fw.AsKubeAdmin.HasController.GetComponent(utils.WorkloadComponent, namespace)
+	Expect(err).NotTo(HaveOccurred())
+	for _, c := range component.Status.Conditions {
+		Expect(c.Status).NotTo(Equal(metav1.ConditionFalse), "component condition %s: %s", c.Type, c.Message)
+	}
+
+	pr, err := fw.AsKubeAdmin.HasController.GetComponentPipelineRun(utils.WorkloadComponent, utils.WorkloadApplication, namespace, "")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(pr.GetStatusCondition().GetCondition(apis.ConditionSucceeded).IsTrue()).To(BeTrue(), "build PipelineRun %s did not succeed", pr.GetName())
+
+	_, err = fw.AsKubeAdmin.IntegrationController.GetSnapshot("", "", utils.WorkloadComponent, namespace)
+	Expect(err).NotTo(HaveOccurred())
+}
+
+func VerifyIntegrationTestScenario(fw *framework.Framework, namespace string) {
+	scenarios, err := fw.AsKubeAdmin.IntegrationController.GetIntegrationTestScenarios(utils.WorkloadApplication, namespace)
+	Expect(err).NotTo(HaveOccurred())
+	names := []string{}
+	for _, s := range *scenarios {
+		names = append(names, s.GetName())
+	}
+	Expect(names).To(ContainElement(utils.WorkloadIntegrationTestScenario))
+}
+
+// VerifyReleasePlan checks that the release plan was reconciled by the release service
+func VerifyReleasePlan(fw *framework.Framework, namespace string) {
+	releasePlan, err := fw.AsKubeAdmin.ReleaseController.GetReleasePlan(utils.WorkloadReleasePlan, namespace)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(meta.FindStatusCondition(releasePlan.Status.Conditions, releaseApi.MatchedConditionType.String())).NotTo(BeNil(),
+		"release plan %s was not reconciled", releasePlan.GetName())
+}
@@ -30,3 +30,31 @@ var _ = framework.UpgradeSuiteDescribe("Create users and check their state", Lab
 	})
 
 })
+
+var _ = framework.UpgradeSuiteDescribe("Verify Konflux workload", Label("upgrade-matrix-verify"), func() {
+	defer GinkgoRecover()
+
+	var fw *framework.Framework
+	var namespace string
+
+	BeforeAll(func() {
+		fw, namespace = utils.PrepareForUpgradeTests()
+	})
+
+	It("verifies Application", func() {
+		verify.VerifyApplication(fw, namespace)
+	})
+
+	It("verifies Component", func() {
+		verify.VerifyComponent(fw, namespace)
+	})
+
+	It("verifies IntegrationTestScenario", func() {
+		verify.VerifyIntegrationTestScenario(fw, namespace)
+	})
+
+	It("verifies ReleasePlan", func() {
+		verify.VerifyReleasePlan(fw, namespace)
+	})
+
+})